@@ -0,0 +1,107 @@
+package bloomfilter
+
+import (
+	"math"
+	"sync"
+)
+
+// AgePartitionedFilter is the age-partitioned Bloom filter of Shtul, Baquero
+// and Almeida: a sliding window over the most recent adds. Its bits form a
+// ring of k+l slices. Add sets one bit in each of the k newest slices, and
+// every generation of adds a new empty slice takes the place of the oldest.
+// An item matches if some k consecutive slices all hold it, so every item
+// ages out smoothly, one slice at a time, rather than with all its
+// generation at once.
+type AgePartitionedFilter struct {
+	mu         sync.Mutex
+	bitset     []uint64
+	k, l       int
+	sliceBits  uint64
+	generation uint
+	inserted   uint
+	newest     int
+}
+
+// NewAgePartitioned returns a filter that remembers at least the last window
+// items, and at most window*(l+1)/l. Its k and l set the false-positive
+// rate: k=10, l=7 gives about 0.15%, and k=14, l=11 about 0.012%. Each slice
+// holds k generations' worth of bits at half fill, about 1.44*k bits per
+// item in a generation.
+func NewAgePartitioned(k, l int, window uint) *AgePartitionedFilter {
+	if k < 1 || l < 1 {
+		panic("bloomfilter: age-partitioned filter needs k and l of at least 1")
+	}
+	generation := max((window+uint(l)-1)/uint(l), 1)
+	sliceBits := uint64(math.Ceil(float64(k) * float64(generation) / math.Ln2))
+	return &AgePartitionedFilter{
+		bitset:     make([]uint64, wordsFor(sliceBits*uint64(k+l))),
+		k:          k,
+		l:          l,
+		sliceBits:  sliceBits,
+		generation: generation,
+	}
+}
+
+// slice is the physical index of the i-th newest slice.
+func (af *AgePartitionedFilter) slice(i int) int {
+	return (af.newest + i) % (af.k + af.l)
+}
+
+// bit is the position of an item's bit in physical slice s. Each slice
+// hashes differently, and keeps its hash as it ages.
+func (af *AgePartitionedFilter) bit(h1, h2 uint64, s int) uint64 {
+	return uint64(s)*af.sliceBits + location(h1, h2, s, af.sliceBits)
+}
+
+func (af *AgePartitionedFilter) Add(item []byte) {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	if af.inserted == af.generation {
+		af.shift()
+	}
+	h1, h2 := hash128(item)
+	for i := 0; i < af.k; i++ {
+		pos := af.bit(h1, h2, af.slice(i))
+		af.bitset[pos/64] |= 1 << (pos % 64)
+	}
+	af.inserted++
+}
+
+// shift retires the oldest slice and reuses it, cleared, as the newest.
+func (af *AgePartitionedFilter) shift() {
+	n := af.k + af.l
+	af.newest = (af.newest + n - 1) % n
+	start := uint64(af.newest) * af.sliceBits
+	for pos := start; pos < start+af.sliceBits; pos++ {
+		af.bitset[pos/64] &^= 1 << (pos % 64)
+	}
+	af.inserted = 0
+}
+
+func (af *AgePartitionedFilter) Contains(item []byte) bool {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	h1, h2 := hash128(item)
+	run := 0
+	for i := 0; i < af.k+af.l; i++ {
+		pos := af.bit(h1, h2, af.slice(i))
+		if af.bitset[pos/64]&(1<<(pos%64)) == 0 {
+			run = 0
+			continue
+		}
+		if run++; run == af.k {
+			return true
+		}
+	}
+	return false
+}
+
+func (af *AgePartitionedFilter) Reset() {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+	clear(af.bitset)
+	af.inserted = 0
+	af.newest = 0
+}
@@ -0,0 +1,76 @@
+package bloomfilter
+
+import (
+	"strconv"
+	"testing"
+)
+
+// Every item among the last window adds matches, at every point of the
+// stream; items well past the window's upper bound mostly do not.
+func TestAgePartitionedWindow(t *testing.T) {
+	const k, l, window = 10, 7, 1000
+	af := NewAgePartitioned(k, l, window)
+	for n := 1; n <= 10*window; n++ {
+		af.Add([]byte(strconv.Itoa(n - 1)))
+		if n%97 != 0 {
+			continue
+		}
+		for i := max(n-window, 0); i < n; i++ {
+			if !af.Contains([]byte(strconv.Itoa(i))) {
+				t.Fatalf("after %d adds: item %d, within the window, is missing", n, i)
+			}
+		}
+	}
+
+	// It remembers at most window*(l+1)/l items and a generation more, so
+	// the first 8000 of the 10000 are long gone.
+	expired := 0
+	for i := 0; i < 8*window; i++ {
+		if !af.Contains([]byte(strconv.Itoa(i))) {
+			expired++
+		}
+	}
+	if expired < 8*window*99/100 {
+		t.Errorf("%d of %d items past the window expired", expired, 8*window)
+	}
+}
+
+// Items never added match at around the rate k and l give, which for
+// k=10, l=7 is about 0.15%.
+func TestAgePartitionedFalsePositives(t *testing.T) {
+	af := NewAgePartitioned(10, 7, 1000)
+	for i := 0; i < 5000; i++ {
+		af.Add([]byte(strconv.Itoa(i)))
+	}
+	fp := 0
+	for i := 0; i < 100000; i++ {
+		if af.Contains([]byte("absent" + strconv.Itoa(i))) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / 100000; rate > 0.005 {
+		t.Errorf("false-positive rate %.4f, want about 0.0015", rate)
+	}
+
+	af.Reset()
+	if af.Contains([]byte("4999")) {
+		t.Error("Reset left an item")
+	}
+	af.Add([]byte("a"))
+	if !af.Contains([]byte("a")) {
+		t.Error("item missing after Reset")
+	}
+}
+
+func TestNewAgePartitionedInvalid(t *testing.T) {
+	for _, kl := range [][2]int{{0, 7}, {10, 0}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewAgePartitioned(%d, %d) did not panic", kl[0], kl[1])
+				}
+			}()
+			NewAgePartitioned(kl[0], kl[1], 1000)
+		}()
+	}
+}
@@ -0,0 +1,106 @@
+// Package analysis measures how a filter actually behaves, so that a new
+// configuration or hasher can be checked against the theory before it is
+// deployed.
+package analysis
+
+import (
+	"encoding/binary"
+	"iter"
+	"math"
+	"math/rand/v2"
+)
+
+// z95 is the standard normal quantile for a two-sided 95% interval.
+const z95 = 1.959963984540054
+
+// Filter is anything that answers membership queries, such as a
+// bloomfilter.BloomFilter or any of its variants.
+type Filter interface {
+	Contains(item []byte) bool
+}
+
+// Report is the outcome of Measure.
+type Report struct {
+	// Trials is how many absent keys were tried, and FalsePositives how
+	// many of them Contains reported present.
+	Trials         int
+	FalsePositives int
+	// Observed is FalsePositives/Trials, with Low and High the bounds of
+	// its 95% Wilson score interval.
+	Observed  float64
+	Low, High float64
+	// Expected is the filter's own estimate, from its
+	// EstimatedFalsePositiveRate method, or NaN if it has none.
+	Expected float64
+	// ZScore is how many standard errors Observed lies above Expected.
+	ZScore float64
+}
+
+// Consistent reports whether Expected falls inside the 95% interval of
+// Observed. A filter whose hashing is sound fails this about one time in
+// twenty by chance; one that fails it repeatedly, above all with a large
+// positive ZScore, is spreading its keys unevenly.
+func (r Report) Consistent() bool {
+	return r.Low <= r.Expected && r.Expected <= r.High
+}
+
+// Measure queries f with every key of absent, which must all be keys never
+// added to f, and compares the rate of false positives with what f expects.
+// Use RandomKeys, or keys drawn from the same distribution as production
+// ones to catch weaknesses a uniform sample would miss.
+func Measure(f Filter, absent iter.Seq[[]byte]) Report {
+	var r Report
+	for key := range absent {
+		r.Trials++
+		if f.Contains(key) {
+			r.FalsePositives++
+		}
+	}
+
+	r.Expected = math.NaN()
+	if e, ok := f.(interface{ EstimatedFalsePositiveRate() float64 }); ok {
+		r.Expected = e.EstimatedFalsePositiveRate()
+	}
+	if r.Trials == 0 {
+		r.Observed, r.Low, r.High, r.ZScore = math.NaN(), 0, 1, math.NaN()
+		return r
+	}
+
+	n := float64(r.Trials)
+	r.Observed = float64(r.FalsePositives) / n
+	r.Low, r.High = wilson(r.Observed, n)
+	r.ZScore = (r.Observed - r.Expected) / math.Sqrt(r.Expected*(1-r.Expected)/n)
+	return r
+}
+
+// wilson returns the 95% Wilson score interval for a proportion p observed
+// over n trials, which unlike the normal approximation stays sensible for
+// the tiny rates filters are built for.
+func wilson(p, n float64) (float64, float64) {
+	z2 := z95 * z95
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	half := z95 / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return max(center-half, 0), min(center+half, 1)
+}
+
+// RandomKeys yields n pseudo-random keys of size bytes from seed. Each key
+// starts with a 0xff byte followed by random bytes, so they are absent from
+// any filter that holds none with that prefix, such as one filled with
+// text; otherwise a size of 16 or more makes a collision with real keys
+// vanishingly unlikely. The yielded slice is reused between keys.
+func RandomKeys(n, size int, seed uint64) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+		size = max(size, 1)
+		key := make([]byte, 1+8*((size+6)/8))
+		key[0] = 0xff
+		for range n {
+			for i := 1; i < len(key); i += 8 {
+				binary.LittleEndian.PutUint64(key[i:], rng.Uint64())
+			}
+			if !yield(key[:size]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+// A sound filter measures close to its own estimate.
+func TestMeasure(t *testing.T) {
+	bf := bloomfilter.NewWithEstimates(10000, 0.01)
+	for i := 0; i < 10000; i++ {
+		bf.AddString(strconv.Itoa(i))
+	}
+	r := Measure(bf, RandomKeys(100000, 16, 1))
+	if r.Trials != 100000 || r.Observed != float64(r.FalsePositives)/1e5 {
+		t.Fatalf("report %+v", r)
+	}
+	if !r.Consistent() || math.Abs(r.ZScore) > 4 {
+		t.Errorf("observed %.4f in [%.4f, %.4f], expected %.4f, z %.1f", r.Observed, r.Low, r.High, r.Expected, r.ZScore)
+	}
+	if !(r.Low < r.Observed && r.Observed < r.High) {
+		t.Errorf("interval [%f, %f] misses %f", r.Low, r.High, r.Observed)
+	}
+}
+
+// always claims every key, far more than the rate it reports.
+type always struct{}
+
+func (always) Contains([]byte) bool                { return true }
+func (always) EstimatedFalsePositiveRate() float64 { return 0.01 }
+
+// never has no estimate of its own.
+type never struct{}
+
+func (never) Contains([]byte) bool { return false }
+
+func TestMeasureInconsistent(t *testing.T) {
+	r := Measure(always{}, RandomKeys(1000, 16, 1))
+	if r.FalsePositives != 1000 || r.Observed != 1 || r.High != 1 {
+		t.Errorf("report %+v", r)
+	}
+	if r.Consistent() || r.ZScore < 100 {
+		t.Errorf("inconsistent filter passed: z %.1f", r.ZScore)
+	}
+
+	r = Measure(never{}, RandomKeys(1000, 16, 1))
+	if r.Observed != 0 || r.Low != 0 || r.High <= 0 || !math.IsNaN(r.Expected) || r.Consistent() {
+		t.Errorf("filter without an estimate: report %+v", r)
+	}
+
+	r = Measure(always{}, RandomKeys(0, 16, 1))
+	if r.Trials != 0 || !math.IsNaN(r.Observed) || r.Low != 0 || r.High != 1 || !r.Consistent() {
+		t.Errorf("no trials: report %+v", r)
+	}
+}
+
+func TestRandomKeys(t *testing.T) {
+	var first [][]byte
+	for key := range RandomKeys(100, 13, 7) {
+		if len(key) != 13 || key[0] != 0xff {
+			t.Fatalf("key %x: want 13 bytes after 0xff", key)
+		}
+		first = append(first, bytes.Clone(key))
+	}
+	if len(first) != 100 || bytes.Equal(first[0], first[1]) {
+		t.Fatal("keys repeat")
+	}
+	i := 0
+	for key := range RandomKeys(100, 13, 7) {
+		if !bytes.Equal(key, first[i]) {
+			t.Fatalf("key %d differs for the same seed", i)
+		}
+		i++
+	}
+	for key := range RandomKeys(100, 13, 8) {
+		if bytes.Equal(key, first[0]) {
+			t.Error("another seed gave the same keys")
+		}
+		break
+	}
+	for key := range RandomKeys(1, 0, 7) {
+		if len(key) != 1 {
+			t.Errorf("size 0 gave a %d-byte key", len(key))
+		}
+	}
+}
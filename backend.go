@@ -0,0 +1,54 @@
+package bloomfilter
+
+// Backend stores the bit array of a BloomFilter somewhere other than a packed
+// slice: outside the process, so that several filters, possibly in different
+// processes, can share one logical filter, or in a more compact form such as
+// SparseBackend. Positions are bit indexes in [0, size).
+type Backend interface {
+	SetBits(positions []uint64) error
+	TestBits(positions []uint64) (bool, error)
+	// Words returns a copy of the bit array, packed like BloomFilter's own
+	// storage: bit i is bit i%64 of word i/64.
+	Words(size uint64) ([]uint64, error)
+	Clear() error
+}
+
+// NewWithBackend returns a filter that keeps its bits in backend instead of
+// in memory. Count reflects only the Add calls made through this filter.
+//
+// Because Add and Contains do not return errors, a failing backend makes
+// Add a no-op and Contains report true, which never produces a false
+// negative. The failure is available from Err.
+func NewWithBackend(size uint, numHashes int, backend Backend) *BloomFilter {
+	return New(size, numHashes, WithBackend(backend))
+}
+
+// Err returns the most recent backend error, if any.
+func (bf *BloomFilter) Err() error {
+	if err := bf.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (bf *BloomFilter) setErr(err error) {
+	bf.err.Store(&err)
+}
+
+func (bf *BloomFilter) positions(h1, h2 uint64) []uint64 {
+	positions := make([]uint64, bf.numHashes)
+	for i := range positions {
+		positions[i] = bf.location(h1, h2, i)
+	}
+	return positions
+}
+
+// words returns the bit array to read from: the live slice for in-memory
+// filters, which callers must read with atomic loads, or a fresh copy
+// fetched from the backend.
+func (bf *BloomFilter) words() ([]uint64, error) {
+	if bf.backend == nil {
+		return bf.bitset, nil
+	}
+	return bf.backend.Words(bf.size)
+}
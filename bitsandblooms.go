@@ -0,0 +1,137 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync/atomic"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+var ErrInvalidBitsAndBlooms = errors.New("bloomfilter: invalid bits-and-blooms bloom filter")
+
+// BitsAndBloomsFilter is bit-for-bit compatible with the BloomFilter of
+// github.com/bits-and-blooms/bloom/v3. Its words are laid out like a
+// bits-and-blooms bitset.BitSet, so Words and BitsAndBloomsFromWords trade
+// bits with bloom.FromWithM and BitSet.Words without conversion, and
+// Serialize and DeserializeBitsAndBlooms use the layout of the bloom
+// package's WriteTo and ReadFrom.
+type BitsAndBloomsFilter struct {
+	data      []uint64
+	m         uint64
+	numHashes int
+	// length is the bitset's own length in bits, which its encoding keeps.
+	length uint64
+}
+
+// NewBitsAndBlooms sizes a filter exactly as bloom.NewWithEstimates does.
+func NewBitsAndBlooms(n uint, fp float64) *BitsAndBloomsFilter {
+	if fp <= 0 || fp >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
+	}
+	n = max(n, 1)
+	m := math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2))
+	k := math.Ceil(math.Ln2 * m / float64(n))
+	return &BitsAndBloomsFilter{
+		data:      make([]uint64, wordsFor(uint64(m))),
+		m:         max(uint64(m), 1),
+		numHashes: max(int(k), 1),
+		length:    uint64(m),
+	}
+}
+
+// BitsAndBloomsFromWords is bloom.FromWithM: a filter of m bits and
+// numHashes hashes over words, which it uses in place.
+func BitsAndBloomsFromWords(words []uint64, m uint64, numHashes int) (*BitsAndBloomsFilter, error) {
+	if m == 0 || numHashes < 1 || uint64(len(words)) < (m+63)/64 {
+		return nil, ErrInvalidBitsAndBlooms
+	}
+	return &BitsAndBloomsFilter{data: words, m: m, numHashes: numHashes, length: 64 * uint64(len(words))}, nil
+}
+
+// babHashes returns the four base hashes of the bloom package: murmur3 of
+// item, and of item followed by a 1 byte.
+func babHashes(item []byte) [4]uint64 {
+	var h [4]uint64
+	h[0], h[1] = hashing.Murmur3x64_128(item, 0)
+	h[2], h[3] = hashing.Murmur3x64_128Append(item, 1, 0)
+	return h
+}
+
+func (bb *BitsAndBloomsFilter) location(h [4]uint64, i int) uint64 {
+	ii := uint64(i)
+	return (h[ii%2] + ii*h[2+(((ii+(ii%2))%4)/2)]) % bb.m
+}
+
+func (bb *BitsAndBloomsFilter) Add(item []byte) {
+	h := babHashes(item)
+	for i := 0; i < bb.numHashes; i++ {
+		index := bb.location(h, i)
+		atomic.OrUint64(&bb.data[index/64], 1<<(index%64))
+	}
+}
+
+func (bb *BitsAndBloomsFilter) Contains(item []byte) bool {
+	h := babHashes(item)
+	for i := 0; i < bb.numHashes; i++ {
+		index := bb.location(h, i)
+		if atomic.LoadUint64(&bb.data[index/64])&(1<<(index%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (bb *BitsAndBloomsFilter) AddString(item string) {
+	bb.Add(stringBytes(item))
+}
+
+func (bb *BitsAndBloomsFilter) ContainsString(item string) bool {
+	return bb.Contains(stringBytes(item))
+}
+
+// Words returns a copy of the bit array.
+func (bb *BitsAndBloomsFilter) Words() []uint64 {
+	words := make([]uint64, len(bb.data))
+	for i := range words {
+		words[i] = atomic.LoadUint64(&bb.data[i])
+	}
+	return words
+}
+
+// Serialize writes m and the hash count, then the bitset as BitSet.WriteTo
+// does: its length in bits and its words, all big-endian.
+func (bb *BitsAndBloomsFilter) Serialize() []byte {
+	serialized := make([]byte, 24+8*len(bb.data))
+	binary.BigEndian.PutUint64(serialized[0:8], bb.m)
+	binary.BigEndian.PutUint64(serialized[8:16], uint64(bb.numHashes))
+	binary.BigEndian.PutUint64(serialized[16:24], bb.length)
+	for i := range bb.data {
+		binary.BigEndian.PutUint64(serialized[24+8*i:], atomic.LoadUint64(&bb.data[i]))
+	}
+	return serialized
+}
+
+func DeserializeBitsAndBlooms(data []byte) (*BitsAndBloomsFilter, error) {
+	if len(data) < 24 {
+		return nil, ErrInvalidBitsAndBlooms
+	}
+	m := binary.BigEndian.Uint64(data[0:8])
+	k := binary.BigEndian.Uint64(data[8:16])
+	length := binary.BigEndian.Uint64(data[16:24])
+	numWords := length/64 + min(length%64, 1)
+	if k == 0 || k > MaxHashes || uint64(len(data)-24)/8 != numWords || len(data)%8 != 0 {
+		return nil, ErrInvalidBitsAndBlooms
+	}
+	words := make([]uint64, numWords)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint64(data[24+8*i:])
+	}
+	bb, err := BitsAndBloomsFromWords(words, m, int(k))
+	if err != nil {
+		return nil, err
+	}
+	bb.length = length
+	return bb, nil
+}
@@ -0,0 +1,83 @@
+package bloomfilter
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// The golden vectors below come from github.com/bits-and-blooms/bloom/v3:
+// NewWithEstimates(1000, 0.01), AddString of item0 … item499, then WriteTo.
+const (
+	babGoldenHeader = "000000000000257200000000000000070000000000002572"
+	babGoldenData   = "9e11073482e60df4b002d782dd231066c722996c9fa4542d2c17603784e20dd0"
+	babGoldenLen    = 1224
+)
+
+func TestBitsAndBloomsGolden(t *testing.T) {
+	bb := NewBitsAndBlooms(1000, 0.01)
+	for i := 0; i < 500; i++ {
+		bb.AddString("item" + strconv.Itoa(i))
+	}
+	data := bb.Serialize()
+	if len(data) != babGoldenLen {
+		t.Fatalf("serialized %d bytes, want %d", len(data), babGoldenLen)
+	}
+	if got := hex.EncodeToString(data[:24]); got != babGoldenHeader {
+		t.Errorf("header = %s, want %s", got, babGoldenHeader)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != babGoldenData {
+		t.Errorf("serialized filter hashes to %s, want %s", got, babGoldenData)
+	}
+
+	decoded, err := DeserializeBitsAndBlooms(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Serialize(); string(got) != string(data) {
+		t.Error("re-serialized filter differs")
+	}
+	words, err := BitsAndBloomsFromWords(bb.Words(), 9586, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 500; i++ {
+		if !decoded.ContainsString("item"+strconv.Itoa(i)) || !words.ContainsString("item"+strconv.Itoa(i)) {
+			t.Fatalf("decoded filter lacks item%d", i)
+		}
+	}
+}
+
+func TestDeserializeBitsAndBloomsInvalid(t *testing.T) {
+	valid := NewBitsAndBlooms(100, 0.01).Serialize()
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", valid[:23]},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing word", append(valid[:len(valid):len(valid)], make([]byte, 8)...)},
+		{"no bits", babHeader(0, 7, 64, 1)},
+		{"no hashes", babHeader(64, 0, 64, 1)},
+		{"too many hashes", babHeader(64, MaxHashes+1, 64, 1)},
+		{"length past end", babHeader(64, 7, 1<<40, 1)},
+		{"m past bitset", babHeader(65, 7, 64, 1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DeserializeBitsAndBlooms(tc.data); !errors.Is(err, ErrInvalidBitsAndBlooms) {
+				t.Errorf("got %v, want ErrInvalidBitsAndBlooms", err)
+			}
+		})
+	}
+}
+
+func babHeader(m, k, length uint64, words int) []byte {
+	data := binary.BigEndian.AppendUint64(nil, m)
+	data = binary.BigEndian.AppendUint64(data, k)
+	data = binary.BigEndian.AppendUint64(data, length)
+	return append(data, make([]byte, 8*words)...)
+}
@@ -0,0 +1,112 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math"
+	"math/bits"
+	"unsafe"
+)
+
+var ErrBitsetLength = errors.New("bloomfilter: bit array does not match the filter size")
+
+// NewFromBitset returns a filter over words, an existing bit array packed
+// like the filter's own storage: bit i is bit i%64 of word i/64. The filter
+// uses words in place, so the caller must not touch them afterwards. It
+// needs exactly enough words for size bits, with none of the bits past size
+// set. Count starts at the ApproxCardinality of the bits.
+//
+// A github.com/bits-and-blooms/bitset BitSet packs its words the same way,
+// so NewFromBitset(b.Words(), uint64(b.Len()), k) wraps one, and
+// bitset.FromWithLength(size, words) turns the result of Words into one.
+func NewFromBitset(words []uint64, size uint64, numHashes int, opts ...Option) (*BloomFilter, error) {
+	if numHashes < 1 || numHashes > MaxHashes {
+		return nil, ErrInvalidHashCount
+	}
+	var cfg BloomFilter
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.backend != nil {
+		panic("bloomfilter: NewFromBitset does not support a Backend")
+	}
+	if size == 0 || cfg.partitioned && size < uint64(numHashes) {
+		return nil, ErrInvalidSize
+	}
+	if len(words) != wordsFor(size) || words[len(words)-1]&^lastWordMask(size) != 0 {
+		return nil, ErrBitsetLength
+	}
+	bf := New64(size, numHashes, append(opts, withBitset(words))...)
+	bf.count.Store(uint64(math.Round(bf.ApproxCardinality())))
+	bf.armWatermarks()
+	return bf, nil
+}
+
+// NewFromBytes is NewFromBitset for a bit array of bytes, bit i being bit
+// i%8 of byte i/8, as an array of little-endian words is laid out. data
+// must hold a whole number of words. It is used in place when the host is
+// little-endian and data is 8-byte aligned, and copied otherwise.
+func NewFromBytes(data []byte, size uint64, numHashes int, opts ...Option) (*BloomFilter, error) {
+	if len(data)%8 != 0 {
+		return nil, ErrBitsetLength
+	}
+	var words []uint64
+	if len(data) > 0 && littleEndianHost && uintptr(unsafe.Pointer(&data[0]))%8 == 0 {
+		words = unsafe.Slice((*uint64)(unsafe.Pointer(&data[0])), len(data)/8)
+	} else {
+		words = make([]uint64, len(data)/8)
+		for i := range words {
+			words[i] = binary.LittleEndian.Uint64(data[8*i:])
+		}
+	}
+	return NewFromBitset(words, size, numHashes, opts...)
+}
+
+// Words returns a copy of the bit array, packed as NewFromBitset takes it.
+func (bf *BloomFilter) Words() ([]uint64, error) {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	words, err := bf.words()
+	if err != nil || bf.backend != nil {
+		return words, err
+	}
+	out := make([]uint64, len(words))
+	for i := range out {
+		out[i] = atomicLoad(words, i)
+	}
+	return out, nil
+}
+
+// SetBitPositions returns the positions of the set bits in increasing
+// order, read word by word as the sequence is consumed rather than from a
+// copy, so bits set meanwhile may or may not appear. A filter with a Backend
+// is read in one go; if that fails the sequence is empty, see Err.
+func (bf *BloomFilter) SetBitPositions() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		bf.mu.RLock()
+		words, err := bf.words()
+		bf.mu.RUnlock()
+		if err != nil {
+			bf.setErr(err)
+			return
+		}
+		for i := range words {
+			for w := atomicLoad(words, i); w != 0; w &= w - 1 {
+				if !yield(uint64(i)*64 + uint64(bits.TrailingZeros64(w))) {
+					return
+				}
+			}
+		}
+	}
+}
+
+var littleEndianHost = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// withBitset makes New use words instead of allocating a bit array.
+func withBitset(words []uint64) Option {
+	return func(bf *BloomFilter) {
+		bf.bitset = words
+	}
+}
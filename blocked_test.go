@@ -0,0 +1,121 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestBlockedAddContains(t *testing.T) {
+	bf := NewBlockedWithEstimates(10000, 0.01)
+	for i := 0; i < 10000; i++ {
+		bf.Add([]byte(strconv.Itoa(i)))
+	}
+	for i := 0; i < 10000; i++ {
+		if !bf.Contains([]byte(strconv.Itoa(i))) {
+			t.Fatalf("item %d added but not found", i)
+		}
+	}
+	var fp int
+	for i := 10000; i < 20000; i++ {
+		if bf.Contains([]byte(strconv.Itoa(i))) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / 10000; rate > 0.03 {
+		t.Errorf("false-positive rate %v, want about 0.01", rate)
+	}
+}
+
+func TestBlockedRoundTrip(t *testing.T) {
+	bf := NewBlockedWithEstimates(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		bf.Add([]byte(strconv.Itoa(i)))
+	}
+	decoded, err := DeserializeBlocked(bf.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !decoded.Contains([]byte(strconv.Itoa(i))) {
+			t.Fatalf("decoded filter lacks %d", i)
+		}
+	}
+}
+
+func TestDeserializeBlockedInvalid(t *testing.T) {
+	valid := NewBlocked(1024, 4).Serialize()
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", valid[:23]},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing bytes", append(valid[:len(valid):len(valid)], 0)},
+		{"no blocks", blockedHeader(0, 4, 0)},
+		{"partial block", blockedHeader(600, 4, 128)},
+		{"size past end", blockedHeader(1<<40, 4, 64)},
+		{"size wraps", blockedHeader(1<<63, 4, 0)},
+		{"no hashes", blockedHeader(512, 0, 64)},
+		{"too many hashes", blockedHeader(512, MaxHashes+1, 64)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DeserializeBlocked(tc.data); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("got %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+}
+
+func blockedHeader(size, numHashes uint64, n int) []byte {
+	data := make([]byte, 24+n)
+	binary.LittleEndian.PutUint64(data[0:], size)
+	binary.LittleEndian.PutUint64(data[16:], numHashes)
+	return data
+}
+
+func blockedBenchFilter(k int) (*BlockedBloomFilter, [][]byte) {
+	bf := NewBlocked(1<<20, k)
+	items := make([][]byte, 4096)
+	for i := range items {
+		items[i] = []byte("benchmark item " + strconv.Itoa(i))
+	}
+	for _, item := range items[:len(items)/2] {
+		bf.Add(item)
+	}
+	return bf, items
+}
+
+func BenchmarkBlockedAdd(b *testing.B) {
+	for _, k := range []int{4, 7, 10} {
+		bf, items := blockedBenchFilter(k)
+		b.Run("k="+strconv.Itoa(k), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; b.Loop(); i++ {
+				bf.Add(items[i%len(items)])
+			}
+		})
+	}
+}
+
+func BenchmarkBlockedContains(b *testing.B) {
+	for _, k := range []int{4, 7, 10} {
+		bf, items := blockedBenchFilter(k)
+		present, absent := items[:len(items)/2], items[len(items)/2:]
+		for _, set := range []struct {
+			name  string
+			items [][]byte
+		}{
+			{"present", present},
+			{"absent", absent},
+		} {
+			b.Run("k="+strconv.Itoa(k)+"/"+set.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; b.Loop(); i++ {
+					bf.Contains(set.items[i%len(set.items)])
+				}
+			})
+		}
+	}
+}
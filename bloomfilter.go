@@ -1,139 +1,684 @@
 package bloomfilter
 
 import (
-	"encoding/binary"
-	"hash"
-	"hash/fnv"
+	"context"
+	"errors"
 	"math"
+	"math/bits"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
+var (
+	ErrIncompatible = errors.New("bloomfilter: filters differ in size, hash count, hasher, seed or layout")
+	ErrNotFoldable  = errors.New("bloomfilter: only filters with an even probe range can be folded")
+)
+
+// BloomFilter is safe for concurrent use. Add and Contains never block: bits
+// are set with atomic OR and read with atomic loads. mu only serializes the
+// whole-filter operations (Reset, Union, Serialize) against each other.
 type BloomFilter struct {
-	mu			sync.RWMutex
-	bitset  	[]bool
-	size    	uint
-	hashFuncs 	[]hash.Hash64
-	count 		uint
+	mu          sync.RWMutex
+	bitset      []uint64
+	size        uint64
+	numHashes   int
+	partitioned bool
+	count       atomic.Uint64
+	backend     Backend
+	err         atomic.Pointer[error]
+	mmap        *mmapState
+	hasher      Hasher
+	seed        uint64
+	tasLocks    [16]sync.Mutex
+	snapshotMu  sync.Mutex
+	snapshots   atomic.Pointer[[]*snapshotPages]
+	changes     atomic.Pointer[changeLog]
+	queries     atomic.Uint64
+	positives   atomic.Uint64
+
+	watermarks    []*watermark
+	nextWatermark atomic.Uint64
 }
 
-func New(size uint, numHashes int) * BloomFilter {
-	bf := &BloomFilter {
-		bitset: make([]bool, size),
-		size: size,
-		hashFuncs: make([]hash.Hash64, numHashes),
-		count: 0,
-	}
+// New returns a filter of size bits probed by numHashes hash functions,
+// configured by opts.
+func New(size uint, numHashes int, opts ...Option) *BloomFilter {
+	return New64(uint64(size), numHashes, opts...)
+}
 
-	for i := 0; i <= numHashes; i++ {
-		bf.hashFuncs[i] = fnv.New64()
+// New64 is New with a 64-bit size, for filters of more than 2^32 bits on
+// platforms where uint is 32 bits. Sizes and bit offsets are uint64
+// throughout, so a filter behaves the same on every platform.
+func New64(size uint64, numHashes int, opts ...Option) *BloomFilter {
+	bf := &BloomFilter{
+		size:      size,
+		numHashes: numHashes,
+		hasher:    defaultHasher,
+	}
+	for _, opt := range opts {
+		opt(bf)
 	}
 
+	if bf.partitioned && (numHashes < 1 || size < uint64(numHashes)) {
+		panic("bloomfilter: partitioned filter needs at least one bit per hash")
+	}
+	if bf.backend == nil && bf.bitset == nil {
+		bf.bitset = make([]uint64, wordsFor(size))
+	}
+	bf.armWatermarks()
 	return bf
 }
 
-func (bf *BloomFilter) Add(item []byte) {
-	bf.mu.RLock()
-	defer bf.mu.RUnlock()
+// MaxHashes bounds the hash count NewValidated accepts. 128 probes already
+// reach a false-positive rate of 2^-128, so larger counts are mistakes.
+const MaxHashes = 128
+
+var (
+	ErrInvalidSize      = errors.New("bloomfilter: size must be positive, and at least the hash count when partitioned")
+	ErrInvalidHashCount = errors.New("bloomfilter: hash count must be between 1 and MaxHashes")
+)
+
+// NewValidated is New, but reports parameters that would make the filter
+// unusable as an error instead of failing later.
+func NewValidated(size uint, numHashes int, opts ...Option) (*BloomFilter, error) {
+	if numHashes < 1 || numHashes > MaxHashes {
+		return nil, ErrInvalidHashCount
+	}
+	var cfg BloomFilter
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if size == 0 || cfg.partitioned && size < uint(numHashes) {
+		return nil, ErrInvalidSize
+	}
+	return New(size, numHashes, opts...), nil
+}
+
+// NewPartitioned is New with WithPartitioned.
+func NewPartitioned(size uint, numHashes int) *BloomFilter {
+	return New(size, numHashes, WithPartitioned())
+}
+
+// NewWithEstimates returns a filter sized to hold expectedElements items at
+// the given false-positive rate, choosing the bit count and hash count itself.
+func NewWithEstimates(expectedElements uint, fpRate float64, opts ...Option) *BloomFilter {
+	size, numHashes := EstimateParameters(expectedElements, fpRate)
+	return New(size, numHashes, opts...)
+}
+
+// EstimateParameters returns the bit count m and hash count k that hold n
+// items at false-positive rate p with the fewest bits, as NewWithEstimates
+// uses. It panics unless p is in (0, 1).
+func EstimateParameters(n uint, p float64) (m uint, k int) {
+	if p <= 0 || p >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
+	}
+	if n == 0 {
+		n = 1
+	}
+
+	bits := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	return uint(bits), max(int(math.Round(bits/float64(n)*math.Ln2)), 1)
+}
+
+// FalsePositiveRate is the expected false-positive rate of a filter of m
+// bits and k hashes holding n items, the inverse of EstimateParameters.
+func FalsePositiveRate(m uint, k int, n uint) float64 {
+	return falsePositiveRate(float64(m), float64(k), float64(n))
+}
+
+// BudgetParameters returns the lowest false-positive rate a bit array of
+// budget bytes reaches holding n items, and the hash count that gets it.
+func BudgetParameters(budget uint64, n uint) (fpRate float64, k int) {
+	m, items := float64(8*budget), float64(max(n, 1))
+	best := max(math.Floor(m/items*math.Ln2), 1)
+	fpRate = falsePositiveRate(m, best, items)
+	if p := falsePositiveRate(m, best+1, items); p < fpRate {
+		best, fpRate = best+1, p
+	}
+	return fpRate, int(best)
+}
 
-	for _, h := range bf.hashFuncs {
-		h.Reset()
-		h.Write(item)
-		index := h.Sum64() % uint64(bf.size)
-		bf.bitset[index] = true
+// BudgetCapacity returns the most items a bit array of budget bytes holds
+// at false-positive rate p: NewWithEstimates sizes a filter for that many
+// within budget. It panics unless p is in (0, 1).
+func BudgetCapacity(budget uint64, p float64) uint {
+	if p <= 0 || p >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
 	}
+	return uint(math.Floor(-float64(8*budget) * (math.Ln2 * math.Ln2) / math.Log(p)))
+}
+
+func falsePositiveRate(m, k, n float64) float64 {
+	return math.Pow(1-math.Exp(-k*n/m), k)
+}
+
+func (bf *BloomFilter) Add(item []byte) {
+	bf.add(bf.hash(item))
 }
 
 func (bf *BloomFilter) Contains(item []byte) bool {
-	bf.mu.RLock()
-	defer bf.mu.RUnlock()
+	return bf.contains(bf.hash(item))
+}
+
+// AddUint64 adds an integer key by mixing it directly instead of hashing its
+// bytes. It is a different key space from Add: test such keys with
+// ContainsUint64.
+func (bf *BloomFilter) AddUint64(item uint64) {
+	bf.add(bf.seeded(hashUint64(item)))
+}
 
-	for _, h := range bf.hashFuncs {
-		h.Reset()
-		h.Write(item)
-		index := h.Sum64() % uint64(bf.size)
-		if !bf.bitset[index] {
+func (bf *BloomFilter) ContainsUint64(item uint64) bool {
+	return bf.contains(bf.seeded(hashUint64(item)))
+}
+
+func (bf *BloomFilter) add(h1, h2 uint64) {
+	if bf.backend != nil {
+		if err := bf.backend.SetBits(bf.positions(h1, h2)); err != nil {
+			bf.setErr(err)
+			return
+		}
+		bf.added(1)
+		return
+	}
+	for i := 0; i < bf.numHashes; i++ {
+		bf.setBit(bf.location(h1, h2, i))
+	}
+	bf.added(1)
+}
+
+func (bf *BloomFilter) contains(h1, h2 uint64) bool {
+	bf.queries.Add(1)
+	if bf.backend != nil {
+		found, err := bf.backend.TestBits(bf.positions(h1, h2))
+		if err != nil {
+			bf.setErr(err)
+			found = true
+		}
+		if found {
+			bf.positives.Add(1)
+		}
+		return found
+	}
+	for i := 0; i < bf.numHashes; i++ {
+		if !bf.testBit(bf.location(h1, h2, i)) {
 			return false
 		}
 	}
+	bf.positives.Add(1)
 	return true
 }
 
-func (bf *BloomFilter) Count() uint {
-	bf.mu.RLock()
-	defer bf.mu.RUnlock()
-	return bf.count
+// AddString is Add for string keys, without converting to []byte.
+func (bf *BloomFilter) AddString(item string) {
+	bf.Add(stringBytes(item))
 }
 
-func (bf *BloomFilter) EstimatedFalsePositiveRate() float64 {
-	bf.mu.RLock()
-	defer bf.mu.RUnlock()
+func (bf *BloomFilter) ContainsString(item string) bool {
+	return bf.Contains(stringBytes(item))
+}
+
+// AddMany adds every item. With a Backend all probes go out in a single
+// SetBits call, so a Redis-backed filter makes one round trip per batch.
+func (bf *BloomFilter) AddMany(items [][]byte) {
+	if bf.backend != nil {
+		positions := make([]uint64, 0, len(items)*bf.numHashes)
+		for _, item := range items {
+			h1, h2 := bf.hash(item)
+			for i := 0; i < bf.numHashes; i++ {
+				positions = append(positions, bf.location(h1, h2, i))
+			}
+		}
+		bf.setPositions(positions, len(items))
+		return
+	}
+	for _, item := range items {
+		h1, h2 := bf.hash(item)
+		for i := 0; i < bf.numHashes; i++ {
+			bf.setBit(bf.location(h1, h2, i))
+		}
+	}
+	bf.added(uint64(len(items)))
+}
+
+// contextBatch is how many items AddManyContext adds between checks of its
+// context.
+const contextBatch = 4096
 
-	k := float64(len(bf.hashFuncs))
-	n := float64(bf.count)
-	m := float64(bf.size)
+// AddManyContext is AddMany in batches, stopping between them once ctx is
+// done. It returns how many of items were added, always a prefix, and ctx's
+// error if it stopped early.
+func (bf *BloomFilter) AddManyContext(ctx context.Context, items [][]byte) (int, error) {
+	for done := 0; done < len(items); {
+		if err := ctx.Err(); err != nil {
+			return done, err
+		}
+		n := min(len(items)-done, contextBatch)
+		bf.AddMany(items[done : done+n])
+		done += n
+	}
+	return len(items), nil
+}
+
+// setPositions sets the bits at positions in the Backend in one call, and
+// counts n items.
+func (bf *BloomFilter) setPositions(positions []uint64, n int) {
+	if err := bf.backend.SetBits(positions); err != nil {
+		bf.setErr(err)
+		return
+	}
+	bf.added(uint64(n))
+}
 
-	return math.Pow(1 - math.Exp(-k * n / m), k)
+// ContainsMany reports Contains for each item.
+func (bf *BloomFilter) ContainsMany(items [][]byte) []bool {
+	found := make([]bool, len(items))
+	for j, item := range items {
+		found[j] = bf.Contains(item)
+	}
+	return found
+}
+
+// TestAndAdd adds item and reports whether it was probably present already.
+// Concurrent calls with the same item are serialized, so exactly one of them
+// reports false for a new item; with a Backend this holds only among callers
+// sharing this BloomFilter value.
+func (bf *BloomFilter) TestAndAdd(item []byte) bool {
+	return bf.testAndAdd(bf.hash(item))
+}
+
+// TestAndAddUint64 is TestAndAdd for keys added with AddUint64.
+func (bf *BloomFilter) TestAndAddUint64(item uint64) bool {
+	return bf.testAndAdd(bf.seeded(hashUint64(item)))
+}
+
+func (bf *BloomFilter) testAndAdd(h1, h2 uint64) bool {
+	lock := &bf.tasLocks[h1%uint64(len(bf.tasLocks))]
+	lock.Lock()
+	defer lock.Unlock()
+
+	if bf.backend != nil {
+		positions := bf.positions(h1, h2)
+		found, err := bf.backend.TestBits(positions)
+		if err != nil {
+			bf.setErr(err)
+			return true
+		}
+		if found {
+			return true
+		}
+		if err := bf.backend.SetBits(positions); err != nil {
+			bf.setErr(err)
+			return false
+		}
+		bf.added(1)
+		return false
+	}
+
+	found := true
+	for i := 0; i < bf.numHashes; i++ {
+		index := bf.location(h1, h2, i)
+		bf.preserve(int(index / 64))
+		if atomic.OrUint64(&bf.bitset[index/64], 1<<(index%64))&(1<<(index%64)) == 0 {
+			bf.touched(int(index / 64))
+			found = false
+		}
+	}
+	if !found {
+		bf.added(1)
+	}
+	return found
+}
+
+func (bf *BloomFilter) Count() uint {
+	return uint(bf.count.Load())
+}
+
+func (bf *BloomFilter) EstimatedFalsePositiveRate() float64 {
+	return falsePositiveRate(float64(bf.size), float64(bf.numHashes), float64(bf.count.Load()))
 }
 
-func (bf *BloomFilter) OptimalNumhashes (expectedElements uint) int {
+// OptimalNumhashes is the hash count that minimizes false positives for
+// expectedElements items in this filter's size.
+//
+// Deprecated: EstimateParameters sizes a filter without building one first.
+func (bf *BloomFilter) OptimalNumhashes(expectedElements uint) int {
 	return int(math.Ceil(float64(bf.size) / float64(expectedElements) * math.Log(2)))
 }
 
 func (bf *BloomFilter) Reset() {
 	bf.mu.Lock()
 	defer bf.mu.Unlock()
-	bf.bitset = make([]bool, bf.size)
-	bf.count = 0
+	if bf.backend != nil {
+		if err := bf.backend.Clear(); err != nil {
+			bf.setErr(err)
+			return
+		}
+		bf.count.Store(0)
+		bf.armWatermarks()
+		return
+	}
+	// Lock-free writers may hold the current slice, so clear it in place
+	// rather than swapping in a new one.
+	for i := range bf.bitset {
+		bf.preserve(i)
+		if atomic.SwapUint64(&bf.bitset[i], 0) != 0 {
+			bf.touched(i)
+		}
+	}
+	bf.count.Store(0)
+	if bf.mmap != nil && bf.mmap.shared {
+		bf.resetShared()
+	}
+	bf.armWatermarks()
 }
 
 func (bf *BloomFilter) Union(other *BloomFilter) *BloomFilter {
-	if bf.size != other.size || len(bf.hashFuncs) != len(other.hashFuncs) {
+	result := bf.combine(other, func(a, b uint64) uint64 { return a | b })
+	if result != nil {
+		result.count.Store(bf.count.Load() + other.count.Load())
+	}
+	return result
+}
+
+// Clone returns an independent in-memory copy of the filter, including its
+// hash configuration and count. A filter with a Backend or a mapped file is
+// copied into memory. It returns nil if the bits cannot be read; see Err.
+func (bf *BloomFilter) Clone() *BloomFilter {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	words, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
 		return nil
 	}
 
-	bf.mu.Lock()
-	other.mu.RLock()
-	defer bf.mu.Unlock()
+	clone := New64(bf.size, bf.numHashes, hashedLike(bf))
+	clone.partitioned = bf.partitioned
+	clone.count.Store(bf.count.Load())
+	for i := range clone.bitset {
+		clone.bitset[i] = atomic.LoadUint64(&words[i])
+	}
+	return clone
+}
+
+// Fold returns a filter half the size of bf holding the same items, by ORing
+// the top half of the bit array into the bottom half (in a partitioned
+// filter, the top half of each partition into its bottom half). Probes are
+// reduced modulo the size, and x mod m mod m/2 = x mod m/2, so the result
+// answers exactly as a filter built at half the size would, with a
+// correspondingly higher false-positive rate. The probe range, the size or
+// the partition size, must be even; powers of two can be folded repeatedly.
+func (bf *BloomFilter) Fold() (*BloomFilter, error) {
+	span := bf.size
+	if bf.partitioned {
+		span = bf.size / uint64(bf.numHashes)
+	}
+	if span%2 != 0 || span == 0 {
+		return nil, ErrNotFoldable
+	}
+	half := span / 2
+
+	bf.mu.RLock()
 	defer bf.mu.RUnlock()
 
-	result := New(bf.size, len(bf.hashFuncs))
-	for i := range bf.bitset {
-		result.bitset[i] = bf.bitset[i] || other.bitset[i]
+	words, err := bf.words()
+	if err != nil {
+		return nil, err
+	}
+
+	var folded *BloomFilter
+	if bf.partitioned {
+		folded = New64(half*uint64(bf.numHashes), bf.numHashes, WithPartitioned(), hashedLike(bf))
+	} else {
+		folded = New64(half, bf.numHashes, hashedLike(bf))
+	}
+	for i := range words {
+		for w := atomic.LoadUint64(&words[i]); w != 0; w &= w - 1 {
+			j := uint64(i)*64 + uint64(bits.TrailingZeros64(w))
+			part, offset := j/uint64(span), j%uint64(span)
+			if part >= uint64(bf.numHashes) && bf.partitioned {
+				continue
+			}
+			folded.setBit(part*half + offset%half)
+		}
+	}
+	folded.count.Store(bf.count.Load())
+	return folded, nil
+}
+
+// Intersect returns the bitwise AND of two filters of the same shape, or nil
+// if the shapes differ. Every item added to both filters tests positive, but
+// the result is not the filter of their common items: a bit set by different
+// items in each filter survives too, so it has more false positives than a
+// filter built from the common items alone. Count reports the smaller of the
+// two counts, an upper bound on the overlap.
+func (bf *BloomFilter) Intersect(other *BloomFilter) *BloomFilter {
+	result := bf.combine(other, func(a, b uint64) uint64 { return a & b })
+	if result != nil {
+		result.count.Store(min(bf.count.Load(), other.count.Load()))
+	}
+	return result
+}
+
+// UnionWith ORs the bits of other into bf in place. Add and Contains may run
+// concurrently; each word is merged atomically.
+func (bf *BloomFilter) UnionWith(other *BloomFilter) error {
+	if bf == other {
+		return nil
+	}
+	n, err := bf.orWith(other)
+	if err != nil {
+		return err
+	}
+	bf.added(n)
+	return nil
+}
+
+// Join merges other into bf like UnionWith, but idempotently: joining a
+// filter twice, or joining filters in any order, ends in the same state.
+// That makes filters a state-based CRDT that replicas can exchange and
+// merge freely, as long as none of them is Reset. Rather than summing, the
+// count becomes the estimated number of distinct items, from ApproxCardinality.
+func (bf *BloomFilter) Join(other *BloomFilter) error {
+	if bf == other {
+		return nil
+	}
+	n, err := bf.orWith(other)
+	if err != nil {
+		return err
+	}
+	target := max(n, uint64(math.Round(bf.ApproxCardinality())))
+	if cur := bf.count.Load(); target > cur {
+		bf.added(target - cur)
+	}
+	return nil
+}
+
+// orWith ORs other's bits into bf and returns other's count. It unlocks
+// before returning, so a watermark hook fired by the caller is free to
+// Reset bf.
+func (bf *BloomFilter) orWith(other *BloomFilter) (uint64, error) {
+	if !bf.compatible(other) {
+		return 0, ErrIncompatible
+	}
+
+	unlock := rlockPair(&bf.mu, &other.mu)
+	defer unlock()
+	b, err := other.words()
+	if err != nil {
+		return 0, err
 	}
+	if bf.backend != nil {
+		var positions []uint64
+		for i := range b {
+			for w := atomic.LoadUint64(&b[i]); w != 0; w &= w - 1 {
+				positions = append(positions, uint64(i)*64+uint64(bits.TrailingZeros64(w)))
+			}
+		}
+		if err := bf.backend.SetBits(positions); err != nil {
+			return 0, err
+		}
+	} else {
+		for i := range bf.bitset {
+			if w := atomic.LoadUint64(&b[i]); w != 0 {
+				bf.preserve(i)
+				if old := atomic.OrUint64(&bf.bitset[i], w); w&^old != 0 {
+					bf.touched(i)
+				}
+			}
+		}
+	}
+	return other.count.Load(), nil
+}
+
+func (bf *BloomFilter) compatible(other *BloomFilter) bool {
+	return bf.size == other.size && bf.numHashes == other.numHashes && bf.partitioned == other.partitioned &&
+		sameHasher(bf.hasher, other.hasher) && bf.seed == other.seed
+}
+
+// rlockPair read-locks the mutexes of two filters in address order, so that
+// concurrent operations on a pair in opposite orders cannot deadlock, and
+// locks a filter paired with itself only once.
+func rlockPair(a, b *sync.RWMutex) (unlock func()) {
+	if a == b {
+		a.RLock()
+		return a.RUnlock
+	}
+	if uintptr(unsafe.Pointer(a)) > uintptr(unsafe.Pointer(b)) {
+		a, b = b, a
+	}
+	a.RLock()
+	b.RLock()
+	return func() {
+		b.RUnlock()
+		a.RUnlock()
+	}
+}
 
-	result.count = bf.count + other.count
+// combine applies op word by word to the bits of two filters of the same
+// shape and returns the result as a new filter.
+func (bf *BloomFilter) combine(other *BloomFilter, op func(a, b uint64) uint64) *BloomFilter {
+	if !bf.compatible(other) {
+		return nil
+	}
+
+	unlock := rlockPair(&bf.mu, &other.mu)
+	defer unlock()
+
+	a, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
+		return nil
+	}
+	b, err := other.words()
+	if err != nil {
+		other.setErr(err)
+		return nil
+	}
 
+	result := New64(bf.size, bf.numHashes, hashedLike(bf))
+	result.partitioned = bf.partitioned
+	for i := range result.bitset {
+		result.bitset[i] = op(atomic.LoadUint64(&a[i]), atomic.LoadUint64(&b[i]))
+	}
 	return result
 }
 
 func (bf *BloomFilter) Serialize() []byte {
+	data, err := bf.MarshalBinary()
+	if err != nil {
+		bf.setErr(err)
+		return nil
+	}
+	return data
+}
+
+// Deserialize reads the output of Serialize, restoring the hash count,
+// hasher and seed. It also reads the older version 1 format, which records
+// none of them; filters read from it hash items with 64-bit FNV-1 mod size,
+// as that format did, with a single hash function.
+func Deserialize(data []byte) (*BloomFilter, error) {
+	return unmarshal(data, DecodeOptions{})
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the Serialize
+// format. Unlike Serialize it reports backend errors directly.
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
 	bf.mu.RLock()
 	defer bf.mu.RUnlock()
 
-	serialized := make([]byte, 8 + 8 + bf.size / 8 + 1)
-	binary.LittleEndian.PutUint64(serialized[0:8], uint64(bf.size))
-	binary.LittleEndian.PutUint64(serialized[8:16], uint64(bf.count))
+	words, err := bf.words()
+	if err != nil {
+		return nil, err
+	}
+	return bf.serializeV2(words, NoCompression)
+}
 
-	for i, bit := range bf.bitset {
-		if bit {
-			serialized[16 + i / 8] |= 1 << (uint(i) % 8)
-		}
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces the
+// whole filter, so it is meant for a zero or freshly declared BloomFilter, as
+// gob and most caches use it; a backend or mapped file the filter had is
+// detached, not written to.
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	decoded, err := unmarshal(data, DecodeOptions{Hasher: bf.hasher})
+	if err != nil {
+		return err
 	}
+	bf.replace(decoded)
+	return nil
+}
 
-	return serialized
+func (bf *BloomFilter) replace(decoded *BloomFilter) {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	bf.bitset = decoded.bitset
+	bf.size = decoded.size
+	bf.numHashes = decoded.numHashes
+	bf.partitioned = decoded.partitioned
+	bf.hasher = decoded.hasher
+	bf.seed = decoded.seed
+	bf.count.Store(decoded.count.Load())
+	bf.backend = nil
+	bf.mmap = nil
+	bf.changes.Store(nil)
+	bf.armWatermarks()
 }
 
-func Deserialize(data []byte) *BloomFilter {
-	size := binary.LittleEndian.Uint64(data[0:8])
-	count := binary.LittleEndian.Uint64(data[8:16])
+// wordsFor is the number of words holding size bits. It does not overflow
+// for sizes near 2^64.
+func wordsFor(size uint64) int {
+	return int(size/64 + min(size%64, 1))
+}
 
-	bf := New(uint(size), 1)
-	bf.count = uint(count)
+// lastWordMask covers the bits of the final word that lie inside the filter.
+func lastWordMask(size uint64) uint64 {
+	if size%64 == 0 {
+		return ^uint64(0)
+	}
+	return 1<<(size%64) - 1
+}
 
-	for i := uint(0); i < bf.size; i++ {
-		if data[16 + i / 8] & (1 << (i % 8)) != 0 {
-			bf.bitset[i] = true
-		}
+func (bf *BloomFilter) location(h1, h2 uint64, i int) uint64 {
+	return probe(h1, h2, i, bf.size, bf.numHashes, bf.partitioned)
+}
+
+func (bf *BloomFilter) setBit(index uint64) {
+	bf.preserve(int(index / 64))
+	if atomic.OrUint64(&bf.bitset[index/64], 1<<(index%64))&(1<<(index%64)) == 0 {
+		bf.touched(int(index / 64))
 	}
-	return bf
-}
\ No newline at end of file
+}
+
+func (bf *BloomFilter) testBit(index uint64) bool {
+	return bf.loadWord(int(index/64))&(1<<(index%64)) != 0
+}
+
+func (bf *BloomFilter) loadWord(i int) uint64 {
+	return atomicLoad(bf.bitset, i)
+}
+
+func atomicLoad(words []uint64, i int) uint64 {
+	return atomic.LoadUint64(&words[i])
+}
@@ -0,0 +1,118 @@
+package bloomfilter
+
+import (
+	"strconv"
+	"testing"
+)
+
+// builtinFilters returns a filter for each built-in hasher, plus the seeded
+// and keyed variants, which hash differently.
+func builtinFilters() map[string]*BloomFilter {
+	return map[string]*BloomFilter{
+		"murmur3":  New(1<<16, 7, WithHasher(Murmur3)),
+		"xxhash64": New(1<<16, 7, WithHasher(XXHash64)),
+		"fnv1a128": New(1<<16, 7, WithHasher(FNV1a128)),
+		"seeded":   New(1<<16, 7, WithSeed(42)),
+		"siphash":  New(1<<16, 7, WithKey([16]byte{1, 2, 3})),
+	}
+}
+
+func TestZeroAllocs(t *testing.T) {
+	item := []byte("an item of a typical length")
+	s := string(item)
+	for name, bf := range builtinFilters() {
+		for op, f := range map[string]func(){
+			"Add":            func() { bf.Add(item) },
+			"Contains":       func() { bf.Contains(item) },
+			"TestAndAdd":     func() { bf.TestAndAdd(item) },
+			"AddString":      func() { bf.AddString(s) },
+			"ContainsString": func() { bf.ContainsString(s) },
+		} {
+			if n := testing.AllocsPerRun(100, f); n != 0 {
+				t.Errorf("%s %s: %v allocations, want 0", name, op, n)
+			}
+		}
+	}
+}
+
+func TestAddContains(t *testing.T) {
+	for name, bf := range builtinFilters() {
+		for i := 0; i < 1000; i++ {
+			bf.AddString(strconv.Itoa(i))
+		}
+		for i := 0; i < 1000; i++ {
+			if !bf.Contains([]byte(strconv.Itoa(i))) {
+				t.Fatalf("%s: item %d added but not found", name, i)
+			}
+		}
+	}
+}
+
+// zeroStepHasher hashes every item to a step of zero.
+type zeroStepHasher struct{}
+
+func (zeroStepHasher) Name() string { return "zero-step" }
+
+func (zeroStepHasher) Hash128(item []byte) (uint64, uint64) {
+	h1, _ := hash128(item)
+	return h1, 0
+}
+
+// A zero step still spreads the probes over distinct bits.
+func TestZeroStep(t *testing.T) {
+	bf := New(1<<10, 7, WithHasher(zeroStepHasher{}))
+	bf.AddString("a")
+	if n := bf.BitsSet(); n != 7 {
+		t.Errorf("7 probes set %d bits", n)
+	}
+}
+
+func benchmarkItems() [][]byte {
+	items := make([][]byte, 1024)
+	for i := range items {
+		items[i] = []byte("benchmark item " + strconv.Itoa(i))
+	}
+	return items
+}
+
+func BenchmarkAdd(b *testing.B) {
+	items := benchmarkItems()
+	for name, bf := range builtinFilters() {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; b.Loop(); i++ {
+				bf.Add(items[i%len(items)])
+			}
+		})
+	}
+}
+
+func BenchmarkContains(b *testing.B) {
+	items := benchmarkItems()
+	for name, bf := range builtinFilters() {
+		bf.AddMany(items[:len(items)/2])
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; b.Loop(); i++ {
+				bf.Contains(items[i%len(items)])
+			}
+		})
+	}
+}
+
+func BenchmarkAddString(b *testing.B) {
+	bf := New(1<<16, 7)
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		bf.AddString("benchmark item")
+	}
+}
+
+func BenchmarkContainsString(b *testing.B) {
+	bf := New(1<<16, 7)
+	bf.AddString("benchmark item")
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		bf.ContainsString("benchmark item")
+	}
+}
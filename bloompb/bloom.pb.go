@@ -0,0 +1,185 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v5.28.3
+// source: bloom.proto
+
+package bloompb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BloomFilter is a standard Bloom filter as built by the bloomfilter package.
+type BloomFilter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hash scheme used to derive probe positions, e.g. "fnv1a-128". Decoders
+	// must reject schemes they do not implement.
+	Hasher string `protobuf:"bytes,1,opt,name=hasher,proto3" json:"hasher,omitempty"`
+	// Seeds mixed into the hasher, if it takes any.
+	Seeds []uint64 `protobuf:"varint,2,rep,packed,name=seeds,proto3" json:"seeds,omitempty"`
+	// Number of bits.
+	Size      uint64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	NumHashes uint32 `protobuf:"varint,4,opt,name=num_hashes,json=numHashes,proto3" json:"num_hashes,omitempty"`
+	// Whether each hash function owns a disjoint slice of the bits.
+	Partitioned bool `protobuf:"varint,5,opt,name=partitioned,proto3" json:"partitioned,omitempty"`
+	// Number of Add calls.
+	Count uint64 `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+	// Bit i is bit i%64 of words[i/64].
+	Words         []uint64 `protobuf:"fixed64,7,rep,packed,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BloomFilter) Reset() {
+	*x = BloomFilter{}
+	mi := &file_bloom_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BloomFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BloomFilter) ProtoMessage() {}
+
+func (x *BloomFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_bloom_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BloomFilter.ProtoReflect.Descriptor instead.
+func (*BloomFilter) Descriptor() ([]byte, []int) {
+	return file_bloom_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BloomFilter) GetHasher() string {
+	if x != nil {
+		return x.Hasher
+	}
+	return ""
+}
+
+func (x *BloomFilter) GetSeeds() []uint64 {
+	if x != nil {
+		return x.Seeds
+	}
+	return nil
+}
+
+func (x *BloomFilter) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BloomFilter) GetNumHashes() uint32 {
+	if x != nil {
+		return x.NumHashes
+	}
+	return 0
+}
+
+func (x *BloomFilter) GetPartitioned() bool {
+	if x != nil {
+		return x.Partitioned
+	}
+	return false
+}
+
+func (x *BloomFilter) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *BloomFilter) GetWords() []uint64 {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+var File_bloom_proto protoreflect.FileDescriptor
+
+const file_bloom_proto_rawDesc = "" +
+	"\n" +
+	"\vbloom.proto\x12\x0ebloomfilter.v1\"\xbc\x01\n" +
+	"\vBloomFilter\x12\x16\n" +
+	"\x06hasher\x18\x01 \x01(\tR\x06hasher\x12\x14\n" +
+	"\x05seeds\x18\x02 \x03(\x04R\x05seeds\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x04R\x04size\x12\x1d\n" +
+	"\n" +
+	"num_hashes\x18\x04 \x01(\rR\tnumHashes\x12 \n" +
+	"\vpartitioned\x18\x05 \x01(\bR\vpartitioned\x12\x14\n" +
+	"\x05count\x18\x06 \x01(\x04R\x05count\x12\x14\n" +
+	"\x05words\x18\a \x03(\x06R\x05wordsB-Z+github.com/hriday-13th/bloom-filter/bloompbb\x06proto3"
+
+var (
+	file_bloom_proto_rawDescOnce sync.Once
+	file_bloom_proto_rawDescData []byte
+)
+
+func file_bloom_proto_rawDescGZIP() []byte {
+	file_bloom_proto_rawDescOnce.Do(func() {
+		file_bloom_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bloom_proto_rawDesc), len(file_bloom_proto_rawDesc)))
+	})
+	return file_bloom_proto_rawDescData
+}
+
+var file_bloom_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_bloom_proto_goTypes = []any{
+	(*BloomFilter)(nil), // 0: bloomfilter.v1.BloomFilter
+}
+var file_bloom_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_bloom_proto_init() }
+func file_bloom_proto_init() {
+	if File_bloom_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bloom_proto_rawDesc), len(file_bloom_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_bloom_proto_goTypes,
+		DependencyIndexes: file_bloom_proto_depIdxs,
+		MessageInfos:      file_bloom_proto_msgTypes,
+	}.Build()
+	File_bloom_proto = out.File
+	file_bloom_proto_goTypes = nil
+	file_bloom_proto_depIdxs = nil
+}
@@ -0,0 +1,7 @@
+// Package bloompb holds the protobuf schema for filters, so they can be
+// embedded in other messages, the BloomService gRPC API that the server
+// package implements, and the ReplicationService of the replication
+// package. Convert filters with bloomfilter's ToProto and FromProto.
+package bloompb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative bloom.proto service.proto replication.proto
@@ -0,0 +1,365 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: service.proto
+
+package bloompb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BloomService_Create_FullMethodName        = "/bloomfilter.v1.BloomService/Create"
+	BloomService_Add_FullMethodName           = "/bloomfilter.v1.BloomService/Add"
+	BloomService_MultiAdd_FullMethodName      = "/bloomfilter.v1.BloomService/MultiAdd"
+	BloomService_Contains_FullMethodName      = "/bloomfilter.v1.BloomService/Contains"
+	BloomService_MultiContains_FullMethodName = "/bloomfilter.v1.BloomService/MultiContains"
+	BloomService_Stats_FullMethodName         = "/bloomfilter.v1.BloomService/Stats"
+	BloomService_Export_FullMethodName        = "/bloomfilter.v1.BloomService/Export"
+)
+
+// BloomServiceClient is the client API for BloomService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BloomService holds named filters. Methods on a filter that does not exist
+// fail with NOT_FOUND.
+type BloomServiceClient interface {
+	// Create makes a filter, replacing any of the same name.
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error)
+	MultiAdd(ctx context.Context, in *MultiAddRequest, opts ...grpc.CallOption) (*AddResponse, error)
+	Contains(ctx context.Context, in *ContainsRequest, opts ...grpc.CallOption) (*ContainsResponse, error)
+	MultiContains(ctx context.Context, in *MultiContainsRequest, opts ...grpc.CallOption) (*MultiContainsResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// Export streams a copy of the whole filter in the Serialize format,
+	// split into chunks so that no message nears gRPC's size limit.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error)
+}
+
+type bloomServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBloomServiceClient(cc grpc.ClientConnInterface) BloomServiceClient {
+	return &bloomServiceClient{cc}
+}
+
+func (c *bloomServiceClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, BloomService_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bloomServiceClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddResponse)
+	err := c.cc.Invoke(ctx, BloomService_Add_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bloomServiceClient) MultiAdd(ctx context.Context, in *MultiAddRequest, opts ...grpc.CallOption) (*AddResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddResponse)
+	err := c.cc.Invoke(ctx, BloomService_MultiAdd_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bloomServiceClient) Contains(ctx context.Context, in *ContainsRequest, opts ...grpc.CallOption) (*ContainsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ContainsResponse)
+	err := c.cc.Invoke(ctx, BloomService_Contains_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bloomServiceClient) MultiContains(ctx context.Context, in *MultiContainsRequest, opts ...grpc.CallOption) (*MultiContainsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MultiContainsResponse)
+	err := c.cc.Invoke(ctx, BloomService_MultiContains_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bloomServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, BloomService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bloomServiceClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BloomService_ServiceDesc.Streams[0], BloomService_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportRequest, ExportChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BloomService_ExportClient = grpc.ServerStreamingClient[ExportChunk]
+
+// BloomServiceServer is the server API for BloomService service.
+// All implementations must embed UnimplementedBloomServiceServer
+// for forward compatibility.
+//
+// BloomService holds named filters. Methods on a filter that does not exist
+// fail with NOT_FOUND.
+type BloomServiceServer interface {
+	// Create makes a filter, replacing any of the same name.
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Add(context.Context, *AddRequest) (*AddResponse, error)
+	MultiAdd(context.Context, *MultiAddRequest) (*AddResponse, error)
+	Contains(context.Context, *ContainsRequest) (*ContainsResponse, error)
+	MultiContains(context.Context, *MultiContainsRequest) (*MultiContainsResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// Export streams a copy of the whole filter in the Serialize format,
+	// split into chunks so that no message nears gRPC's size limit.
+	Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error
+	mustEmbedUnimplementedBloomServiceServer()
+}
+
+// UnimplementedBloomServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBloomServiceServer struct{}
+
+func (UnimplementedBloomServiceServer) Create(context.Context, *CreateRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedBloomServiceServer) Add(context.Context, *AddRequest) (*AddResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedBloomServiceServer) MultiAdd(context.Context, *MultiAddRequest) (*AddResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MultiAdd not implemented")
+}
+func (UnimplementedBloomServiceServer) Contains(context.Context, *ContainsRequest) (*ContainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Contains not implemented")
+}
+func (UnimplementedBloomServiceServer) MultiContains(context.Context, *MultiContainsRequest) (*MultiContainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MultiContains not implemented")
+}
+func (UnimplementedBloomServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedBloomServiceServer) Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedBloomServiceServer) mustEmbedUnimplementedBloomServiceServer() {}
+func (UnimplementedBloomServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeBloomServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BloomServiceServer will
+// result in compilation errors.
+type UnsafeBloomServiceServer interface {
+	mustEmbedUnimplementedBloomServiceServer()
+}
+
+func RegisterBloomServiceServer(s grpc.ServiceRegistrar, srv BloomServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBloomServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BloomService_ServiceDesc, srv)
+}
+
+func _BloomService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BloomServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BloomService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BloomServiceServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BloomService_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BloomServiceServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BloomService_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BloomServiceServer).Add(ctx, req.(*AddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BloomService_MultiAdd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiAddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BloomServiceServer).MultiAdd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BloomService_MultiAdd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BloomServiceServer).MultiAdd(ctx, req.(*MultiAddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BloomService_Contains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BloomServiceServer).Contains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BloomService_Contains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BloomServiceServer).Contains(ctx, req.(*ContainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BloomService_MultiContains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiContainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BloomServiceServer).MultiContains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BloomService_MultiContains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BloomServiceServer).MultiContains(ctx, req.(*MultiContainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BloomService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BloomServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BloomService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BloomServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BloomService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BloomServiceServer).Export(m, &grpc.GenericServerStream[ExportRequest, ExportChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BloomService_ExportServer = grpc.ServerStreamingServer[ExportChunk]
+
+// BloomService_ServiceDesc is the grpc.ServiceDesc for BloomService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BloomService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bloomfilter.v1.BloomService",
+	HandlerType: (*BloomServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _BloomService_Create_Handler,
+		},
+		{
+			MethodName: "Add",
+			Handler:    _BloomService_Add_Handler,
+		},
+		{
+			MethodName: "MultiAdd",
+			Handler:    _BloomService_MultiAdd_Handler,
+		},
+		{
+			MethodName: "Contains",
+			Handler:    _BloomService_Contains_Handler,
+		},
+		{
+			MethodName: "MultiContains",
+			Handler:    _BloomService_MultiContains_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _BloomService_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _BloomService_Export_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "service.proto",
+}
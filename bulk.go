@@ -0,0 +1,42 @@
+package bloomfilter
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// bulkBatch is how many keys each BulkLoad worker gathers per AddMany.
+const bulkBatch = 1024
+
+// BulkLoad adds every key received from keys, hashing and setting bits on
+// workers goroutines, or GOMAXPROCS if workers is not positive, until keys
+// is closed. Bits are set with atomic OR as by Add, so the workers never
+// wait on each other; each goes through AddMany a batch at a time, which
+// also keeps them from contending on Count. Keys must not be modified once
+// sent. It returns how many keys it added.
+func (bf *BloomFilter) BulkLoad(keys <-chan []byte, workers int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch := make([][]byte, 0, bulkBatch)
+			for key := range keys {
+				if batch = append(batch, key); len(batch) == bulkBatch {
+					bf.AddMany(batch)
+					total.Add(int64(len(batch)))
+					batch = batch[:0]
+				}
+			}
+			bf.AddMany(batch)
+			total.Add(int64(len(batch)))
+		}()
+	}
+	wg.Wait()
+	return int(total.Load())
+}
@@ -0,0 +1,105 @@
+// Package client is a Go client for the BloomService gRPC API served by the
+// server package.
+package client
+
+import (
+	"context"
+	"io"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+	"github.com/hriday-13th/bloom-filter/bloompb"
+	"google.golang.org/grpc"
+)
+
+// Client calls a BloomService. It is safe for concurrent use.
+type Client struct {
+	c bloompb.BloomServiceClient
+}
+
+// New returns a client using conn, typically a *grpc.ClientConn.
+func New(conn grpc.ClientConnInterface) *Client {
+	return &Client{c: bloompb.NewBloomServiceClient(conn)}
+}
+
+// CreateWithEstimates makes a filter named name sized like NewWithEstimates,
+// replacing any of the same name.
+func (c *Client) CreateWithEstimates(ctx context.Context, name string, capacity uint64, fpRate float64) error {
+	_, err := c.c.Create(ctx, &bloompb.CreateRequest{Name: name, Capacity: capacity, FpRate: fpRate})
+	return err
+}
+
+// Create makes a filter named name of bits bits and hashes hash functions,
+// replacing any of the same name.
+func (c *Client) Create(ctx context.Context, name string, bits uint64, hashes int) error {
+	_, err := c.c.Create(ctx, &bloompb.CreateRequest{Name: name, Bits: bits, Hashes: uint32(hashes)})
+	return err
+}
+
+func (c *Client) Add(ctx context.Context, name string, item []byte) error {
+	_, err := c.c.Add(ctx, &bloompb.AddRequest{Name: name, Item: item})
+	return err
+}
+
+func (c *Client) MultiAdd(ctx context.Context, name string, items [][]byte) error {
+	_, err := c.c.MultiAdd(ctx, &bloompb.MultiAddRequest{Name: name, Items: items})
+	return err
+}
+
+func (c *Client) Contains(ctx context.Context, name string, item []byte) (bool, error) {
+	resp, err := c.c.Contains(ctx, &bloompb.ContainsRequest{Name: name, Item: item})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetPresent(), nil
+}
+
+// MultiContains returns one result per item, in order.
+func (c *Client) MultiContains(ctx context.Context, name string, items [][]byte) ([]bool, error) {
+	resp, err := c.c.MultiContains(ctx, &bloompb.MultiContainsRequest{Name: name, Items: items})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetPresent(), nil
+}
+
+func (c *Client) Stats(ctx context.Context, name string) (*bloompb.StatsResponse, error) {
+	return c.c.Stats(ctx, &bloompb.StatsRequest{Name: name})
+}
+
+// Export downloads a copy of the filter, for querying locally. It arrives
+// in chunks, so its size is not bound by the gRPC message limit.
+func (c *Client) Export(ctx context.Context, name string) (*bloomfilter.BloomFilter, error) {
+	stream, err := c.c.Export(ctx, &bloompb.ExportRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	r := &chunkReader{stream: stream}
+	bf, _, err := bloomfilter.DecodeOptions{}.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	// Read to the end of the stream, which releases it.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, err
+	}
+	return bf, nil
+}
+
+// chunkReader reads the data of an Export stream's chunks in order.
+type chunkReader struct {
+	stream grpc.ServerStreamingClient[bloompb.ExportChunk]
+	data   []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.data = chunk.GetData()
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
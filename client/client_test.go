@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+	"github.com/hriday-13th/bloom-filter/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts s on an in-memory listener and returns a client of it.
+func serve(t *testing.T, s *server.Server) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	s.RegisterGRPC(g)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return New(conn)
+}
+
+// A filter well past gRPC's default 4 MiB message limit still exports.
+func TestExport(t *testing.T) {
+	s := server.New()
+	bf := bloomfilter.New64(64<<20, 5)
+	for i := 0; i < 1000; i++ {
+		bf.AddString(strconv.Itoa(i))
+	}
+	s.Set("f", bf)
+	c := serve(t, s)
+
+	got, err := c.Export(context.Background(), "f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(bf) || got.Count() != bf.Count() {
+		t.Error("exported filter differs")
+	}
+
+	if _, err := c.Export(context.Background(), "missing"); status.Code(err) != codes.NotFound {
+		t.Errorf("missing filter: got %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+	"google.golang.org/grpc"
+)
+
+// virtualNodes is how many points each server gets on the hash ring, which
+// evens out the share of keys each one owns.
+const virtualNodes = 128
+
+// ErrNoNodes is returned when a Cluster has no servers left to try.
+var ErrNoNodes = errors.New("client: no reachable node for key")
+
+type ringPoint struct {
+	hash uint64
+	node int
+}
+
+// Cluster spreads one logical filter over several BloomService servers. A
+// consistent-hash ring assigns each key to replicas servers, so adding or
+// removing a server moves only its share of the keys. Each server holds a
+// filter of the same name covering the keys it owns.
+//
+// Adds go to every replica and succeed if any does. Contains stops at the
+// first replica that has the key and otherwise asks them all, so a replica
+// that was down for an Add causes no false negative while another is up.
+type Cluster struct {
+	name     string
+	nodes    []*Client
+	addrs    []string
+	ring     []ringPoint
+	replicas int
+
+	// Timeout bounds each call made by Add and Contains. Default 5s.
+	Timeout time.Duration
+	err     atomic.Pointer[error]
+}
+
+// NewCluster returns a cluster over the filter called name on each of
+// conns, keyed by a stable identity such as the server address, with every
+// key stored on replicas of them.
+func NewCluster(name string, conns map[string]grpc.ClientConnInterface, replicas int) *Cluster {
+	if len(conns) == 0 {
+		panic("client: cluster needs at least one node")
+	}
+	c := &Cluster{name: name, replicas: min(max(replicas, 1), len(conns)), Timeout: 5 * time.Second}
+	for addr := range conns {
+		c.addrs = append(c.addrs, addr)
+	}
+	// Sort so that every process builds the same ring.
+	slices.Sort(c.addrs)
+	for i, addr := range c.addrs {
+		c.nodes = append(c.nodes, New(conns[addr]))
+		for v := range virtualNodes {
+			h, _ := hashing.Sum128(fmt.Appendf(nil, "%s#%d", addr, v))
+			c.ring = append(c.ring, ringPoint{hash: h, node: i})
+		}
+	}
+	slices.SortFunc(c.ring, func(a, b ringPoint) int {
+		switch {
+		case a.hash < b.hash:
+			return -1
+		case a.hash > b.hash:
+			return 1
+		}
+		return 0
+	})
+	return c
+}
+
+// owners returns the nodes holding item, starting with its primary.
+func (c *Cluster) owners(item []byte) []int {
+	h, _ := hashing.Sum128(item)
+	start := sort.Search(len(c.ring), func(i int) bool { return c.ring[i].hash >= h })
+	owners := make([]int, 0, c.replicas)
+	for i := 0; len(owners) < c.replicas; i++ {
+		node := c.ring[(start+i)%len(c.ring)].node
+		if !slices.Contains(owners, node) {
+			owners = append(owners, node)
+		}
+	}
+	return owners
+}
+
+// CreateWithEstimates makes the filter on every node, each sized for its
+// share of capacity items.
+func (c *Cluster) CreateWithEstimates(ctx context.Context, capacity uint64, fpRate float64) error {
+	share := (capacity*uint64(c.replicas) + uint64(len(c.nodes)) - 1) / uint64(len(c.nodes))
+	var errs []error
+	for i, node := range c.nodes {
+		if err := node.CreateWithEstimates(ctx, c.name, share, fpRate); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.addrs[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AddContext adds item to its replicas, failing only if none took it.
+func (c *Cluster) AddContext(ctx context.Context, item []byte) error {
+	var errs []error
+	for _, i := range c.owners(item) {
+		if err := c.nodes[i].Add(ctx, c.name, item); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.addrs[i], err))
+		}
+	}
+	if len(errs) == c.replicas {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// ContainsContext asks item's replicas in turn, failing only if none
+// answered.
+func (c *Cluster) ContainsContext(ctx context.Context, item []byte) (bool, error) {
+	var errs []error
+	for _, i := range c.owners(item) {
+		found, err := c.nodes[i].Contains(ctx, c.name, item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.addrs[i], err))
+			continue
+		}
+		if found {
+			return true, nil
+		}
+	}
+	if len(errs) == c.replicas {
+		return false, errors.Join(append(errs, ErrNoNodes)...)
+	}
+	return false, nil
+}
+
+// Add is AddContext with Timeout, for use like a local filter. Errors are
+// kept for Err.
+func (c *Cluster) Add(item []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	if err := c.AddContext(ctx, item); err != nil {
+		c.setErr(err)
+	}
+}
+
+// Contains is ContainsContext with Timeout, for use like a local filter.
+// Like a filter with a failing Backend, it reports true when no replica
+// answers, so that an outage cannot cause false negatives; see Err.
+func (c *Cluster) Contains(item []byte) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	found, err := c.ContainsContext(ctx, item)
+	if err != nil {
+		c.setErr(err)
+		return true
+	}
+	return found
+}
+
+func (c *Cluster) AddString(item string) {
+	c.Add([]byte(item))
+}
+
+func (c *Cluster) ContainsString(item string) bool {
+	return c.Contains([]byte(item))
+}
+
+// Err returns the last error from Add or Contains, if any.
+func (c *Cluster) Err() error {
+	if p := c.err.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (c *Cluster) setErr(err error) {
+	c.err.Store(&err)
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hriday-13th/bloom-filter/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// node is a BloomService behind an httptest server, which grpc.Server
+// handles over HTTP/2.
+type node struct {
+	s    *server.Server
+	ts   *httptest.Server
+	conn *grpc.ClientConn
+}
+
+func startNodes(t *testing.T, n int) ([]*node, map[string]grpc.ClientConnInterface) {
+	t.Helper()
+	var nodes []*node
+	conns := make(map[string]grpc.ClientConnInterface)
+	for range n {
+		s := server.New()
+		g := grpc.NewServer()
+		s.RegisterGRPC(g)
+		ts := httptest.NewUnstartedServer(g)
+		ts.EnableHTTP2 = true
+		ts.StartTLS()
+		t.Cleanup(ts.Close)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ts.Certificate())
+		addr := strings.TrimPrefix(ts.URL, "https://")
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: roots})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		nodes = append(nodes, &node{s: s, ts: ts, conn: conn})
+		conns[addr] = conn
+	}
+	return nodes, conns
+}
+
+// stop takes n down for good.
+func (n *node) stop() {
+	n.ts.CloseClientConnections()
+	n.ts.Close()
+}
+
+// Each key lands on exactly the nodes the ring assigns it, the same in every
+// process, and the keys spread over all the nodes.
+func TestClusterRouting(t *testing.T) {
+	nodes, conns := startNodes(t, 3)
+	c := NewCluster("f", conns, 1)
+	ctx := context.Background()
+	if err := c.CreateWithEstimates(ctx, 3000, 0.01); err != nil {
+		t.Fatal(err)
+	}
+
+	owned := make(map[string]uint)
+	other := NewCluster("f", conns, 1)
+	for i := 0; i < 300; i++ {
+		item := []byte(strconv.Itoa(i))
+		if err := c.AddContext(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+		owners := c.owners(item)
+		if o := other.owners(item); len(o) != 1 || o[0] != owners[0] {
+			t.Fatalf("item %d: owners %v in one cluster and %v in another", i, owners, o)
+		}
+		owned[c.addrs[owners[0]]]++
+	}
+	for _, n := range nodes {
+		addr := strings.TrimPrefix(n.ts.URL, "https://")
+		if got := n.s.Filter("f").Count(); got != owned[addr] || got == 0 {
+			t.Errorf("%s holds %d items, owns %d", addr, got, owned[addr])
+		}
+	}
+	for i := 0; i < 300; i++ {
+		if !c.ContainsString(strconv.Itoa(i)) {
+			t.Fatalf("item %d not found", i)
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+// With two replicas, losing a node loses no keys and no adds.
+func TestClusterFailover(t *testing.T) {
+	nodes, conns := startNodes(t, 3)
+	c := NewCluster("f", conns, 2)
+	ctx := context.Background()
+	if err := c.CreateWithEstimates(ctx, 3000, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := c.AddContext(ctx, []byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nodes[0].stop()
+	for i := 0; i < 200; i++ {
+		item := []byte(strconv.Itoa(i))
+		if i >= 100 {
+			if err := c.AddContext(ctx, item); err != nil {
+				t.Fatalf("add %d with a node down: %v", i, err)
+			}
+		}
+		found, err := c.ContainsContext(ctx, item)
+		if err != nil || !found {
+			t.Fatalf("item %d with a node down: %t, %v", i, found, err)
+		}
+	}
+}
+
+// With one replica, a key whose node is down cannot be answered for: the
+// error says so, and Contains reports true rather than a false negative.
+func TestClusterNoReplica(t *testing.T) {
+	nodes, conns := startNodes(t, 2)
+	c := NewCluster("f", conns, 1)
+	ctx := context.Background()
+	if err := c.CreateWithEstimates(ctx, 1000, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	nodes[0].stop()
+
+	down := strings.TrimPrefix(nodes[0].ts.URL, "https://")
+	var item []byte
+	for i := 0; item == nil; i++ {
+		if key := []byte(strconv.Itoa(i)); c.addrs[c.owners(key)[0]] == down {
+			item = key
+		}
+	}
+	if _, err := c.ContainsContext(ctx, item); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("ContainsContext: got %v, want ErrNoNodes", err)
+	}
+	if !c.Contains(item) || c.Err() == nil {
+		t.Error("Contains with the owner down: want true and an error from Err")
+	}
+	if err := c.AddContext(ctx, item); err == nil {
+		t.Error("AddContext with the owner down succeeded")
+	}
+}
@@ -0,0 +1,234 @@
+// Command bloom creates, fills and queries Bloom filter files from the shell.
+//
+//	bloom create [-n items -fp rate | -bits m -k hashes] [-hasher name] FILE
+//	bloom add FILE [INPUT...]
+//	bloom check [-a] FILE [KEY...]
+//	bloom merge OUT IN...
+//	bloom stats FILE
+//	bloom dump FILE
+//
+// add and check read one key per line from the inputs, or from standard
+// input if none are given; check takes keys as arguments too. check prints
+// the keys that are probably present, or with -a every key with its result,
+// and like grep exits 1 if none were. dump prints the index of every set
+// bit. Files are written with SaveFile, and compressed files written by
+// SaveFileCompressed are read as well.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+var hashers = map[string]bloomfilter.Hasher{
+	bloomfilter.Murmur3.Name():  bloomfilter.Murmur3,
+	bloomfilter.XXHash64.Name(): bloomfilter.XXHash64,
+	bloomfilter.FNV1a128.Name(): bloomfilter.FNV1a128,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	commands := map[string]func([]string) error{
+		"create": create,
+		"add":    add,
+		"check":  check,
+		"merge":  merge,
+		"stats":  stats,
+		"dump":   dump,
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "bloom:", err)
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bloom create|add|check|merge|stats|dump ...")
+	os.Exit(2)
+}
+
+func create(args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	n := fs.Uint("n", 0, "expected number of items")
+	fp := fs.Float64("fp", 0.01, "target false-positive rate, with -n")
+	m := fs.Uint64("bits", 0, "size in bits, instead of -n")
+	k := fs.Int("k", 0, "number of hash functions, with -bits")
+	hasherName := fs.String("hasher", bloomfilter.Murmur3.Name(), "hash function")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("create: need exactly one FILE")
+	}
+	hasher, ok := hashers[*hasherName]
+	if !ok {
+		return fmt.Errorf("create: unknown hasher %q", *hasherName)
+	}
+
+	var bf *bloomfilter.BloomFilter
+	switch {
+	case *n > 0 && *m == 0:
+		if *fp <= 0 || *fp >= 1 {
+			return fmt.Errorf("create: -fp must be in (0, 1)")
+		}
+		bf = bloomfilter.NewWithEstimates(*n, *fp, bloomfilter.WithHasher(hasher))
+	case *m > 0 && *n == 0:
+		if *k < 1 || *k > bloomfilter.MaxHashes {
+			return fmt.Errorf("create: -k must be between 1 and %d", bloomfilter.MaxHashes)
+		}
+		bf = bloomfilter.New64(*m, *k, bloomfilter.WithHasher(hasher))
+	default:
+		return fmt.Errorf("create: give either -n or -bits")
+	}
+	return bf.SaveFile(fs.Arg(0))
+}
+
+func add(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("add: need a FILE")
+	}
+	bf, err := bloomfilter.LoadFile(args[0])
+	if err != nil {
+		return err
+	}
+	if err := eachLine(args[1:], func(key []byte) { bf.Add(key) }); err != nil {
+		return err
+	}
+	return bf.SaveFile(args[0])
+}
+
+func check(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	all := fs.Bool("a", false, "print every key with its result")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		return fmt.Errorf("check: need a FILE")
+	}
+	bf, err := bloomfilter.LoadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	found := false
+	test := func(key []byte) {
+		ok := bf.Contains(key)
+		found = found || ok
+		switch {
+		case *all:
+			fmt.Fprintf(w, "%t\t%s\n", ok, key)
+		case ok:
+			fmt.Fprintf(w, "%s\n", key)
+		}
+	}
+	if fs.NArg() > 1 {
+		for _, key := range fs.Args()[1:] {
+			test([]byte(key))
+		}
+	} else if err := eachLine(nil, test); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !found {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func merge(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("merge: need OUT and at least one IN")
+	}
+	merged, err := bloomfilter.LoadFile(args[1])
+	if err != nil {
+		return err
+	}
+	for _, path := range args[2:] {
+		bf, err := bloomfilter.LoadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := merged.UnionWith(bf); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return merged.SaveFile(args[0])
+}
+
+func stats(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("stats: need exactly one FILE")
+	}
+	bf, err := bloomfilter.LoadFile(args[0])
+	if err != nil {
+		return err
+	}
+	m := bf.ToProto()
+	fmt.Printf("bits:          %d\n", m.GetSize())
+	fmt.Printf("hashes:        %d\n", m.GetNumHashes())
+	fmt.Printf("hasher:        %s\n", m.GetHasher())
+	fmt.Printf("partitioned:   %t\n", m.GetPartitioned())
+	fmt.Printf("count:         %d\n", bf.Count())
+	fmt.Printf("bits set:      %d\n", bf.BitsSet())
+	fmt.Printf("fill ratio:    %.4f\n", bf.FillRatio())
+	fmt.Printf("cardinality:   %.0f\n", bf.ApproxCardinality())
+	fmt.Printf("est. fp rate:  %.6g\n", bf.EstimatedFalsePositiveRate())
+	return nil
+}
+
+func dump(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("dump: need exactly one FILE")
+	}
+	bf, err := bloomfilter.LoadFile(args[0])
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	for i, word := range bf.ToProto().GetWords() {
+		for ; word != 0; word &= word - 1 {
+			fmt.Fprintln(w, uint64(i)*64+uint64(bits.TrailingZeros64(word)))
+		}
+	}
+	return w.Flush()
+}
+
+// eachLine calls fn with every line of the named files, or of standard input
+// if there are none.
+func eachLine(paths []string, fn func([]byte)) error {
+	scan := func(r io.Reader) error {
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, 1<<20)
+		for s.Scan() {
+			fn(s.Bytes())
+		}
+		return s.Err()
+	}
+	if len(paths) == 0 {
+		return scan(os.Stdin)
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		err = scan(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the command itself when the tests re-execute their binary,
+// so that tests see its output and exit status as a shell would.
+func TestMain(m *testing.M) {
+	if os.Getenv("BLOOM_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// bloom runs the command with args in dir, feeding it stdin.
+func bloom(t *testing.T, dir, stdin string, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "BLOOM_TEST_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return string(out), exit.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+// The steps share a directory and run in order, each on the files the
+// earlier ones left.
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keys"), []byte("c\nd\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name  string
+		args  []string
+		stdin string
+		out   string // a substring of the output, if set
+		code  int
+	}{
+		{"create", []string{"create", "-n", "1000", "f"}, "", "", 0},
+		{"create by bits", []string{"create", "-bits", "9586", "-k", "7", "-hasher", "xxhash64", "g"}, "", "", 0},
+		{"add from stdin", []string{"add", "f"}, "a\nb\n", "", 0},
+		{"add from a file", []string{"add", "f", "keys"}, "", "", 0},
+		{"check keys", []string{"check", "f", "a", "zzz", "d"}, "", "a\nd\n", 0},
+		{"check stdin", []string{"check", "f"}, "b\nzzz\n", "b\n", 0},
+		{"check all", []string{"check", "-a", "f", "a", "zzz"}, "", "true\ta\nfalse\tzzz\n", 0},
+		{"check none found", []string{"check", "f", "zzz"}, "", "", 1},
+		{"stats", []string{"stats", "f"}, "", "count:         4\n", 0},
+		{"merge", []string{"merge", "out", "f", "f"}, "", "", 0},
+		{"merged check", []string{"check", "out", "a", "b", "c", "d"}, "", "a\nb\nc\nd\n", 0},
+		{"merge mismatched", []string{"merge", "out", "f", "g"}, "", "", 2},
+		{"create without a file", []string{"create", "-n", "1000"}, "", "", 2},
+		{"create unknown hasher", []string{"create", "-n", "1000", "-hasher", "nope", "h"}, "", "", 2},
+		{"create both sizes", []string{"create", "-n", "1000", "-bits", "64", "h"}, "", "", 2},
+		{"create bad rate", []string{"create", "-n", "1000", "-fp", "1", "h"}, "", "", 2},
+		{"missing file", []string{"stats", "missing"}, "", "", 2},
+		{"unknown command", []string{"frobnicate"}, "", "", 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, code := bloom(t, dir, tc.stdin, tc.args...)
+			if code != tc.code {
+				t.Errorf("exit status %d, want %d; output:\n%s", code, tc.code, out)
+			}
+			if !strings.Contains(out, tc.out) {
+				t.Errorf("output %q lacks %q", out, tc.out)
+			}
+		})
+	}
+}
+
+// dump prints each set bit once, as many as stats counts.
+func TestDump(t *testing.T) {
+	dir := t.TempDir()
+	bloom(t, dir, "", "create", "-bits", "1024", "-k", "3", "f")
+	bloom(t, dir, "a\nb\nc\n", "add", "f")
+
+	out, code := bloom(t, dir, "", "dump", "f")
+	if code != 0 {
+		t.Fatalf("exit status %d", code)
+	}
+	lines := strings.Fields(out)
+	if len(lines) == 0 || len(lines) > 9 {
+		t.Fatalf("dump printed %d bits for 3 items of 3 hashes", len(lines))
+	}
+	stats, _ := bloom(t, dir, "", "stats", "f")
+	if want := "bits set:      " + strconv.Itoa(len(lines)) + "\n"; !strings.Contains(stats, want) {
+		t.Errorf("stats %q lacks %q", stats, want)
+	}
+	seen := make(map[string]bool)
+	for _, l := range lines {
+		if seen[l] {
+			t.Errorf("bit %s printed twice", l)
+		}
+		seen[l] = true
+	}
+}
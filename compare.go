@@ -0,0 +1,100 @@
+package bloomfilter
+
+import (
+	"sync/atomic"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// Equal reports whether two filters have the same shape, hasher and bits.
+// Count is not compared, as replicas that merged the same data in a
+// different order can disagree on it.
+func (bf *BloomFilter) Equal(other *BloomFilter) bool {
+	if !bf.compatible(other) {
+		return false
+	}
+	if bf == other {
+		return true
+	}
+
+	unlock := rlockPair(&bf.mu, &other.mu)
+	defer unlock()
+
+	a, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
+		return false
+	}
+	b, err := other.words()
+	if err != nil {
+		other.setErr(err)
+		return false
+	}
+	for i := range a {
+		if atomic.LoadUint64(&a[i]) != atomic.LoadUint64(&b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubsetOf reports whether every bit set in bf is also set in other, as
+// holds when other is a union that includes bf. Filters of different shapes
+// are never subsets of each other.
+func (bf *BloomFilter) IsSubsetOf(other *BloomFilter) bool {
+	if !bf.compatible(other) {
+		return false
+	}
+	if bf == other {
+		return true
+	}
+
+	unlock := rlockPair(&bf.mu, &other.mu)
+	defer unlock()
+
+	a, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
+		return false
+	}
+	b, err := other.words()
+	if err != nil {
+		other.setErr(err)
+		return false
+	}
+	for i := range a {
+		if atomic.LoadUint64(&a[i])&^atomic.LoadUint64(&b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Fingerprint returns an XXH64 digest of the filter's shape, hasher and bits,
+// so replicas can check that they agree without exchanging the bits. Filters
+// that are Equal have the same fingerprint. It returns 0 if the bits cannot
+// be read; see Err.
+func (bf *BloomFilter) Fingerprint() uint64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	words, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
+		return 0
+	}
+
+	h := bf.header()
+	h.count = 0
+	d := hashing.NewXXDigest(0)
+	buf := h.appendTo(make([]byte, 0, streamChunkSize))
+	for i := 0; i < len(words); {
+		n := min(len(words)-i, (streamChunkSize-len(buf))/8)
+		buf = appendWords(buf, words[i:i+n])
+		d.Write(buf)
+		buf = buf[:0]
+		i += n
+	}
+	d.Write(buf)
+	return d.Sum64()
+}
@@ -0,0 +1,109 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects how SerializeCompressed encodes the bit array. Sparse
+// filters are mostly zero words and shrink by orders of magnitude.
+type Compression uint8
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+	Snappy
+)
+
+// The compression is stored in the high nibble of the header flags. A
+// compressed bit array is preceded by its compressed length as a uint64.
+const formatCompressionShift = 4
+
+var ErrUnknownCompression = errors.New("bloomfilter: unknown compression")
+
+// SerializeCompressed is like Serialize but compresses the bit array.
+// Deserialize, UnmarshalBinary and ReadFrom detect the compression from the
+// header.
+func (bf *BloomFilter) SerializeCompressed(c Compression) ([]byte, error) {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	words, err := bf.words()
+	if err != nil {
+		return nil, err
+	}
+	return bf.serializeV2(words, c)
+}
+
+func (bf *BloomFilter) serializeV2(words []uint64, c Compression) ([]byte, error) {
+	h := bf.header()
+	h.flags |= uint8(c) << formatCompressionShift
+	if c == NoCompression {
+		serialized := make([]byte, 0, h.encodedLen()+8*len(words)+4)
+		serialized = appendWords(h.appendTo(serialized), words)
+		return binary.LittleEndian.AppendUint32(serialized, crc32.Checksum(serialized, castagnoli)), nil
+	}
+
+	var payload bytes.Buffer
+	zw, err := compressor(&payload, c)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, streamChunkSize)
+	for i := 0; i < len(words); {
+		n := min(len(words)-i, streamChunkSize/8)
+		if _, err := zw.Write(appendWords(buf, words[i:i+n])); err != nil {
+			return nil, err
+		}
+		i += n
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	serialized := make([]byte, 0, h.encodedLen()+8+payload.Len()+4)
+	serialized = h.appendTo(serialized)
+	serialized = binary.LittleEndian.AppendUint64(serialized, uint64(payload.Len()))
+	serialized = append(serialized, payload.Bytes()...)
+	return binary.LittleEndian.AppendUint32(serialized, crc32.Checksum(serialized, castagnoli)), nil
+}
+
+func appendWords(dst []byte, words []uint64) []byte {
+	for i := range words {
+		dst = binary.LittleEndian.AppendUint64(dst, atomicLoad(words, i))
+	}
+	return dst
+}
+
+func compressor(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	case Snappy:
+		return snappy.NewBufferedWriter(w), nil
+	}
+	return nil, ErrUnknownCompression
+}
+
+func decompressor(r io.Reader, c Compression) (io.Reader, error) {
+	switch c {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		// A single-threaded decoder needs no Close to release goroutines.
+		return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	case Snappy:
+		return snappy.NewReader(r), nil
+	}
+	return nil, ErrUnknownCompression
+}
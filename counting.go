@@ -0,0 +1,91 @@
+package bloomfilter
+
+import (
+	"math"
+	"sync"
+)
+
+// CountingBloomFilter replaces each bit with a small counter so that items
+// can be removed again. Counters saturate at 255 and are never decremented
+// past that point, since the true count is no longer known.
+type CountingBloomFilter struct {
+	mu        sync.RWMutex
+	counters  []uint8
+	size      uint
+	numHashes int
+	count     uint
+}
+
+func NewCounting(size uint, numHashes int) *CountingBloomFilter {
+	return &CountingBloomFilter{
+		counters:  make([]uint8, size),
+		size:      size,
+		numHashes: numHashes,
+	}
+}
+
+func (cf *CountingBloomFilter) Add(item []byte) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	h1, h2 := hash128(item)
+	for i := 0; i < cf.numHashes; i++ {
+		index := location(h1, h2, i, uint64(cf.size))
+		if cf.counters[index] < math.MaxUint8 {
+			cf.counters[index]++
+		}
+	}
+	cf.count++
+}
+
+func (cf *CountingBloomFilter) Contains(item []byte) bool {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+
+	h1, h2 := hash128(item)
+	for i := 0; i < cf.numHashes; i++ {
+		if cf.counters[location(h1, h2, i, uint64(cf.size))] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove deletes one occurrence of item. It reports false, leaving the filter
+// untouched, when item is definitely not present. Removing an item that was
+// never added but tests positive corrupts the filter for other items.
+func (cf *CountingBloomFilter) Remove(item []byte) bool {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	h1, h2 := hash128(item)
+	for i := 0; i < cf.numHashes; i++ {
+		if cf.counters[location(h1, h2, i, uint64(cf.size))] == 0 {
+			return false
+		}
+	}
+
+	for i := 0; i < cf.numHashes; i++ {
+		index := location(h1, h2, i, uint64(cf.size))
+		if cf.counters[index] < math.MaxUint8 {
+			cf.counters[index]--
+		}
+	}
+	if cf.count > 0 {
+		cf.count--
+	}
+	return true
+}
+
+func (cf *CountingBloomFilter) Count() uint {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return cf.count
+}
+
+func (cf *CountingBloomFilter) Reset() {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	clear(cf.counters)
+	cf.count = 0
+}
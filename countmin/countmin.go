@@ -0,0 +1,171 @@
+// Package countmin implements a count-min sketch, which estimates how often
+// each item occurs in a stream using a fixed amount of memory.
+package countmin
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+var (
+	ErrIncompatible = errors.New("countmin: sketches must have the same width and depth")
+	ErrInvalidData  = errors.New("countmin: invalid serialized sketch")
+)
+
+// Sketch keeps depth rows of width counters. Each item adds to one counter
+// per row, and its estimate is the smallest of them: never below the true
+// count, and above it by at most epsilon times the total count with
+// probability 1-delta, for the epsilon and delta given to NewWithEstimates.
+type Sketch struct {
+	mu       sync.RWMutex
+	counters []uint64
+	width    uint64
+	depth    int
+	count    uint64
+}
+
+// New returns a sketch of depth rows of width counters each.
+func New(width uint, depth int) *Sketch {
+	if width == 0 || depth < 1 {
+		panic("countmin: width and depth must be positive")
+	}
+	return &Sketch{
+		counters: make([]uint64, uint64(width)*uint64(depth)),
+		width:    uint64(width),
+		depth:    depth,
+	}
+}
+
+// NewWithEstimates sizes a sketch whose estimates overshoot by at most
+// epsilon times the total count, with probability 1-delta.
+func NewWithEstimates(epsilon, delta float64) *Sketch {
+	if epsilon <= 0 || delta <= 0 || delta >= 1 {
+		panic("countmin: epsilon must be positive and delta in (0, 1)")
+	}
+	width := math.Ceil(math.E / epsilon)
+	depth := math.Ceil(math.Log(1 / delta))
+	return New(uint(width), int(depth))
+}
+
+// cell returns the index of item's counter in row i.
+func (s *Sketch) cell(h1, h2 uint64, i int) uint64 {
+	return uint64(i)*s.width + (h1+uint64(i)*h2)%s.width
+}
+
+func (s *Sketch) Add(item []byte) {
+	s.AddN(item, 1)
+}
+
+// AddN adds n occurrences of item at once.
+func (s *Sketch) AddN(item []byte, n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h1, h2 := hashing.Sum128(item)
+	for i := 0; i < s.depth; i++ {
+		s.counters[s.cell(h1, h2, i)] += n
+	}
+	s.count += n
+}
+
+// Estimate returns the approximate number of times item was added.
+func (s *Sketch) Estimate(item []byte) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h1, h2 := hashing.Sum128(item)
+	est := uint64(math.MaxUint64)
+	for i := 0; i < s.depth; i++ {
+		est = min(est, s.counters[s.cell(h1, h2, i)])
+	}
+	return est
+}
+
+// Merge adds the counts of other into s, as if every item added to other
+// had been added to s as well.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.width != other.width || s.depth != other.depth {
+		return ErrIncompatible
+	}
+	if s == other {
+		other = other.clone()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+
+	for i, c := range other.counters {
+		s.counters[i] += c
+	}
+	s.count += other.count
+	return nil
+}
+
+func (s *Sketch) clone() *Sketch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &Sketch{
+		counters: append([]uint64(nil), s.counters...),
+		width:    s.width,
+		depth:    s.depth,
+		count:    s.count,
+	}
+}
+
+// Count is the total number of occurrences added.
+func (s *Sketch) Count() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.count
+}
+
+func (s *Sketch) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.counters)
+	s.count = 0
+}
+
+// Serialize uses the same framing as the Bloom filter: little-endian uint64
+// header fields (width, depth, count) followed by the counters, row by row.
+func (s *Sketch) Serialize() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	serialized := make([]byte, 24, 24+8*len(s.counters))
+	binary.LittleEndian.PutUint64(serialized[0:8], s.width)
+	binary.LittleEndian.PutUint64(serialized[8:16], uint64(s.depth))
+	binary.LittleEndian.PutUint64(serialized[16:24], s.count)
+	for _, c := range s.counters {
+		serialized = binary.LittleEndian.AppendUint64(serialized, c)
+	}
+	return serialized
+}
+
+func Deserialize(data []byte) (*Sketch, error) {
+	if len(data) < 24 {
+		return nil, ErrInvalidData
+	}
+	width := binary.LittleEndian.Uint64(data[0:8])
+	depth := binary.LittleEndian.Uint64(data[8:16])
+	if width == 0 || depth == 0 || depth > math.MaxInt32 ||
+		width > uint64(len(data)-24)/8/depth || uint64(len(data)-24) != 8*width*depth {
+		return nil, ErrInvalidData
+	}
+
+	s := &Sketch{
+		counters: make([]uint64, width*depth),
+		width:    width,
+		depth:    int(depth),
+		count:    binary.LittleEndian.Uint64(data[16:24]),
+	}
+	for i := range s.counters {
+		s.counters[i] = binary.LittleEndian.Uint64(data[24+8*i:])
+	}
+	return s, nil
+}
@@ -0,0 +1,143 @@
+package countmin
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// Estimates never fall below the true count, and overshoot by more than
+// epsilon times the total for at most a delta share of the items.
+func TestEstimateBounds(t *testing.T) {
+	const epsilon, delta = 0.001, 0.01
+	s := NewWithEstimates(epsilon, delta)
+	rng := rand.New(rand.NewPCG(1, 2))
+	counts := make(map[string]uint64)
+	for range 100000 {
+		// A skewed stream: low keys are far more common.
+		key := strconv.Itoa(int(rng.ExpFloat64() * 100))
+		s.Add([]byte(key))
+		counts[key]++
+	}
+	if s.Count() != 100000 {
+		t.Errorf("count %d, want 100000", s.Count())
+	}
+
+	bad := 0
+	for key, n := range counts {
+		est := s.Estimate([]byte(key))
+		if est < n {
+			t.Fatalf("%s: estimate %d under the true count %d", key, est, n)
+		}
+		if float64(est-n) > epsilon*100000 {
+			bad++
+		}
+	}
+	if float64(bad) > delta*float64(len(counts)) {
+		t.Errorf("%d of %d estimates overshoot by more than epsilon", bad, len(counts))
+	}
+	if est := s.Estimate([]byte("never added")); float64(est) > epsilon*100000 {
+		t.Errorf("absent key estimated at %d", est)
+	}
+}
+
+// Merging two sketches gives the sketch of both streams, and merging a
+// sketch into itself doubles it.
+func TestMerge(t *testing.T) {
+	a, b, both := New(256, 4), New(256, 4), New(256, 4)
+	for i := 0; i < 1000; i++ {
+		key := []byte(strconv.Itoa(i % 37))
+		if i%3 == 0 {
+			a.Add(key)
+			both.Add(key)
+		} else {
+			b.AddN(key, 2)
+			both.AddN(key, 2)
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(a.counters, both.counters) || a.Count() != both.Count() {
+		t.Error("merged sketch differs from one fed both streams")
+	}
+
+	if err := a.Merge(a); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.Estimate([]byte("0")), 2*both.Estimate([]byte("0")); got != want {
+		t.Errorf("self-merge: estimate %d, want %d", got, want)
+	}
+	if err := a.Merge(New(128, 4)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("different width: got %v, want ErrIncompatible", err)
+	}
+	if err := a.Merge(New(256, 3)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("different depth: got %v, want ErrIncompatible", err)
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	s := New(100, 3)
+	for i := 0; i < 500; i++ {
+		s.Add([]byte(strconv.Itoa(i % 50)))
+	}
+	blob := s.Serialize()
+	got, err := Deserialize(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.width != s.width || got.depth != s.depth || got.Count() != s.Count() || !slices.Equal(got.counters, s.counters) {
+		t.Error("round trip changed the sketch")
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"truncated": blob[:len(blob)-1],
+		"trailing":  append(slices.Clone(blob), 0),
+		"no width":  append(make([]byte, 8), blob[8:]...),
+		"no depth":  append(append(slices.Clone(blob[:8]), make([]byte, 8)...), blob[16:]...),
+	} {
+		if _, err := Deserialize(data); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: got %v, want ErrInvalidData", name, err)
+		}
+	}
+}
+
+// TopK ranks the heaviest keys first, including one that only becomes
+// heavy late in the stream.
+func TestTopK(t *testing.T) {
+	tk := NewTopK(3, 0.001, 0.01)
+	for i := 0; i < 1000; i++ {
+		tk.Add([]byte("noise" + strconv.Itoa(i)))
+		if i%2 == 0 {
+			tk.Add([]byte("b"))
+		}
+		if i%4 == 0 {
+			tk.Add([]byte("c"))
+		}
+	}
+	if tk.AddN([]byte("cold"), 1) {
+		t.Error("a key seen once entered a full top 3")
+	}
+	if !tk.AddN([]byte("a"), 1000) {
+		t.Error("the heaviest key did not enter the top 3")
+	}
+
+	var keys []string
+	for _, e := range tk.List() {
+		keys = append(keys, e.Key)
+		if e.Count != tk.Estimate([]byte(e.Key)) {
+			t.Errorf("%s listed at %d, estimated at %d", e.Key, e.Count, tk.Estimate([]byte(e.Key)))
+		}
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(keys, want) {
+		t.Errorf("top 3 = %v, want %v", keys, want)
+	}
+
+	tk.Reset()
+	if len(tk.List()) != 0 || tk.Estimate([]byte("a")) != 0 {
+		t.Error("Reset left entries behind")
+	}
+}
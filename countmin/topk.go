@@ -0,0 +1,133 @@
+package countmin
+
+import (
+	"container/heap"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Entry is a key and its estimated count.
+type Entry struct {
+	Key   string
+	Count uint64
+}
+
+// TopK tracks the k most frequent keys of a stream: a Sketch estimates every
+// key's count, and a min-heap keeps the k keys with the highest estimates
+// seen so far. A key's count only grows, so one that enters the top k late
+// is still ranked by its whole history.
+type TopK struct {
+	mu     sync.Mutex
+	sketch *Sketch
+	k      int
+	heap   entryHeap
+	index  map[string]int
+}
+
+// NewTopK returns a tracker for the k most frequent keys, backed by a sketch
+// sized by NewWithEstimates.
+func NewTopK(k int, epsilon, delta float64) *TopK {
+	if k < 1 {
+		panic("countmin: k must be positive")
+	}
+	t := &TopK{
+		sketch: NewWithEstimates(epsilon, delta),
+		k:      k,
+		index:  make(map[string]int, k),
+	}
+	t.heap.index = t.index
+	return t
+}
+
+func (t *TopK) Add(item []byte) {
+	t.AddN(item, 1)
+}
+
+// AddN adds n occurrences of item and reports whether it is now in the
+// top k.
+func (t *TopK) AddN(item []byte, n uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sketch.AddN(item, n)
+	count := t.sketch.Estimate(item)
+	if i, ok := t.index[string(item)]; ok {
+		t.heap.entries[i].Count = count
+		heap.Fix(&t.heap, i)
+		return true
+	}
+	if len(t.heap.entries) < t.k {
+		heap.Push(&t.heap, Entry{Key: string(item), Count: count})
+		return true
+	}
+	if count <= t.heap.entries[0].Count {
+		return false
+	}
+	delete(t.index, t.heap.entries[0].Key)
+	t.heap.entries[0] = Entry{Key: string(item), Count: count}
+	t.index[string(item)] = 0
+	heap.Fix(&t.heap, 0)
+	return true
+}
+
+// List returns the top keys, most frequent first.
+func (t *TopK) List() []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := slices.Clone(t.heap.entries)
+	slices.SortFunc(list, func(a, b Entry) int {
+		if a.Count != b.Count {
+			if a.Count > b.Count {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return list
+}
+
+// Estimate returns the approximate count of item, whether or not it is in
+// the top k.
+func (t *TopK) Estimate(item []byte) uint64 {
+	return t.sketch.Estimate(item)
+}
+
+func (t *TopK) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sketch.Reset()
+	t.heap.entries = t.heap.entries[:0]
+	clear(t.index)
+}
+
+// entryHeap is a min-heap by count that keeps index, key to position,
+// up to date as entries move.
+type entryHeap struct {
+	entries []Entry
+	index   map[string]int
+}
+
+func (h *entryHeap) Len() int           { return len(h.entries) }
+func (h *entryHeap) Less(i, j int) bool { return h.entries[i].Count < h.entries[j].Count }
+
+func (h *entryHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.index[h.entries[i].Key] = i
+	h.index[h.entries[j].Key] = j
+}
+
+func (h *entryHeap) Push(x any) {
+	e := x.(Entry)
+	h.index[e.Key] = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *entryHeap) Pop() any {
+	e := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	delete(h.index, e.Key)
+	return e
+}
@@ -0,0 +1,279 @@
+// Package cuckoo implements a cuckoo filter: a set-membership filter that
+// stores short fingerprints in a bucketed hash table and, unlike a Bloom
+// filter, supports deletion.
+package cuckoo
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+const (
+	bucketSize = 4
+	maxKicks   = 500
+)
+
+var ErrInvalidData = errors.New("cuckoo: invalid serialized filter")
+
+// A serialized filter starts with magic and a version byte and ends with a
+// CRC-32C of everything before it, like the Bloom filter's version 2 format.
+const (
+	version    = 1
+	headerSize = 5 + 24
+)
+
+var (
+	magic      = [4]byte{0x89, 'C', 'K', 'F'}
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type bucket [bucketSize]uint16
+
+type victim struct {
+	index       uint64
+	fingerprint uint16
+	used        bool
+}
+
+// Filter stores 16-bit fingerprints in buckets of four, giving a
+// false-positive rate of roughly 0.012% at up to ~95% occupancy.
+type Filter struct {
+	mu      sync.RWMutex
+	buckets []bucket
+	mask    uint64
+	count   uint
+	victim  victim
+}
+
+// New returns a filter able to hold about capacity items. The bucket count is
+// rounded up to a power of two.
+func New(capacity uint) *Filter {
+	numBuckets := (uint64(capacity) + bucketSize - 1) / bucketSize
+	if numBuckets < 1 {
+		numBuckets = 1
+	}
+	numBuckets = 1 << bits.Len64(numBuckets-1)
+
+	return &Filter{
+		buckets: make([]bucket, numBuckets),
+		mask:    numBuckets - 1,
+	}
+}
+
+func fingerprintAndIndex(item []byte) (uint16, uint64) {
+	h1, h2 := hashing.Sum128(item)
+	fp := uint16(h2)
+	if fp == 0 {
+		fp = 1
+	}
+	return fp, h1
+}
+
+func (f *Filter) altIndex(index uint64, fp uint16) uint64 {
+	return (index ^ hashing.Mix64(uint64(fp))) & f.mask
+}
+
+// Add inserts item and reports whether it fit. Once Add returns false the
+// filter is full and further inserts fail until something is deleted.
+func (f *Filter) Add(item []byte) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.victim.used {
+		return false
+	}
+
+	fp, h := fingerprintAndIndex(item)
+	i1 := h & f.mask
+	i2 := f.altIndex(i1, fp)
+	if f.buckets[i1].insert(fp) || f.buckets[i2].insert(fp) {
+		f.count++
+		return true
+	}
+
+	index := i1
+	if rand.IntN(2) == 1 {
+		index = i2
+	}
+	for n := 0; n < maxKicks; n++ {
+		slot := rand.IntN(bucketSize)
+		fp, f.buckets[index][slot] = f.buckets[index][slot], fp
+		index = f.altIndex(index, fp)
+		if f.buckets[index].insert(fp) {
+			f.count++
+			return true
+		}
+	}
+
+	// The item itself is stored; the fingerprint displaced last is parked
+	// until a Delete frees room for it.
+	f.victim = victim{index: index, fingerprint: fp, used: true}
+	f.count++
+	return true
+}
+
+func (f *Filter) Contains(item []byte) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	fp, h := fingerprintAndIndex(item)
+	i1 := h & f.mask
+	i2 := f.altIndex(i1, fp)
+	if f.buckets[i1].has(fp) || f.buckets[i2].has(fp) {
+		return true
+	}
+	return f.victim.used && f.victim.fingerprint == fp && (f.victim.index == i1 || f.victim.index == i2)
+}
+
+// Delete removes one copy of item and reports whether it was found. Deleting
+// an item that was never added may remove a colliding item instead.
+func (f *Filter) Delete(item []byte) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fp, h := fingerprintAndIndex(item)
+	i1 := h & f.mask
+	i2 := f.altIndex(i1, fp)
+
+	if !f.buckets[i1].remove(fp) && !f.buckets[i2].remove(fp) {
+		if !f.victim.used || f.victim.fingerprint != fp || (f.victim.index != i1 && f.victim.index != i2) {
+			return false
+		}
+		f.victim = victim{}
+		f.count--
+		return true
+	}
+	f.count--
+
+	if f.victim.used {
+		v := f.victim
+		f.victim = victim{}
+		alt := f.altIndex(v.index, v.fingerprint)
+		if !f.buckets[v.index].insert(v.fingerprint) && !f.buckets[alt].insert(v.fingerprint) {
+			f.victim = v
+		}
+	}
+	return true
+}
+
+func (f *Filter) Count() uint {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.count
+}
+
+func (f *Filter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.buckets)
+	f.count = 0
+	f.victim = victim{}
+}
+
+// Serialize writes the magic and version, then little-endian uint64 header
+// fields (bucket count, count, victim) and the table as little-endian uint16
+// slots, then the checksum.
+func (f *Filter) Serialize() []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	serialized := make([]byte, headerSize+len(f.buckets)*bucketSize*2, headerSize+len(f.buckets)*bucketSize*2+4)
+	copy(serialized, magic[:])
+	serialized[4] = version
+	binary.LittleEndian.PutUint64(serialized[5:13], uint64(len(f.buckets)))
+	binary.LittleEndian.PutUint64(serialized[13:21], uint64(f.count))
+	if f.victim.used {
+		binary.LittleEndian.PutUint64(serialized[21:29], f.victim.index<<16|uint64(f.victim.fingerprint))
+	}
+
+	offset := headerSize
+	for _, b := range f.buckets {
+		for _, fp := range b {
+			binary.LittleEndian.PutUint16(serialized[offset:], fp)
+			offset += 2
+		}
+	}
+
+	return binary.LittleEndian.AppendUint32(serialized, crc32.Checksum(serialized, castagnoli))
+}
+
+// Deserialize checks the framing, then data against the invariants Add and
+// Delete keep: a power-of-two bucket count, a victim inside the table, and a
+// count equal to the fingerprints stored.
+func Deserialize(data []byte) (*Filter, error) {
+	if len(data) < headerSize+4 || [4]byte(data[:4]) != magic || data[4] != version {
+		return nil, ErrInvalidData
+	}
+	body := data[:len(data)-4]
+	if crc32.Checksum(body, castagnoli) != binary.LittleEndian.Uint32(data[len(body):]) {
+		return nil, ErrInvalidData
+	}
+	numBuckets := binary.LittleEndian.Uint64(body[5:13])
+	if numBuckets == 0 || numBuckets&(numBuckets-1) != 0 ||
+		numBuckets > uint64(len(body)-headerSize)/(2*bucketSize) || uint64(len(body)-headerSize) != 2*bucketSize*numBuckets {
+		return nil, ErrInvalidData
+	}
+	f := &Filter{
+		buckets: make([]bucket, numBuckets),
+		mask:    numBuckets - 1,
+		count:   uint(binary.LittleEndian.Uint64(body[13:21])),
+	}
+	var stored uint
+	if v := binary.LittleEndian.Uint64(body[21:29]); v != 0 {
+		f.victim = victim{index: v >> 16, fingerprint: uint16(v), used: true}
+		if f.victim.index >= numBuckets || f.victim.fingerprint == 0 {
+			return nil, ErrInvalidData
+		}
+		stored++
+	}
+
+	offset := headerSize
+	for i := range f.buckets {
+		for j := range f.buckets[i] {
+			f.buckets[i][j] = binary.LittleEndian.Uint16(body[offset:])
+			if f.buckets[i][j] != 0 {
+				stored++
+			}
+			offset += 2
+		}
+	}
+	if f.count != stored {
+		return nil, ErrInvalidData
+	}
+	return f, nil
+}
+
+func (b *bucket) insert(fp uint16) bool {
+	for i, slot := range b {
+		if slot == 0 {
+			b[i] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) has(fp uint16) bool {
+	for _, slot := range b {
+		if slot == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) remove(fp uint16) bool {
+	for i, slot := range b {
+		if slot == fp {
+			b[i] = 0
+			return true
+		}
+	}
+	return false
+}
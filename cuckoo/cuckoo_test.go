@@ -0,0 +1,71 @@
+package cuckoo
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"strconv"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	f := New(1000)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	f.Delete([]byte("7"))
+
+	decoded, err := Deserialize(f.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Count() != f.Count() {
+		t.Errorf("Count = %d, want %d", decoded.Count(), f.Count())
+	}
+	for i := 0; i < 1000; i++ {
+		if i != 7 && !decoded.Contains([]byte(strconv.Itoa(i))) {
+			t.Fatalf("decoded filter lacks %d", i)
+		}
+	}
+}
+
+func TestDeserializeInvalid(t *testing.T) {
+	f := New(64)
+	f.Add([]byte("a"))
+	valid := f.Serialize()
+	// Mutations of the fields go through reframe, so that they reach the
+	// invariant checks rather than failing the checksum.
+	for _, tc := range []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"short", func(b []byte) []byte { return b[:headerSize+3] }},
+		{"bad magic", func(b []byte) []byte { b[1] = 'X'; return reframe(b) }},
+		{"unknown version", func(b []byte) []byte { b[4] = 2; return reframe(b) }},
+		{"bad checksum", func(b []byte) []byte { b[len(b)-1]++; return b }},
+		{"damaged slot", func(b []byte) []byte { b[headerSize] ^= 1; return b }},
+		{"truncated table", func(b []byte) []byte { return reframe(append(b[:len(b)-6:len(b)-6], 0, 0, 0, 0)) }},
+		{"no buckets", func(b []byte) []byte { binary.LittleEndian.PutUint64(b[5:], 0); return reframe(b[:headerSize+4]) }},
+		{"buckets not a power of two", func(b []byte) []byte {
+			binary.LittleEndian.PutUint64(b[5:], 3)
+			return reframe(b[:headerSize+3*8+4])
+		}},
+		{"wrapping bucket count", func(b []byte) []byte { binary.LittleEndian.PutUint64(b[5:], 1<<61); return reframe(b[:headerSize+4]) }},
+		{"count mismatch", func(b []byte) []byte { binary.LittleEndian.PutUint64(b[13:], 5); return reframe(b) }},
+		{"victim outside table", func(b []byte) []byte { binary.LittleEndian.PutUint64(b[21:], 1<<40|1); return reframe(b) }},
+		{"empty victim", func(b []byte) []byte { binary.LittleEndian.PutUint64(b[21:], 1<<16); return reframe(b) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := tc.mutate(append([]byte(nil), valid...))
+			if _, err := Deserialize(data); !errors.Is(err, ErrInvalidData) {
+				t.Errorf("got %v, want ErrInvalidData", err)
+			}
+		})
+	}
+}
+
+// reframe replaces the checksum at the end of b with that of the rest.
+func reframe(b []byte) []byte {
+	body := b[:len(b)-4]
+	return binary.LittleEndian.AppendUint32(body, crc32.Checksum(body, castagnoli))
+}
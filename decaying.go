@@ -0,0 +1,109 @@
+package bloomfilter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// DecayingFilter keeps a small counter per cell that every Add increments
+// and every decay step halves, so an item's Score is a frequency estimate
+// weighted towards recent adds, and items nobody adds any more fade out
+// entirely. Unlike StableBloomFilter, which evicts at random to bound its
+// fill, it forgets at a fixed rate over time, which suits "trending" or
+// "recently active" checks over an endless stream.
+type DecayingFilter struct {
+	mu        sync.Mutex
+	counters  []uint8
+	size      uint
+	numHashes int
+	halfLife  time.Duration
+	last      time.Time
+	now       func() time.Time
+}
+
+// NewDecaying returns a filter of size counters that halves them every
+// halfLife. With a zero halfLife the counters only decay when Decay is
+// called.
+func NewDecaying(size uint, numHashes int, halfLife time.Duration) *DecayingFilter {
+	df := &DecayingFilter{
+		counters:  make([]uint8, size),
+		size:      size,
+		numHashes: numHashes,
+		halfLife:  halfLife,
+		now:       time.Now,
+	}
+	df.last = df.now()
+	return df
+}
+
+// catchUp applies the half-lives that passed since the last decay. Eight of
+// them clear every counter, so an idle filter catches up in one pass.
+func (df *DecayingFilter) catchUp() {
+	if df.halfLife <= 0 {
+		return
+	}
+	steps := df.now().Sub(df.last) / df.halfLife
+	if steps <= 0 {
+		return
+	}
+	df.last = df.last.Add(steps * df.halfLife)
+	df.decay(uint(min(steps, 8)))
+}
+
+func (df *DecayingFilter) decay(steps uint) {
+	for i := range df.counters {
+		df.counters[i] >>= steps
+	}
+}
+
+// Decay halves every counter now, on top of the timed decay.
+func (df *DecayingFilter) Decay() {
+	df.mu.Lock()
+	defer df.mu.Unlock()
+	df.catchUp()
+	df.decay(1)
+}
+
+func (df *DecayingFilter) Add(item []byte) {
+	df.mu.Lock()
+	defer df.mu.Unlock()
+	df.catchUp()
+
+	h1, h2 := hash128(item)
+	for i := 0; i < df.numHashes; i++ {
+		index := location(h1, h2, i, uint64(df.size))
+		if df.counters[index] < math.MaxUint8 {
+			df.counters[index]++
+		}
+	}
+}
+
+// Contains reports whether item has been added recently enough that its
+// counters have not decayed to zero.
+func (df *DecayingFilter) Contains(item []byte) bool {
+	return df.Score(item) > 0
+}
+
+// Score is the smallest of item's counters: roughly how many times it was
+// added, halved for every half-life since. Other items sharing its counters
+// can only push it up.
+func (df *DecayingFilter) Score(item []byte) uint8 {
+	df.mu.Lock()
+	defer df.mu.Unlock()
+	df.catchUp()
+
+	score := uint8(math.MaxUint8)
+	h1, h2 := hash128(item)
+	for i := 0; i < df.numHashes; i++ {
+		score = min(score, df.counters[location(h1, h2, i, uint64(df.size))])
+	}
+	return score
+}
+
+func (df *DecayingFilter) Reset() {
+	df.mu.Lock()
+	defer df.mu.Unlock()
+	clear(df.counters)
+	df.last = df.now()
+}
@@ -0,0 +1,89 @@
+package bloomfilter
+
+import (
+	"testing"
+	"time"
+)
+
+func newFakeDecaying(size uint, numHashes int, halfLife time.Duration) (*DecayingFilter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1e9, 0)}
+	df := NewDecaying(size, numHashes, halfLife)
+	df.now = clock.now
+	df.last = clock.now()
+	return df, clock
+}
+
+func addN(df *DecayingFilter, item string, n int) {
+	for range n {
+		df.Add([]byte(item))
+	}
+}
+
+// A score counts adds and halves every half-life, with the part of a
+// half-life left over carried into the next.
+func TestDecayingScore(t *testing.T) {
+	df, clock := newFakeDecaying(1<<12, 4, time.Minute)
+	addN(df, "hot", 40)
+	addN(df, "warm", 5)
+	if s := df.Score([]byte("hot")); s != 40 {
+		t.Fatalf("score %d after 40 adds", s)
+	}
+
+	clock.advance(90 * time.Second)
+	if s := df.Score([]byte("hot")); s != 20 {
+		t.Errorf("score %d after one and a half half-lives, want 20", s)
+	}
+	clock.advance(30 * time.Second)
+	if s := df.Score([]byte("hot")); s != 10 {
+		t.Errorf("score %d after two half-lives, want 10", s)
+	}
+	if s := df.Score([]byte("warm")); s != 1 {
+		t.Errorf("score %d for 5 adds after two half-lives, want 1", s)
+	}
+
+	clock.advance(time.Minute)
+	if df.Contains([]byte("warm")) || !df.Contains([]byte("hot")) {
+		t.Error("want only the hot item after three half-lives")
+	}
+	if df.Contains([]byte("never added")) {
+		t.Error("an item never added matches")
+	}
+}
+
+// Counters saturate rather than wrap, and an idle filter catches up in one
+// step however long it sat.
+func TestDecayingSaturateIdle(t *testing.T) {
+	df, clock := newFakeDecaying(1<<12, 4, time.Minute)
+	addN(df, "a", 300)
+	if s := df.Score([]byte("a")); s != 255 {
+		t.Errorf("score %d after 300 adds, want 255", s)
+	}
+	clock.advance(1000 * time.Hour)
+	if df.Contains([]byte("a")) {
+		t.Error("item survived a long idle period")
+	}
+	df.Add([]byte("b"))
+	clock.advance(59 * time.Second)
+	if !df.Contains([]byte("b")) {
+		t.Error("item added after the idle period decayed early")
+	}
+}
+
+// With no half-life only Decay ages the counters.
+func TestDecayingManual(t *testing.T) {
+	df, clock := newFakeDecaying(1<<12, 4, 0)
+	addN(df, "a", 8)
+	clock.advance(1000 * time.Hour)
+	if s := df.Score([]byte("a")); s != 8 {
+		t.Errorf("score %d after idling without a half-life, want 8", s)
+	}
+	df.Decay()
+	df.Decay()
+	if s := df.Score([]byte("a")); s != 2 {
+		t.Errorf("score %d after two Decay calls, want 2", s)
+	}
+	df.Reset()
+	if df.Contains([]byte("a")) {
+		t.Error("Reset left the item")
+	}
+}
@@ -0,0 +1,89 @@
+package bloomfilter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// DecodeOptions bounds what decoding accepts, for filters that arrive from
+// untrusted sources: a crafted header can otherwise declare an arbitrarily
+// large filter. Zero limits mean no limit.
+type DecodeOptions struct {
+	// MaxBits caps the declared filter size. Within it decoding allocates
+	// the whole filter up front; without it, the bit array grows as the
+	// data arrives, so a short stream cannot make it allocate much.
+	MaxBits uint64
+	// MaxBytes caps the encoded size, compressed or not.
+	MaxBytes uint64
+	// Hasher is used for filters that name it, such as those built WithKey,
+	// whose hasher cannot be registered.
+	Hasher Hasher
+	// Progress reports the bytes read by Decode and LoadFile, out of the
+	// file size for LoadFile.
+	Progress Progress
+}
+
+// ErrTooLarge matches every LimitError under errors.Is.
+var ErrTooLarge = errors.New("bloomfilter: serialized filter exceeds decode limits")
+
+// LimitError reports a serialized filter larger than DecodeOptions allow.
+// It is returned before the filter is allocated.
+type LimitError struct {
+	Limit string // "MaxBits" or "MaxBytes"
+	Value uint64
+	Max   uint64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("bloomfilter: serialized filter needs %d, over %s of %d", e.Value, e.Limit, e.Max)
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrTooLarge
+}
+
+// Deserialize is the package-level Deserialize within the limits of o.
+func (o DecodeOptions) Deserialize(data []byte) (*BloomFilter, error) {
+	return unmarshal(data, o)
+}
+
+// Decode reads one filter from r within the limits of o, like
+// BloomFilter.ReadFrom, and returns it along with the bytes read.
+func (o DecodeOptions) Decode(r io.Reader) (*BloomFilter, int64, error) {
+	return o.decode(r, -1)
+}
+
+func (o DecodeOptions) decode(r io.Reader, total int64) (*BloomFilter, int64, error) {
+	r, t := o.decodeProgress(r, total)
+	bf, n, err := readFilter(r, o)
+	if err == nil {
+		t.finish()
+	}
+	return bf, n, err
+}
+
+// maxBits is the largest filter size whose word count can be computed
+// without overflow.
+const maxBits = math.MaxUint64 - 63
+
+// checkBits vets the size a serialized filter declares before it is
+// allocated. Every decoder goes through it, so it also rejects sizes no
+// filter can have: an empty filter would divide by zero on its first probe.
+func (o DecodeOptions) checkBits(bits uint64) error {
+	if bits == 0 || bits > maxBits {
+		return ErrInvalidFormat
+	}
+	if o.MaxBits > 0 && bits > o.MaxBits {
+		return &LimitError{Limit: "MaxBits", Value: bits, Max: o.MaxBits}
+	}
+	return nil
+}
+
+func (o DecodeOptions) checkBytes(n uint64) error {
+	if o.MaxBytes > 0 && n > o.MaxBytes {
+		return &LimitError{Limit: "MaxBytes", Value: n, Max: o.MaxBytes}
+	}
+	return nil
+}
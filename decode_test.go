@@ -0,0 +1,226 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/hriday-13th/bloom-filter/bloompb"
+)
+
+// v2Blob encodes h and words as Serialize would, with a valid checksum.
+func v2Blob(h header, words []uint64) []byte {
+	blob := h.appendTo(nil)
+	for _, w := range words {
+		blob = binary.LittleEndian.AppendUint64(blob, w)
+	}
+	return binary.LittleEndian.AppendUint32(blob, crc32.Checksum(blob, castagnoli))
+}
+
+// Sizes whose word count is zero, or wraps to zero when rounded up, must
+// not decode: the filter would have no bits to probe.
+func TestDecodeRejectsInvalidSize(t *testing.T) {
+	for _, size := range []uint64{0, math.MaxUint64, math.MaxUint64 - 10} {
+		h := header{hasher: Murmur3.Name(), numHashes: 3, size: size}
+		blob := v2Blob(h, nil)
+
+		if _, err := Deserialize(blob); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Deserialize, size %d: got %v, want ErrInvalidFormat", size, err)
+		}
+		if _, _, err := (DecodeOptions{}).Decode(bytes.NewReader(blob)); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Decode, size %d: got %v, want ErrInvalidFormat", size, err)
+		}
+		bf := New(64, 3)
+		if _, err := bf.ReadFrom(bytes.NewReader(blob)); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("ReadFrom, size %d: got %v, want ErrInvalidFormat", size, err)
+		}
+		m := &bloompb.BloomFilter{Hasher: h.hasher, NumHashes: h.numHashes, Size: size}
+		if _, err := FromProto(m); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("FromProto, size %d: got %v, want ErrInvalidFormat", size, err)
+		}
+	}
+}
+
+func TestWordsFor(t *testing.T) {
+	for _, tc := range []struct {
+		size uint64
+		want int
+	}{
+		{0, 0},
+		{1, 1},
+		{64, 1},
+		{65, 2},
+		{math.MaxUint64 - 63, 1<<58 - 1},
+		{math.MaxUint64, 1 << 58},
+	} {
+		if got := wordsFor(tc.size); got != tc.want {
+			t.Errorf("wordsFor(%d) = %d, want %d", tc.size, got, tc.want)
+		}
+	}
+}
+
+// v1Blob encodes a version 1 filter of size bits with n bytes of bits.
+func v1Blob(size uint64, n int) []byte {
+	blob := binary.LittleEndian.AppendUint64(nil, size)
+	blob = binary.LittleEndian.AppendUint64(blob, 0)
+	return append(blob, make([]byte, n)...)
+}
+
+// withChecksum replaces the trailer of blob with the checksum of the rest.
+func withChecksum(blob []byte) []byte {
+	body := blob[:len(blob)-4]
+	return binary.LittleEndian.AppendUint32(body, crc32.Checksum(body, castagnoli))
+}
+
+// Crafted and damaged input must fail with an error, on both the in-memory
+// and the streaming decoder, rather than panic or allocate what it claims.
+func TestDecodeHardening(t *testing.T) {
+	valid := New(1000, 3).Serialize()
+	h := header{hasher: Murmur3.Name(), numHashes: 3, size: 128}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		err  error // from Deserialize; the stream decoder must fail too
+	}{
+		{"empty", nil, ErrInvalidFormat},
+		{"short", []byte{1, 2, 3}, ErrInvalidFormat},
+		{"magic only", formatMagic[:], ErrChecksum},
+		{"bad checksum", append(valid[:len(valid)-4:len(valid)-4], 0, 0, 0, 0), ErrChecksum},
+		{"unknown version", withChecksum(append([]byte{0x89, 'B', 'L', 'M', 9, 0, 0}, make([]byte, 25)...)), ErrUnknownVersion},
+		{"hasher past end", withChecksum(append([]byte{0x89, 'B', 'L', 'M', 2, 0, 200}, make([]byte, 25)...)), ErrInvalidFormat},
+		{"seeds past end", withChecksum(append(valid[:7+len(Murmur3.Name())+20:7+len(Murmur3.Name())+20], 255, 0, 0, 0, 0)), ErrInvalidFormat},
+		{"unknown hasher", v2Blob(header{hasher: "nope", numHashes: 3, size: 128}, make([]uint64, 2)), ErrUnknownHasher},
+		{"short payload", v2Blob(h, make([]uint64, 1)), ErrInvalidFormat},
+		{"long payload", v2Blob(h, make([]uint64, 3)), ErrInvalidFormat},
+		{"bad compressed length", v2Blob(header{hasher: h.hasher, numHashes: 3, size: 128, flags: uint8(Zstd) << formatCompressionShift}, []uint64{100}), ErrInvalidFormat},
+		{"v1 size 0", v1Blob(0, 1), ErrInvalidFormat},
+		{"v1 short", v1Blob(64, 8), ErrInvalidFormat},
+		{"v1 header only", v1Blob(64, 0)[:12], ErrInvalidFormat},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Deserialize(tc.data); !errors.Is(err, tc.err) {
+				t.Errorf("Deserialize: got %v, want %v", err, tc.err)
+			}
+			if _, _, err := (DecodeOptions{}).Decode(bytes.NewReader(tc.data)); err == nil {
+				t.Error("Decode succeeded")
+			}
+		})
+	}
+
+	// The stream decoder stops where the filter ends and leaves excess bytes
+	// to the next reader; only Deserialize sees them.
+	if _, err := Deserialize(v1Blob(64, 10)); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Deserialize of a long v1 blob: got %v, want ErrInvalidFormat", err)
+	}
+}
+
+// Every proper prefix of a valid blob is rejected.
+func TestDecodeTruncated(t *testing.T) {
+	bf := New(1000, 3)
+	bf.AddString("a")
+	for _, blob := range [][]byte{bf.Serialize(), mustCompress(t, bf, Zstd)} {
+		for n := 0; n < len(blob); n++ {
+			if _, err := Deserialize(blob[:n]); err == nil {
+				t.Fatalf("Deserialize of %d of %d bytes succeeded", n, len(blob))
+			}
+			if _, _, err := (DecodeOptions{}).Decode(bytes.NewReader(blob[:n])); err == nil {
+				t.Fatalf("Decode of %d of %d bytes succeeded", n, len(blob))
+			}
+		}
+	}
+}
+
+// Without MaxBits, a header declaring a huge filter ahead of a few bytes
+// fails on the missing bytes, having allocated about what arrived rather
+// than the 8 GiB the header declares.
+func TestDecodeHugeHeader(t *testing.T) {
+	const size = 1 << 36
+	h := header{hasher: Murmur3.Name(), numHashes: 3, size: size}
+	compressed := mustCompress(t, New(1<<16, 3), Zstd)
+	binary.LittleEndian.PutUint64(compressed[7+len(h.hasher)+4:], size)
+	compressed = withChecksum(compressed)
+
+	for _, tc := range []struct {
+		name        string
+		data        []byte
+		deserialize bool // the payload length is checked up front otherwise
+	}{
+		{"plain", v2Blob(h, make([]uint64, 4)), false},
+		{"zstd", compressed, true},
+		{"v1", v1Blob(size, 100), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			allocated := allocatedBy(func() {
+				if _, _, err := (DecodeOptions{}).Decode(bytes.NewReader(tc.data)); err == nil {
+					t.Error("Decode succeeded")
+				}
+				if _, err := New(64, 3).ReadFrom(bytes.NewReader(tc.data)); err == nil {
+					t.Error("ReadFrom succeeded")
+				}
+				if _, err := Deserialize(tc.data); tc.deserialize && err == nil {
+					t.Error("Deserialize succeeded")
+				}
+			})
+			if allocated > 64<<20 {
+				t.Errorf("allocated %d bytes", allocated)
+			}
+		})
+	}
+}
+
+// allocatedBy returns the bytes allocated while f runs.
+func allocatedBy(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func mustCompress(t *testing.T, bf *BloomFilter, c Compression) []byte {
+	t.Helper()
+	blob, err := bf.SerializeCompressed(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blob
+}
+
+func TestDecodeLimits(t *testing.T) {
+	small := New(128, 3).Serialize()
+	large := New(1<<16, 3).Serialize()
+	for _, tc := range []struct {
+		name string
+		o    DecodeOptions
+		data []byte
+		err  error
+	}{
+		{"no limits", DecodeOptions{}, large, nil},
+		{"within MaxBits", DecodeOptions{MaxBits: 128}, small, nil},
+		{"over MaxBits", DecodeOptions{MaxBits: 1 << 15}, large, ErrTooLarge},
+		{"over MaxBytes", DecodeOptions{MaxBytes: 1 << 10}, large, ErrTooLarge},
+		{"v1 over MaxBits", DecodeOptions{MaxBits: 63}, v1Blob(64, 9), ErrTooLarge},
+		// An invalid size is malformed, whatever the limits.
+		{"size 0 under MaxBits", DecodeOptions{MaxBits: 1 << 20}, v2Blob(header{hasher: Murmur3.Name(), numHashes: 3}, nil), ErrInvalidFormat},
+		{"v1 size 0 under MaxBits", DecodeOptions{MaxBits: 1 << 20}, v1Blob(0, 1), ErrInvalidFormat},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.o.Deserialize(tc.data); !errors.Is(err, tc.err) {
+				t.Errorf("Deserialize: got %v, want %v", err, tc.err)
+			}
+			if _, _, err := tc.o.Decode(bytes.NewReader(tc.data)); !errors.Is(err, tc.err) {
+				t.Errorf("Decode: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+
+	var le *LimitError
+	if _, err := (DecodeOptions{MaxBits: 1 << 15}).Deserialize(large); !errors.As(err, &le) || le.Limit != "MaxBits" || le.Value != 1<<16 {
+		t.Errorf("got %v, want a MaxBits LimitError for %d bits", err, 1<<16)
+	}
+}
@@ -0,0 +1,173 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math/bits"
+	"sync/atomic"
+)
+
+var ErrNotTracking = errors.New("bloomfilter: filter is not tracking changes")
+
+// changeLog marks the words of the bit array written since the last Delta,
+// one bit per word.
+type changeLog struct {
+	dirty []uint64
+}
+
+// DeltaWord is one changed word of the bit array and its new value.
+type DeltaWord struct {
+	Offset uint64
+	Word   uint64
+}
+
+// Delta holds the words of a filter that changed between two calls to
+// Delta, for bringing a replica of the filter up to date without copying
+// the whole bit array. Size and NumHashes identify the filter's shape.
+type Delta struct {
+	Size      uint64
+	NumHashes int
+	Count     uint64
+	Words     []DeltaWord
+}
+
+// TrackChanges starts recording which words of the bit array change, at a
+// cost of one bit per word, for Delta to collect. To start a replica, call
+// TrackChanges, then copy the filter with WriteTo or a Snapshot, and from
+// then on ship Deltas; changes that raced with the copy are in the first
+// one. UnmarshalBinary and ReadFrom replace the bit array and stop tracking.
+// It does nothing for a filter with a Backend.
+func (bf *BloomFilter) TrackChanges() {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	if bf.backend == nil && bf.changes.Load() == nil {
+		bf.changes.Store(&changeLog{dirty: make([]uint64, (len(bf.bitset)+63)/64)})
+	}
+}
+
+// touched records that word i changed. It must follow the write, so that a
+// Delta that clears the mark also sees the new value.
+func (bf *BloomFilter) touched(i int) {
+	if cl := bf.changes.Load(); cl != nil {
+		atomic.OrUint64(&cl.dirty[i/64], 1<<(i%64))
+	}
+}
+
+// Delta returns the words changed since the previous Delta, or since
+// TrackChanges for the first. Words keep changing while it runs; any it
+// misses are in the next one.
+func (bf *BloomFilter) Delta() (*Delta, error) {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	cl := bf.changes.Load()
+	if cl == nil {
+		return nil, ErrNotTracking
+	}
+	d := &Delta{Size: bf.size, NumHashes: bf.numHashes}
+	for j := range cl.dirty {
+		if atomic.LoadUint64(&cl.dirty[j]) == 0 {
+			continue
+		}
+		for w := atomic.SwapUint64(&cl.dirty[j], 0); w != 0; w &= w - 1 {
+			i := j*64 + bits.TrailingZeros64(w)
+			d.Words = append(d.Words, DeltaWord{Offset: uint64(i), Word: bf.loadWord(i)})
+		}
+	}
+	d.Count = bf.count.Load()
+	return d, nil
+}
+
+// ApplyDelta stores the words of d, taken from a filter of the same shape,
+// into bf. Applied in order on top of a copy, Deltas keep it identical to
+// the original. A filter with a Backend cannot apply them.
+func (bf *BloomFilter) ApplyDelta(d *Delta) error {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	if bf.backend != nil || d.Size != bf.size || d.NumHashes != bf.numHashes {
+		return ErrIncompatible
+	}
+	for _, dw := range d.Words {
+		if dw.Offset >= uint64(len(bf.bitset)) {
+			return ErrInvalidFormat
+		}
+	}
+	for _, dw := range d.Words {
+		i := int(dw.Offset)
+		bf.preserve(i)
+		atomic.StoreUint64(&bf.bitset[i], dw.Word&bf.wordMask(i))
+		bf.touched(i)
+	}
+	bf.count.Store(d.Count)
+	return nil
+}
+
+// wordMask covers the bits of word i that lie inside the filter.
+func (bf *BloomFilter) wordMask(i int) uint64 {
+	if i == len(bf.bitset)-1 {
+		return lastWordMask(bf.size)
+	}
+	return ^uint64(0)
+}
+
+// MarshalBinary encodes the shape and count as uvarints, then each word as
+// the uvarint gap from the previous offset and the little-endian word,
+// followed by a CRC-32C of everything before it.
+func (d *Delta) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 4*binary.MaxVarintLen64+len(d.Words)*10+4)
+	buf = binary.AppendUvarint(buf, d.Size)
+	buf = binary.AppendUvarint(buf, uint64(d.NumHashes))
+	buf = binary.AppendUvarint(buf, d.Count)
+	buf = binary.AppendUvarint(buf, uint64(len(d.Words)))
+	var prev uint64
+	for i, dw := range d.Words {
+		if i > 0 && dw.Offset <= prev {
+			return nil, ErrInvalidFormat
+		}
+		buf = binary.AppendUvarint(buf, dw.Offset-prev)
+		buf = binary.LittleEndian.AppendUint64(buf, dw.Word)
+		prev = dw.Offset
+	}
+	return binary.LittleEndian.AppendUint32(buf, crc32.Checksum(buf, castagnoli)), nil
+}
+
+func (d *Delta) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return ErrInvalidFormat
+	}
+	body := data[:len(data)-4]
+	if crc32.Checksum(body, castagnoli) != binary.LittleEndian.Uint32(data[len(data)-4:]) {
+		return ErrChecksum
+	}
+
+	var fields [4]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(body)
+		if n <= 0 {
+			return ErrInvalidFormat
+		}
+		fields[i], body = v, body[n:]
+	}
+	if fields[1] > MaxHashes || fields[3] > uint64(len(body))/9 {
+		return ErrInvalidFormat
+	}
+
+	words := make([]DeltaWord, fields[3])
+	var offset uint64
+	for i := range words {
+		gap, n := binary.Uvarint(body)
+		if n <= 0 || len(body) < n+8 || (i > 0 && gap == 0) {
+			return ErrInvalidFormat
+		}
+		offset += gap
+		words[i] = DeltaWord{Offset: offset, Word: binary.LittleEndian.Uint64(body[n:])}
+		body = body[n+8:]
+	}
+	if len(body) != 0 {
+		return ErrInvalidFormat
+	}
+	*d = Delta{Size: fields[0], NumHashes: int(fields[1]), Count: fields[2], Words: words}
+	return nil
+}
@@ -0,0 +1,125 @@
+package bloomfilter
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// ApproxCardinality estimates the number of distinct items in the filter
+// from the fraction of bits set. Unlike Count it ignores duplicate adds and
+// stays meaningful after Union and UnionWith. It is +Inf once every bit is
+// set, and 0 if the bits cannot be read; see Err.
+func (bf *BloomFilter) ApproxCardinality() float64 {
+	return estimateCardinality(bf.bitsSet(), bf.size, bf.numHashes)
+}
+
+// Capacity returns how many distinct items the filter can hold before its
+// expected false-positive rate exceeds targetFP.
+func (bf *BloomFilter) Capacity(targetFP float64) uint {
+	if targetFP <= 0 || targetFP >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
+	}
+	k := float64(bf.numHashes)
+	return uint(-float64(bf.size) / k * math.Log1p(-math.Pow(targetFP, 1/k)))
+}
+
+// Remaining returns how many more distinct items fit before the expected
+// false-positive rate exceeds targetFP, taking the current contents from
+// ApproxCardinality rather than Count, so duplicates and merged filters are
+// accounted for.
+func (bf *BloomFilter) Remaining(targetFP float64) uint {
+	capacity := float64(bf.Capacity(targetFP))
+	n := bf.ApproxCardinality()
+	if n >= capacity {
+		return 0
+	}
+	return uint(capacity - n)
+}
+
+// BitsSet returns the number of set bits, or 0 if the bits cannot be read;
+// see Err.
+func (bf *BloomFilter) BitsSet() uint {
+	return uint(bf.bitsSet())
+}
+
+func (bf *BloomFilter) bitsSet() uint64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	words, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
+		return 0
+	}
+	return countBits(words)
+}
+
+// FillRatio returns the fraction of bits set. A filter at its designed
+// capacity is about half full.
+func (bf *BloomFilter) FillRatio() float64 {
+	if bf.size == 0 {
+		return 0
+	}
+	return float64(bf.bitsSet()) / float64(bf.size)
+}
+
+// EstimateJaccard estimates |A∩B| / |A∪B| for the sets added to two filters
+// of the same shape, from the number of bits set in each filter and in their
+// union. It returns NaN if the shapes differ or the bits cannot be read, and
+// 1 for two empty filters. The estimate degrades as the filters saturate.
+func (bf *BloomFilter) EstimateJaccard(other *BloomFilter) float64 {
+	if !bf.compatible(other) {
+		return math.NaN()
+	}
+
+	unlock := rlockPair(&bf.mu, &other.mu)
+	defer unlock()
+
+	a, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
+		return math.NaN()
+	}
+	b, err := other.words()
+	if err != nil {
+		other.setErr(err)
+		return math.NaN()
+	}
+
+	var setA, setB, setUnion uint64
+	for i := range a {
+		wa, wb := atomic.LoadUint64(&a[i]), atomic.LoadUint64(&b[i])
+		setA += uint64(bits.OnesCount64(wa))
+		setB += uint64(bits.OnesCount64(wb))
+		setUnion += uint64(bits.OnesCount64(wa | wb))
+	}
+	if setUnion == 0 {
+		return 1
+	}
+
+	nA := estimateCardinality(setA, bf.size, bf.numHashes)
+	nB := estimateCardinality(setB, bf.size, bf.numHashes)
+	nUnion := estimateCardinality(setUnion, bf.size, bf.numHashes)
+	if math.IsInf(nUnion, 1) {
+		return math.NaN()
+	}
+	return max(0, min(1, (nA+nB-nUnion)/nUnion))
+}
+
+// estimateCardinality is the Swamidass–Baldi estimate of the number of
+// distinct items that set bitsSet of size bits, -m/k ln(1 - X/m). It holds
+// for partitioned filters too, where each item sets one bit in each m/k-bit
+// slice. A full filter gives +Inf.
+func estimateCardinality(bitsSet uint64, size uint64, numHashes int) float64 {
+	m := float64(size)
+	return -m / float64(numHashes) * math.Log1p(-float64(bitsSet)/m)
+}
+
+func countBits(words []uint64) uint64 {
+	var n uint64
+	for i := range words {
+		n += uint64(bits.OnesCount64(atomic.LoadUint64(&words[i])))
+	}
+	return n
+}
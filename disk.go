@@ -0,0 +1,88 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// DiskFilter answers membership queries against a filter file written by
+// CreateMmap without loading it: each Contains reads only the words its
+// probes land in, at most one small read per probe. It is read-only and
+// safe for concurrent use if the underlying ReaderAt is.
+type DiskFilter struct {
+	r           io.ReaderAt
+	closer      io.Closer
+	size        uint64
+	count       uint64
+	numHashes   int
+	partitioned bool
+	hasher      Hasher
+}
+
+// NewDiskFilter reads the header of a filter file from r.
+func NewDiskFilter(r io.ReaderAt) (*DiskFilter, error) {
+	header := make([]byte, mmapHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if err == io.EOF {
+			return nil, ErrInvalidMmapFile
+		}
+		return nil, err
+	}
+
+	flags := binary.LittleEndian.Uint64(header[24:32])
+	df := &DiskFilter{
+		r:           r,
+		size:        binary.LittleEndian.Uint64(header[0:8]),
+		count:       binary.LittleEndian.Uint64(header[8:16]),
+		numHashes:   int(binary.LittleEndian.Uint64(header[16:24])),
+		partitioned: flags&mmapFlagPartitioned != 0,
+		hasher:      mmapHasher(flags),
+	}
+	if err := checkMmapHeader(df.size, df.numHashes, df.hasher); err != nil {
+		return nil, err
+	}
+	return df, nil
+}
+
+// OpenDiskFilter opens the filter file at path. Close releases it.
+func OpenDiskFilter(path string) (*DiskFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	df, err := NewDiskFilter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	df.closer = f
+	return df, nil
+}
+
+func (df *DiskFilter) Contains(item []byte) (bool, error) {
+	var word [8]byte
+	h1, h2 := df.hasher.Hash128(item)
+	for i := 0; i < df.numHashes; i++ {
+		index := probe(h1, h2, i, df.size, df.numHashes, df.partitioned)
+		if _, err := df.r.ReadAt(word[:], mmapHeaderSize+8*int64(index/64)); err != nil {
+			return false, err
+		}
+		// The bit array is stored as in memory, in native byte order.
+		if binary.NativeEndian.Uint64(word[:])&(1<<(index%64)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (df *DiskFilter) Count() uint {
+	return uint(df.count)
+}
+
+func (df *DiskFilter) Close() error {
+	if df.closer == nil {
+		return nil
+	}
+	return df.closer.Close()
+}
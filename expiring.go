@@ -0,0 +1,131 @@
+package bloomfilter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// expiringTicks is how finely an ExpiringFilter divides its TTL.
+const expiringTicks = 256
+
+// expiringRebase is the tick past which Add moves the filter's epoch up to
+// the present, so that expiry ticks keep fitting in a cell. Expiries more
+// than about 2^31 ticks out saturate at the largest cell value.
+const expiringRebase = math.MaxUint32 / 2
+
+// ExpiringFilter forgets each item once its TTL has passed. Every cell holds
+// the latest expiry time of the items hashed to it, so an item matches until
+// the earliest of its cells expires: its own TTL, or later if other items
+// share all its cells. Expiry is tracked to 1/256 of the TTL.
+type ExpiringFilter struct {
+	mu        sync.RWMutex
+	cells     []uint32
+	size      uint
+	numHashes int
+	ttl       time.Duration
+	tick      time.Duration
+	start     time.Time
+	now       func() time.Time
+}
+
+// NewExpiring returns a filter of size cells, four bytes each, whose items
+// expire ttl after they were last added.
+func NewExpiring(size uint, numHashes int, ttl time.Duration) *ExpiringFilter {
+	if size == 0 || numHashes < 1 {
+		panic("bloomfilter: expiring filter needs at least one cell and one hash")
+	}
+	if ttl <= 0 {
+		panic("bloomfilter: TTL must be positive")
+	}
+	ef := &ExpiringFilter{
+		cells:     make([]uint32, size),
+		size:      size,
+		numHashes: numHashes,
+		ttl:       ttl,
+		tick:      max(ttl/expiringTicks, 1),
+		now:       time.Now,
+	}
+	ef.start = ef.now()
+	return ef
+}
+
+// ticks is the number of ticks since the filter's epoch, at first its
+// creation. Cells hold expiry ticks, so zero never matches.
+func (ef *ExpiringFilter) ticks() uint64 {
+	return uint64(max(ef.now().Sub(ef.start), 0) / ef.tick)
+}
+
+// rebase moves the epoch forward by shift ticks, shifting every expiry with
+// it. Cells that expired before the new epoch become zero.
+func (ef *ExpiringFilter) rebase(shift uint64) {
+	for i, expiry := range ef.cells {
+		ef.cells[i] = uint32(uint64(expiry) - min(uint64(expiry), shift))
+	}
+	ef.start = ef.start.Add(time.Duration(shift) * ef.tick)
+}
+
+func (ef *ExpiringFilter) Add(item []byte) {
+	ef.AddWithTTL(item, ef.ttl)
+}
+
+// AddWithTTL adds item with its own TTL instead of the filter's. It cannot
+// cut short the TTL of an earlier Add.
+func (ef *ExpiringFilter) AddWithTTL(item []byte, ttl time.Duration) {
+	ef.mu.Lock()
+	defer ef.mu.Unlock()
+
+	now := ef.ticks()
+	if now > expiringRebase {
+		ef.rebase(now)
+		now = 0
+	}
+	var n uint64
+	if ttl > 0 {
+		n = uint64(ttl / ef.tick)
+		if ttl%ef.tick != 0 {
+			n++
+		}
+	}
+	expiry := uint32(min(now+n, math.MaxUint32))
+	h1, h2 := hash128(item)
+	for i := 0; i < ef.numHashes; i++ {
+		index := location(h1, h2, i, uint64(ef.size))
+		ef.cells[index] = max(ef.cells[index], expiry)
+	}
+}
+
+func (ef *ExpiringFilter) Contains(item []byte) bool {
+	ef.mu.RLock()
+	defer ef.mu.RUnlock()
+
+	now := ef.ticks()
+	h1, h2 := hash128(item)
+	for i := 0; i < ef.numHashes; i++ {
+		if uint64(ef.cells[location(h1, h2, i, uint64(ef.size))]) <= now {
+			return false
+		}
+	}
+	return true
+}
+
+// Live reports how many cells still hold an unexpired item.
+func (ef *ExpiringFilter) Live() uint {
+	ef.mu.RLock()
+	defer ef.mu.RUnlock()
+
+	now := ef.ticks()
+	var n uint
+	for _, expiry := range ef.cells {
+		if uint64(expiry) > now {
+			n++
+		}
+	}
+	return n
+}
+
+func (ef *ExpiringFilter) Reset() {
+	ef.mu.Lock()
+	defer ef.mu.Unlock()
+	clear(ef.cells)
+}
@@ -0,0 +1,132 @@
+package bloomfilter
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for the filters that read the time through
+// a now field.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newFakeExpiring(size uint, numHashes int, ttl time.Duration) (*ExpiringFilter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1e9, 0)}
+	ef := NewExpiring(size, numHashes, ttl)
+	ef.now = clock.now
+	ef.start = clock.now()
+	return ef, clock
+}
+
+// An item matches for its TTL, give or take a tick, and not after.
+func TestExpiringTTL(t *testing.T) {
+	ef, clock := newFakeExpiring(1<<12, 4, time.Minute)
+	tick := time.Minute / expiringTicks
+	ef.Add([]byte("a"))
+	clock.advance(time.Minute - tick)
+	if !ef.Contains([]byte("a")) {
+		t.Error("item expired before its TTL")
+	}
+	ef.Add([]byte("b"))
+	clock.advance(2 * tick)
+	if ef.Contains([]byte("a")) {
+		t.Error("item outlived its TTL")
+	}
+	if !ef.Contains([]byte("b")) {
+		t.Error("item added later expired with the first")
+	}
+}
+
+func TestExpiringAddWithTTL(t *testing.T) {
+	ef, clock := newFakeExpiring(1<<12, 4, time.Minute)
+	ef.AddWithTTL([]byte("long"), time.Hour)
+	ef.AddWithTTL([]byte("short"), time.Second)
+	// A shorter TTL does not cut short a longer one.
+	ef.AddWithTTL([]byte("long"), time.Second)
+	// Nor does a TTL past what a cell holds wrap around to a short one.
+	ef.AddWithTTL([]byte("forever"), math.MaxInt64)
+
+	clock.advance(30 * time.Second)
+	if ef.Contains([]byte("short")) {
+		t.Error("short TTL outlived")
+	}
+	if !ef.Contains([]byte("long")) {
+		t.Error("long TTL cut short")
+	}
+	clock.advance(2 * time.Hour)
+	if ef.Contains([]byte("long")) {
+		t.Error("long TTL outlived")
+	}
+	if !ef.Contains([]byte("forever")) {
+		t.Error("huge TTL expired")
+	}
+}
+
+// The tick counter runs past 2^32 ticks; items added after that still
+// expire on time, and those from before do not come back.
+func TestExpiringTickRollover(t *testing.T) {
+	// A tick of one nanosecond rolls over a uint32 in about four seconds.
+	// Each idle period ends just short of a multiple of 2^32 ticks, where
+	// a fresh expiry would wrap.
+	ef, clock := newFakeExpiring(1<<12, 4, expiringTicks)
+	ef.Add([]byte("old"))
+	for i := 0; i < 3; i++ {
+		clock.advance(1<<32 - 100)
+		if ef.Contains([]byte("old")) {
+			t.Fatalf("expired item matches after %d idle periods", i+1)
+		}
+		item := strconv.Itoa(i)
+		ef.Add([]byte(item))
+		if !ef.Contains([]byte(item)) || ef.Contains([]byte("old")) {
+			t.Fatalf("period %d: want only the new item", i)
+		}
+		clock.advance(expiringTicks)
+		if ef.Contains([]byte(item)) {
+			t.Fatalf("period %d: item outlived its TTL", i)
+		}
+	}
+}
+
+func TestExpiringLive(t *testing.T) {
+	ef, clock := newFakeExpiring(1<<12, 4, time.Minute)
+	if ef.Live() != 0 {
+		t.Fatalf("new filter has %d live cells", ef.Live())
+	}
+	for i := 0; i < 10; i++ {
+		ef.Add([]byte(strconv.Itoa(i)))
+	}
+	if n := ef.Live(); n == 0 || n > 40 {
+		t.Errorf("%d live cells after 10 items of 4 hashes", n)
+	}
+	clock.advance(2 * time.Minute)
+	if n := ef.Live(); n != 0 {
+		t.Errorf("%d live cells after the TTL", n)
+	}
+}
+
+func TestNewExpiringInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		size      uint
+		numHashes int
+		ttl       time.Duration
+	}{
+		{"no cells", 0, 4, time.Minute},
+		{"no hashes", 64, 0, time.Minute},
+		{"no TTL", 64, 4, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("NewExpiring did not panic")
+				}
+			}()
+			NewExpiring(tc.size, tc.numHashes, tc.ttl)
+		})
+	}
+}
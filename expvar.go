@@ -0,0 +1,24 @@
+package bloomfilter
+
+import "expvar"
+
+// Publish registers the filter's statistics as the expvar variable name, so
+// they appear under /debug/vars: its size in bits, hash count, count, query
+// counters, fill ratio and estimated false-positive rate. They are computed
+// on each read, which scans the bit array for the fill ratio. Like
+// expvar.Publish it panics if name is already taken.
+func (bf *BloomFilter) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		s := bf.Stats()
+		return map[string]any{
+			"size":              s.Size,
+			"num_hashes":        s.NumHashes,
+			"count":             s.Count,
+			"queries":           s.Queries,
+			"positives":         s.Positives,
+			"negatives":         s.Negatives,
+			"fill_ratio":        s.FillRatio,
+			"estimated_fp_rate": s.EstimatedFPRate,
+		}
+	}))
+}
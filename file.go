@@ -0,0 +1,81 @@
+package bloomfilter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SaveFile writes the filter to path in the Serialize format. It writes a
+// temporary file alongside and renames it over path once synced, so path
+// always holds a complete filter; the format's checksum catches later
+// corruption.
+func (bf *BloomFilter) SaveFile(path string) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := bf.WriteTo(w)
+		return err
+	})
+}
+
+// SaveFileCompressed is SaveFile in the SerializeCompressed format.
+func (bf *BloomFilter) SaveFileCompressed(path string, c Compression) error {
+	data, err := bf.SerializeCompressed(c)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// LoadFile reads a filter written by SaveFile or SaveFileCompressed.
+func LoadFile(path string) (*BloomFilter, error) {
+	return DecodeOptions{}.LoadFile(path)
+}
+
+// LoadFile is the package-level LoadFile within the limits of o.
+func (o DecodeOptions) LoadFile(path string) (*BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	bf, _, err := o.decode(f, fileSize(f))
+	return bf, err
+}
+
+// writeFileAtomic writes path through write, by way of a temporary file in
+// the same directory that is synced and renamed over path, so readers see
+// either the old contents or the new, never a partial file.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// Sync the directory too, or the rename itself may not survive a crash.
+	if d, err := os.Open(filepath.Dir(path)); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
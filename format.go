@@ -0,0 +1,248 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// Serialized filters since version 2 start with formatMagic, chosen so that
+// it does not look like the leading size field of a version 1 blob, and end
+// with a CRC-32C of everything before it.
+//
+//	magic     [4]byte
+//	version   uint8
+//	flags     uint8
+//	hasherLen uint8
+//	hasher    [hasherLen]byte
+//	numHashes uint32
+//	size      uint64
+//	count     uint64
+//	numSeeds  uint8
+//	seeds     [numSeeds]uint64
+//	words     [ceil(size/64)]uint64, or a length and compressed words
+//	checksum  uint32
+//
+// All integers are little-endian. The hash count, hasher and seeds are
+// everything Contains depends on besides the bits, so a decoded filter
+// answers exactly as the original did. Version 1 blobs are just size, count
+// and the bit array, with no hash count: every hash function of those
+// filters was the same FNV-1 instance, so they set one bit per item, at its
+// hash mod size, and load as single-hash filters with legacyHasher.
+const (
+	formatVersion = 2
+
+	formatFlagPartitioned = 1 << 0
+)
+
+var formatMagic = [4]byte{0x89, 'B', 'L', 'M'}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	ErrInvalidFormat  = errors.New("bloomfilter: invalid serialized filter")
+	ErrUnknownVersion = errors.New("bloomfilter: unsupported serialization version")
+	ErrUnknownHasher  = errors.New("bloomfilter: serialized filter uses an unknown hasher")
+	ErrChecksum       = errors.New("bloomfilter: checksum mismatch")
+)
+
+type header struct {
+	flags     uint8
+	hasher    string
+	numHashes uint32
+	size      uint64
+	count     uint64
+	seeds     []uint64
+}
+
+func (bf *BloomFilter) header() header {
+	h := header{
+		hasher:    bf.hasher.Name(),
+		numHashes: uint32(bf.numHashes),
+		size:      bf.size,
+		count:     bf.count.Load(),
+	}
+	if bf.seed != 0 {
+		h.seeds = []uint64{bf.seed}
+	}
+	if bf.partitioned {
+		h.flags |= formatFlagPartitioned
+	}
+	return h
+}
+
+func (h *header) encodedLen() int {
+	return 4 + 1 + 1 + 1 + len(h.hasher) + 4 + 8 + 8 + 1 + 8*len(h.seeds)
+}
+
+func (h *header) appendTo(dst []byte) []byte {
+	dst = append(dst, formatMagic[:]...)
+	dst = append(dst, formatVersion, h.flags, byte(len(h.hasher)))
+	dst = append(dst, h.hasher...)
+	dst = binary.LittleEndian.AppendUint32(dst, h.numHashes)
+	dst = binary.LittleEndian.AppendUint64(dst, h.size)
+	dst = binary.LittleEndian.AppendUint64(dst, h.count)
+	dst = append(dst, byte(len(h.seeds)))
+	for _, seed := range h.seeds {
+		dst = binary.LittleEndian.AppendUint64(dst, seed)
+	}
+	return dst
+}
+
+func hasMagic(data []byte) bool {
+	return len(data) >= len(formatMagic) && [4]byte(data[:4]) == formatMagic
+}
+
+// parseHeader decodes a version 2 header and returns it along with the
+// number of bytes it occupied.
+func parseHeader(data []byte) (header, int, error) {
+	var h header
+	if !hasMagic(data) || len(data) < 7 {
+		return h, 0, ErrInvalidFormat
+	}
+	if data[4] != formatVersion {
+		return h, 0, ErrUnknownVersion
+	}
+	h.flags = data[5]
+	n := 7 + int(data[6])
+	if len(data) < n+4+8+8+1 {
+		return h, 0, ErrInvalidFormat
+	}
+	h.hasher = string(data[7:n])
+	h.numHashes = binary.LittleEndian.Uint32(data[n:])
+	h.size = binary.LittleEndian.Uint64(data[n+4:])
+	h.count = binary.LittleEndian.Uint64(data[n+12:])
+	numSeeds := int(data[n+20])
+	n += 21
+	if len(data) < n+8*numSeeds {
+		return h, 0, ErrInvalidFormat
+	}
+	for i := 0; i < numSeeds; i++ {
+		h.seeds = append(h.seeds, binary.LittleEndian.Uint64(data[n:]))
+		n += 8
+	}
+	return h, n, nil
+}
+
+// newFilter returns a filter of the shape h describes with no words yet,
+// preferring o.Hasher when it has the recorded name. Its bit array has room
+// for every word only when o.MaxBits vetted the size; otherwise readWords
+// grows it as the words arrive.
+func (h *header) newFilter(o DecodeOptions) (*BloomFilter, error) {
+	if err := o.checkBits(h.size); err != nil {
+		return nil, err
+	}
+	hasher := o.Hasher
+	if hasher == nil || hasher.Name() != h.hasher {
+		var err error
+		if hasher, err = lookupHasher(h.hasher); err != nil {
+			return nil, err
+		}
+	}
+	partitioned := h.flags&formatFlagPartitioned != 0
+	if len(h.seeds) > 1 || h.numHashes < 1 || h.numHashes > MaxHashes ||
+		partitioned && h.size < uint64(h.numHashes) {
+		return nil, ErrInvalidFormat
+	}
+
+	words := []uint64{}
+	if o.MaxBits > 0 {
+		words = make([]uint64, 0, wordsFor(h.size))
+	}
+	bf := New64(h.size, int(h.numHashes), WithHasher(hasher), withBitset(words))
+	bf.partitioned = partitioned
+	bf.count.Store(h.count)
+	if len(h.seeds) == 1 {
+		bf.seed = h.seeds[0]
+	}
+	return bf, nil
+}
+
+func deserializeV2(data []byte, o DecodeOptions) (*BloomFilter, error) {
+	if len(data) < 4 {
+		return nil, ErrInvalidFormat
+	}
+	body := data[:len(data)-4]
+	if crc32.Checksum(body, castagnoli) != binary.LittleEndian.Uint32(data[len(data)-4:]) {
+		return nil, ErrChecksum
+	}
+
+	h, n, err := parseHeader(body)
+	if err != nil {
+		return nil, err
+	}
+	c := Compression(h.flags >> formatCompressionShift)
+	payload := body[n:]
+	if c == NoCompression {
+		if uint64(len(payload)) != 8*uint64(wordsFor(h.size)) {
+			return nil, ErrInvalidFormat
+		}
+	} else if len(payload) < 8 || binary.LittleEndian.Uint64(payload) != uint64(len(payload)-8) {
+		return nil, ErrInvalidFormat
+	}
+
+	bf, err := h.newFilter(o)
+	if err != nil {
+		return nil, err
+	}
+	if c == NoCompression {
+		// The length check above vouches for the size.
+		bf.bitset = make([]uint64, wordsFor(h.size))
+		for i := range bf.bitset {
+			bf.bitset[i] = binary.LittleEndian.Uint64(payload[8*i:])
+		}
+	} else {
+		r, err := decompressor(bytes.NewReader(payload[8:]), c)
+		if err != nil {
+			return nil, err
+		}
+		if bf.bitset, err = readWords(r, bf.bitset, wordsFor(h.size)); err != nil {
+			return nil, err
+		}
+	}
+	if len(bf.bitset) > 0 {
+		bf.bitset[len(bf.bitset)-1] &= lastWordMask(bf.size)
+	}
+	return bf, nil
+}
+
+func unmarshal(data []byte, o DecodeOptions) (*BloomFilter, error) {
+	if err := o.checkBytes(uint64(len(data))); err != nil {
+		return nil, err
+	}
+	if hasMagic(data) {
+		return deserializeV2(data, o)
+	}
+	// Version 1 always wrote size/8+1 bytes of bits.
+	if len(data) < 16 {
+		return nil, ErrInvalidFormat
+	}
+	size := binary.LittleEndian.Uint64(data[0:8])
+	if uint64(len(data)-16) != size/8+1 {
+		return nil, ErrInvalidFormat
+	}
+	if err := o.checkBits(size); err != nil {
+		return nil, err
+	}
+	return deserializeV1(data), nil
+}
+
+func deserializeV1(data []byte) *BloomFilter {
+	size := binary.LittleEndian.Uint64(data[0:8])
+	count := binary.LittleEndian.Uint64(data[8:16])
+
+	bf := New64(size, 1, WithHasher(legacyHasher))
+	bf.count.Store(count)
+
+	for i, b := range data[16:] {
+		if i/8 >= len(bf.bitset) {
+			break
+		}
+		bf.bitset[i/8] |= uint64(b) << (8 * (i % 8))
+	}
+	if len(bf.bitset) > 0 {
+		bf.bitset[len(bf.bitset)-1] &= lastWordMask(bf.size)
+	}
+	return bf
+}
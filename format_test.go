@@ -0,0 +1,166 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"testing"
+)
+
+// The version 2 encodings of two empty 128-bit filters, spelled out field by
+// field. A change to either is a format change.
+func TestFormatGolden(t *testing.T) {
+	plain := New(128, 3)
+	plain.count.Store(2)
+	seeded := New(128, 3, WithSeed(42), WithHasher(XXHash64))
+	seeded.partitioned = true
+
+	for _, tc := range []struct {
+		name string
+		bf   *BloomFilter
+		want string
+	}{
+		{"plain", plain, "89424c4d" + "02" + "00" + "0b" + hex.EncodeToString([]byte("murmur3-128")) +
+			"03000000" + "8000000000000000" + "0200000000000000" + "00" +
+			"00000000000000000000000000000000" + "e1dc9ac9"},
+		{"seeded partitioned", seeded, "89424c4d" + "02" + "01" + "08" + hex.EncodeToString([]byte("xxhash64")) +
+			"03000000" + "8000000000000000" + "0000000000000000" + "01" + "2a00000000000000" +
+			"00000000000000000000000000000000" + "e30892b7"},
+	} {
+		if got := hex.EncodeToString(tc.bf.Serialize()); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// Every hasher, seed, layout and compression survives a round trip, so the
+// decoded filter answers exactly as the original did.
+func TestFormatRoundTrip(t *testing.T) {
+	filters := builtinFilters()
+	filters["partitioned"] = NewPartitioned(1<<16, 7)
+	for name, bf := range filters {
+		for i := 0; i < 1000; i++ {
+			bf.AddString(strconv.Itoa(i))
+		}
+		o := DecodeOptions{Hasher: bf.hasher}
+		for _, c := range []Compression{NoCompression, Gzip, Zstd, Snappy} {
+			blob, err := bf.SerializeCompressed(c)
+			if err != nil {
+				t.Fatalf("%s, compression %d: %v", name, c, err)
+			}
+			decoded, err := o.Deserialize(blob)
+			if err != nil {
+				t.Fatalf("%s, compression %d: %v", name, c, err)
+			}
+			streamed, n, err := o.Decode(bytes.NewReader(blob))
+			if err != nil || n != int64(len(blob)) {
+				t.Fatalf("%s, compression %d: Decode = %d, %v; want %d, nil", name, c, n, err, len(blob))
+			}
+			for _, got := range []*BloomFilter{decoded, streamed} {
+				if !got.Equal(bf) || got.Count() != bf.Count() || got.partitioned != bf.partitioned {
+					t.Errorf("%s, compression %d: decoded filter differs", name, c)
+				}
+			}
+		}
+	}
+}
+
+// A version 1 blob loads as a single-hash filter with its bits and count in
+// place.
+func TestFormatV1(t *testing.T) {
+	blob := v1Blob(100, 13)
+	blob[8] = 5
+	blob[16] = 0x81
+	blob[16+12] = 0x08
+
+	bf, err := Deserialize(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bf.size != 100 || bf.numHashes != 1 || bf.hasher != legacyHasher || bf.Count() != 5 {
+		t.Errorf("got size %d, %d hashes, hasher %s, count %d", bf.size, bf.numHashes, bf.hasher.Name(), bf.Count())
+	}
+	if bf.bitset[0] != 0x81 || bf.bitset[1] != 0x08<<32 {
+		t.Errorf("bits = %#x, want [0x81 %#x]", bf.bitset, uint64(0x08)<<32)
+	}
+
+	// Bits past size are dropped.
+	blob[16+12] = 0xf0
+	if bf, err = Deserialize(blob); err != nil {
+		t.Fatal(err)
+	}
+	if bf.bitset[1] != 0 {
+		t.Errorf("bits = %#x, want the bits past size cleared", bf.bitset)
+	}
+}
+
+// baselineSerialize writes items as the version 1 code did: each at bit
+// fnv.New64().Sum64() % size, packed LSB first after the size and count.
+func baselineSerialize(size uint64, items []string) []byte {
+	serialized := make([]byte, 8+8+size/8+1)
+	binary.LittleEndian.PutUint64(serialized[0:8], size)
+	binary.LittleEndian.PutUint64(serialized[8:16], uint64(len(items)))
+	for _, item := range items {
+		h := fnv.New64()
+		h.Write([]byte(item))
+		i := h.Sum64() % size
+		serialized[16+i/8] |= 1 << (i % 8)
+	}
+	return serialized
+}
+
+// Items in a version 1 filter are still found after loading it, by either
+// decoder, and after saving it again in the current format.
+func TestFormatV1Items(t *testing.T) {
+	items := make([]string, 200)
+	for i := range items {
+		items[i] = "item" + strconv.Itoa(i)
+	}
+	blob := baselineSerialize(10007, items)
+
+	bf, err := Deserialize(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, _, err := (DecodeOptions{}).Decode(bytes.NewReader(blob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resaved, err := Deserialize(bf.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, f := range map[string]*BloomFilter{"Deserialize": bf, "Decode": streamed, "resaved": resaved} {
+		for _, item := range items {
+			if !f.ContainsString(item) {
+				t.Fatalf("%s: %s is missing", name, item)
+			}
+		}
+	}
+
+	// Adds after loading go where the old code would have put them too.
+	bf.AddString("new")
+	if want := baselineSerialize(10007, append(items, "new")); !bytes.Equal(v1Bits(bf), want[16:]) {
+		t.Error("bits after Add differ from the version 1 placement")
+	}
+}
+
+// v1Bits packs bf's bits as version 1 did.
+func v1Bits(bf *BloomFilter) []byte {
+	data := make([]byte, bf.size/8+1)
+	for i := range data {
+		data[i] = byte(bf.bitset[i/8] >> (8 * (i % 8)))
+	}
+	return data
+}
+
+func TestFormatUnknownHasher(t *testing.T) {
+	// A SipHash filter has no key in its encoding, so it needs the hasher.
+	blob := New(128, 3, WithKey([16]byte{1})).Serialize()
+	if _, err := Deserialize(blob); !errors.Is(err, ErrUnknownHasher) {
+		t.Errorf("got %v, want ErrUnknownHasher", err)
+	}
+}
@@ -0,0 +1,64 @@
+package bloomfilter
+
+import "io"
+
+// FrozenFilter is a read-only snapshot of a BloomFilter. It has no mutating
+// methods and its bits never change, so it can be shared freely between
+// goroutines and its queries never wait on anything.
+type FrozenFilter struct {
+	bf *BloomFilter
+}
+
+// Freeze returns a FrozenFilter holding a copy of the filter's current
+// contents; bf stays usable and later changes to it do not show in the
+// snapshot. It returns nil if the bits cannot be read; see Err.
+func (bf *BloomFilter) Freeze() *FrozenFilter {
+	clone := bf.Clone()
+	if clone == nil {
+		return nil
+	}
+	return &FrozenFilter{bf: clone}
+}
+
+func (ff *FrozenFilter) Contains(item []byte) bool {
+	return ff.bf.Contains(item)
+}
+
+func (ff *FrozenFilter) ContainsString(item string) bool {
+	return ff.bf.ContainsString(item)
+}
+
+func (ff *FrozenFilter) ContainsUint64(item uint64) bool {
+	return ff.bf.ContainsUint64(item)
+}
+
+func (ff *FrozenFilter) ContainsMany(items [][]byte) []bool {
+	return ff.bf.ContainsMany(items)
+}
+
+func (ff *FrozenFilter) Count() uint {
+	return ff.bf.Count()
+}
+
+func (ff *FrozenFilter) EstimatedFalsePositiveRate() float64 {
+	return ff.bf.EstimatedFalsePositiveRate()
+}
+
+func (ff *FrozenFilter) FillRatio() float64 {
+	return ff.bf.FillRatio()
+}
+
+// MarshalBinary uses the Serialize format, so the result loads back with
+// Deserialize as an ordinary BloomFilter.
+func (ff *FrozenFilter) MarshalBinary() ([]byte, error) {
+	return ff.bf.MarshalBinary()
+}
+
+func (ff *FrozenFilter) WriteTo(w io.Writer) (int64, error) {
+	return ff.bf.WriteTo(w)
+}
+
+// Thaw returns a mutable copy of the snapshot.
+func (ff *FrozenFilter) Thaw() *BloomFilter {
+	return ff.bf.Clone()
+}
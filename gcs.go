@@ -0,0 +1,192 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// GolombSet is a Golomb-coded set: every key is hashed into [0, n*2^p), the
+// sorted values are delta-encoded with a Golomb-Rice code of parameter p, and
+// lookups decode the stream in place. It costs about p+1.5 bits per key for a
+// false-positive rate of 1/2^p, and is read-only once built.
+type GolombSet struct {
+	n    uint64
+	p    uint8
+	data []byte
+}
+
+// NewGolombSet encodes keys with a false-positive rate of 1/2^fpBits.
+func NewGolombSet(keys [][]byte, fpBits uint8) *GolombSet {
+	if fpBits < 1 || fpBits > 32 {
+		panic("bloomfilter: golomb parameter must be between 1 and 32")
+	}
+
+	gs := &GolombSet{n: uint64(len(keys)), p: fpBits}
+	values := make([]uint64, len(keys))
+	for i, key := range keys {
+		values[i] = gs.value(key)
+	}
+	slices.Sort(values)
+	values = slices.Compact(values)
+
+	var w bitWriter
+	prev := uint64(0)
+	for _, v := range values {
+		delta := v - prev
+		prev = v
+		for q := delta >> gs.p; q > 0; q-- {
+			w.writeBit(1)
+		}
+		w.writeBit(0)
+		w.writeBits(delta, gs.p)
+	}
+	gs.data = w.bytes()
+	return gs
+}
+
+// value maps key uniformly onto [0, n*2^p).
+func (gs *GolombSet) value(key []byte) uint64 {
+	h, _ := hash128(key)
+	hi, _ := bits.Mul64(h, gs.n<<gs.p)
+	return hi
+}
+
+func (gs *GolombSet) Contains(item []byte) bool {
+	if gs.n == 0 {
+		return false
+	}
+	target := gs.value(item)
+
+	r := bitReader{data: gs.data}
+	v := uint64(0)
+	for {
+		var ok bool
+		if v, ok = gs.next(&r, v); !ok || v > target {
+			return false
+		}
+		if v == target {
+			return true
+		}
+	}
+}
+
+// next decodes the value following v. It fails at the end of the stream,
+// and on a quotient no valid set has, which would otherwise overflow.
+func (gs *GolombSet) next(r *bitReader, v uint64) (uint64, bool) {
+	q := uint64(0)
+	for {
+		bit, ok := r.readBit()
+		if !ok || q > gs.n {
+			return 0, false
+		}
+		if bit == 0 {
+			break
+		}
+		q++
+	}
+	rem, ok := r.readBits(gs.p)
+	if !ok {
+		return 0, false
+	}
+	return v + (q<<gs.p | rem), true
+}
+
+func (gs *GolombSet) Count() uint {
+	return uint(gs.n)
+}
+
+func (gs *GolombSet) Serialize() []byte {
+	serialized := make([]byte, 16+len(gs.data))
+	binary.LittleEndian.PutUint64(serialized[0:8], gs.n)
+	binary.LittleEndian.PutUint64(serialized[8:16], uint64(gs.p))
+	copy(serialized[16:], gs.data)
+	return serialized
+}
+
+// DeserializeGolombSet decodes the whole stream once to check it: at most n
+// increasing values below n*2^p, followed by no more than the zero bits
+// padding the last byte.
+func DeserializeGolombSet(data []byte) (*GolombSet, error) {
+	if len(data) < 16 {
+		return nil, ErrInvalidFormat
+	}
+	n := binary.LittleEndian.Uint64(data[0:8])
+	p := binary.LittleEndian.Uint64(data[8:16])
+	if p < 1 || p > 32 || n > math.MaxInt64>>p {
+		return nil, ErrInvalidFormat
+	}
+	gs := &GolombSet{n: n, p: uint8(p), data: slices.Clone(data[16:])}
+
+	r := bitReader{data: gs.data}
+	var v, count uint64
+	for {
+		start := r.n
+		next, ok := gs.next(&r, v)
+		if !ok || count > 0 && next == v {
+			// Padding long enough to hold a code decodes as a repeat.
+			end := uint(len(gs.data)) * 8
+			if end-start >= 8 || start < end && gs.data[len(gs.data)-1]&(0xff>>(start%8)) != 0 {
+				return nil, ErrInvalidFormat
+			}
+			return gs, nil
+		}
+		if count++; count > n || next >= n<<p {
+			return nil, ErrInvalidFormat
+		}
+		v = next
+	}
+}
+
+// bitWriter and bitReader handle MSB-first bit streams.
+type bitWriter struct {
+	data []byte
+	n    uint
+}
+
+func (w *bitWriter) writeBit(bit uint64) {
+	if w.n%8 == 0 {
+		w.data = append(w.data, 0)
+	}
+	if bit != 0 {
+		w.data[w.n/8] |= 0x80 >> (w.n % 8)
+	}
+	w.n++
+}
+
+func (w *bitWriter) writeBits(v uint64, n uint8) {
+	for i := int(n) - 1; i >= 0; i-- {
+		w.writeBit(v >> i & 1)
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	return w.data
+}
+
+type bitReader struct {
+	data []byte
+	n    uint
+}
+
+func (r *bitReader) readBit() (uint64, bool) {
+	if r.n >= uint(len(r.data))*8 {
+		return 0, false
+	}
+	bit := uint64(r.data[r.n/8]>>(7-r.n%8)) & 1
+	r.n++
+	return bit, true
+}
+
+func (r *bitReader) readBits(n uint8) (uint64, bool) {
+	v := uint64(0)
+	for i := uint8(0); i < n; i++ {
+		bit, ok := r.readBit()
+		if !ok {
+			return 0, false
+		}
+		v = v<<1 | bit
+	}
+	return v, true
+}
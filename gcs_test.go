@@ -0,0 +1,90 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestGolombSetRoundTrip(t *testing.T) {
+	keys := make([][]byte, 1000)
+	for i := range keys {
+		keys[i] = []byte(strconv.Itoa(i))
+	}
+	for _, p := range []uint8{1, 8, 32} {
+		gs := NewGolombSet(keys, p)
+		decoded, err := DeserializeGolombSet(gs.Serialize())
+		if err != nil {
+			t.Fatalf("p=%d: %v", p, err)
+		}
+		for _, key := range keys {
+			if !decoded.Contains(key) {
+				t.Fatalf("p=%d: decoded set lacks %s", p, key)
+			}
+		}
+	}
+
+	if _, err := DeserializeGolombSet(NewGolombSet(nil, 8).Serialize()); err != nil {
+		t.Errorf("empty set: %v", err)
+	}
+	// One value, 1, and three bits of zero padding.
+	if _, err := DeserializeGolombSet(gcsBlob(1, 4, 0x08)); err != nil {
+		t.Errorf("padded stream: %v", err)
+	}
+}
+
+func TestDeserializeGolombSetInvalid(t *testing.T) {
+	valid := NewGolombSet([][]byte{[]byte("a"), []byte("b"), []byte("c")}, 8).Serialize()
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", valid[:15]},
+		{"p 0", gcsBlob(1, 0)},
+		{"p 33", gcsBlob(1, 33)},
+		{"p truncates to 8", gcsBlob(1, 256+8)},
+		{"n overflows range", gcsBlob(math.MaxUint64>>8, 8)},
+		{"values for an empty set", gcsBlob(0, 8, 0x00, 0x80)},
+		{"more values than n", gcsBlob(1, 2, 0x24)},
+		{"repeated value", gcsBlob(4, 1, 0x40, 0x00)},
+		{"value out of range", gcsBlob(1, 1, 0x80)},
+		{"endless quotient", gcsBlob(1, 1, 0xff, 0xff, 0xff)},
+		{"truncated code", gcsBlob(4, 8, 0x00)},
+		{"nonzero padding", gcsBlob(1, 4, 0x09)},
+		{"trailing byte", append(valid[:len(valid):len(valid)], 0x00)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DeserializeGolombSet(tc.data); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("got %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+}
+
+func gcsBlob(n, p uint64, stream ...byte) []byte {
+	data := binary.LittleEndian.AppendUint64(nil, n)
+	data = binary.LittleEndian.AppendUint64(data, p)
+	return append(data, stream...)
+}
+
+// Flipping any bit either fails to decode or leaves a set that still
+// answers lookups.
+func TestDeserializeGolombSetDamaged(t *testing.T) {
+	keys := make([][]byte, 100)
+	for i := range keys {
+		keys[i] = []byte(strconv.Itoa(i))
+	}
+	valid := NewGolombSet(keys, 4).Serialize()
+	for i := range 8 * len(valid) {
+		data := slices.Clone(valid)
+		data[i/8] ^= 1 << (i % 8)
+		if gs, err := DeserializeGolombSet(data); err == nil {
+			for _, key := range keys {
+				gs.Contains(key)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package gossip spreads a filter across a cluster by gossip: each node
+// periodically swaps its whole filter with a random peer and joins the two,
+// so every node converges on the union of everyone's adds. It plugs into
+// github.com/hashicorp/memberlist without depending on it.
+package gossip
+
+import (
+	"sync/atomic"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+// Delegate implements memberlist.Delegate on top of memberlist's push/pull
+// state sync:
+//
+//	cfg := memberlist.DefaultLANConfig()
+//	cfg.Delegate = gossip.NewDelegate(bf)
+//	cfg.PushPullInterval = 10 * time.Second
+//
+// Every node must create its filter with the same size, hash count, hasher
+// and seed. Merges use BloomFilter.Join, so exchanging the same state again
+// is harmless, but a Reset on one node is undone by the next merge.
+type Delegate struct {
+	bf *bloomfilter.BloomFilter
+	// Limits bounds the remote states MergeRemoteState accepts.
+	Limits      bloomfilter.DecodeOptions
+	Compression bloomfilter.Compression
+	err         atomic.Pointer[error]
+}
+
+// NewDelegate returns a delegate gossiping bf, compressed with Zstd, which
+// shrinks sparse filters considerably.
+func NewDelegate(bf *bloomfilter.BloomFilter) *Delegate {
+	return &Delegate{bf: bf, Compression: bloomfilter.Zstd}
+}
+
+func (d *Delegate) NodeMeta(limit int) []byte {
+	return nil
+}
+
+func (d *Delegate) NotifyMsg(msg []byte) {}
+
+func (d *Delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return nil
+}
+
+// LocalState returns the serialized filter, for memberlist to send to a
+// peer.
+func (d *Delegate) LocalState(join bool) []byte {
+	state, err := d.bf.SerializeCompressed(d.Compression)
+	if err != nil {
+		d.setErr(err)
+		return nil
+	}
+	return state
+}
+
+// MergeRemoteState joins a peer's filter into the local one.
+func (d *Delegate) MergeRemoteState(buf []byte, join bool) {
+	if len(buf) == 0 {
+		return
+	}
+	remote, err := d.Limits.Deserialize(buf)
+	if err == nil {
+		err = d.bf.Join(remote)
+	}
+	if err != nil {
+		d.setErr(err)
+	}
+}
+
+// Err returns the last error serializing or merging a state, if any. The
+// memberlist interface has no way to report them.
+func (d *Delegate) Err() error {
+	if p := d.err.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (d *Delegate) setErr(err error) {
+	d.err.Store(&err)
+}
@@ -0,0 +1,117 @@
+package gossip
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+// pushPull swaps states between a and b as a memberlist push/pull sync does.
+func pushPull(a, b *Delegate) {
+	sa, sb := a.LocalState(false), b.LocalState(false)
+	b.MergeRemoteState(sa, false)
+	a.MergeRemoteState(sb, false)
+}
+
+func TestMerge(t *testing.T) {
+	a := NewDelegate(bloomfilter.New(1<<16, 5))
+	b := NewDelegate(bloomfilter.New(1<<16, 5))
+	a.bf.AddString("a")
+	b.bf.AddString("b")
+
+	pushPull(a, b)
+	for _, d := range []*Delegate{a, b} {
+		if !d.bf.ContainsString("a") || !d.bf.ContainsString("b") {
+			t.Error("merged filter lacks an item")
+		}
+	}
+	// Exchanging the same states again changes nothing.
+	before := a.bf.Clone()
+	pushPull(a, b)
+	if !a.bf.Equal(before) || !a.bf.Equal(b.bf) {
+		t.Error("a repeated exchange changed the filters")
+	}
+	if err := a.Err(); err != nil {
+		t.Error(err)
+	}
+
+	// An empty state, as from a peer that failed to serialize, is skipped.
+	a.MergeRemoteState(nil, false)
+	if err := a.Err(); err != nil {
+		t.Errorf("empty state: %v", err)
+	}
+}
+
+// A state that is corrupt, too large or of another shape leaves the filter
+// alone and is reported through Err.
+func TestMergeInvalid(t *testing.T) {
+	big := NewDelegate(bloomfilter.New(1<<20, 5))
+	for name, tc := range map[string]struct {
+		state []byte
+		want  error
+	}{
+		"corrupt":        {[]byte("not a filter"), bloomfilter.ErrInvalidFormat},
+		"other shape":    {NewDelegate(bloomfilter.New(1<<12, 5)).LocalState(false), bloomfilter.ErrIncompatible},
+		"over the limit": {big.LocalState(false), bloomfilter.ErrTooLarge},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := NewDelegate(bloomfilter.New(1<<16, 5))
+			d.Limits.MaxBits = 1 << 16
+			d.bf.AddString("a")
+			before := d.bf.Clone()
+			d.MergeRemoteState(tc.state, false)
+			if err := d.Err(); !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+			if !d.bf.Equal(before) {
+				t.Error("the filter changed")
+			}
+		})
+	}
+}
+
+// Random pairwise exchanges, as memberlist makes between nodes, bring every
+// node to the union of all their adds.
+func TestAntiEntropy(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	nodes := make([]*Delegate, 8)
+	union := bloomfilter.New(1<<16, 5)
+	for i := range nodes {
+		nodes[i] = NewDelegate(bloomfilter.New(1<<16, 5))
+		for j := 0; j < 50; j++ {
+			item := strconv.Itoa(i) + "/" + strconv.Itoa(j)
+			nodes[i].bf.AddString(item)
+			union.AddString(item)
+		}
+	}
+
+	converged := func() bool {
+		for _, d := range nodes {
+			if !d.bf.Equal(union) {
+				return false
+			}
+		}
+		return true
+	}
+	rounds := 0
+	for ; !converged(); rounds++ {
+		if rounds == 100 {
+			t.Fatal("nodes did not converge in 100 rounds")
+		}
+		for _, d := range nodes {
+			peer := nodes[rng.IntN(len(nodes))]
+			if peer != d {
+				pushPull(d, peer)
+			}
+		}
+	}
+	for _, d := range nodes {
+		if err := d.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Logf("converged in %d rounds", rounds)
+}
@@ -0,0 +1,101 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync/atomic"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// guavaMurmur128Mitz64 is the ordinal of BloomFilterStrategies.MURMUR128_MITZ_64.
+const guavaMurmur128Mitz64 = 1
+
+var ErrInvalidGuavaFilter = errors.New("bloomfilter: invalid guava bloom filter")
+
+// GuavaFilter is bit-for-bit compatible with com.google.common.hash.BloomFilter
+// using the MURMUR128_MITZ_64 strategy, over items funneled as raw bytes
+// (Funnels.byteArrayFunnel, or stringFunnel(UTF_8) for strings). Serialize and
+// DeserializeGuava use the layout of BloomFilter.writeTo and readFrom.
+type GuavaFilter struct {
+	data      []uint64
+	numHashes int
+}
+
+// NewGuava sizes a filter exactly as BloomFilter.create does.
+func NewGuava(expectedInsertions uint, fpp float64) *GuavaFilter {
+	if fpp <= 0 || fpp >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
+	}
+	if expectedInsertions == 0 {
+		expectedInsertions = 1
+	}
+	n := float64(expectedInsertions)
+	numBits := int64(-n * math.Log(fpp) / (math.Ln2 * math.Ln2))
+	numHashes := max(1, int(math.Round(float64(numBits)/n*math.Ln2)))
+
+	return &GuavaFilter{
+		data:      make([]uint64, (numBits+63)/64),
+		numHashes: numHashes,
+	}
+}
+
+func (gf *GuavaFilter) bitSize() uint64 {
+	return uint64(len(gf.data)) * 64
+}
+
+func (gf *GuavaFilter) Add(item []byte) {
+	h1, h2 := hashing.Murmur3x64_128(item, 0)
+	combined := h1
+	for i := 0; i < gf.numHashes; i++ {
+		index := (combined & math.MaxInt64) % gf.bitSize()
+		atomic.OrUint64(&gf.data[index/64], 1<<(index%64))
+		combined += h2
+	}
+}
+
+func (gf *GuavaFilter) Contains(item []byte) bool {
+	h1, h2 := hashing.Murmur3x64_128(item, 0)
+	combined := h1
+	for i := 0; i < gf.numHashes; i++ {
+		index := (combined & math.MaxInt64) % gf.bitSize()
+		if atomic.LoadUint64(&gf.data[index/64])&(1<<(index%64)) == 0 {
+			return false
+		}
+		combined += h2
+	}
+	return true
+}
+
+// Serialize writes the strategy ordinal, the hash count, the number of longs
+// and the longs themselves, all big-endian as Java's DataOutputStream does.
+func (gf *GuavaFilter) Serialize() []byte {
+	serialized := make([]byte, 6+8*len(gf.data))
+	serialized[0] = guavaMurmur128Mitz64
+	serialized[1] = byte(gf.numHashes)
+	binary.BigEndian.PutUint32(serialized[2:6], uint32(len(gf.data)))
+	for i := range gf.data {
+		binary.BigEndian.PutUint64(serialized[6+8*i:], atomic.LoadUint64(&gf.data[i]))
+	}
+	return serialized
+}
+
+func DeserializeGuava(data []byte) (*GuavaFilter, error) {
+	if len(data) < 6 || data[0] != guavaMurmur128Mitz64 || data[1] == 0 {
+		return nil, ErrInvalidGuavaFilter
+	}
+	numLongs := binary.BigEndian.Uint32(data[2:6])
+	if numLongs == 0 || uint64(len(data)-6) != 8*uint64(numLongs) {
+		return nil, ErrInvalidGuavaFilter
+	}
+
+	gf := &GuavaFilter{
+		data:      make([]uint64, numLongs),
+		numHashes: int(data[1]),
+	}
+	for i := range gf.data {
+		gf.data[i] = binary.BigEndian.Uint64(data[6+8*i:])
+	}
+	return gf, nil
+}
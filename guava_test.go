@@ -0,0 +1,88 @@
+package bloomfilter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// The golden vectors below come from the reference MurmurHash3_x64_128 and
+// from Guava's optimalNumOfBits, MURMUR128_MITZ_64 and BloomFilter.writeTo,
+// built from C. Guava's Hashing.murmur3_128 hashes "The quick brown fox
+// jumps over the lazy dog" to 6c1b07bc7bbc4be347939ac4a93c437a, the
+// little-endian bytes of h1 and h2 below.
+
+func TestGuavaMurmur3(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		h1, h2 uint64
+	}{
+		{"", 0, 0},
+		{"a", 0x85555565f6597889, 0xe6b53a48510e895a},
+		{"hello", 0xcbd8a7b341bd9b02, 0x5b1e906a48ae1d19},
+		{"The quick brown fox jumps over the lazy dog", 0xe34bbc7bbc071b6c, 0x7a433ca9c49a9347},
+	} {
+		if h1, h2 := hashing.Murmur3x64_128([]byte(tc.in), 0); h1 != tc.h1 || h2 != tc.h2 {
+			t.Errorf("Murmur3x64_128(%q) = %#x, %#x; want %#x, %#x", tc.in, h1, h2, tc.h1, tc.h2)
+		}
+	}
+}
+
+// BloomFilter.create(Funnels.stringFunnel(UTF_8), 1000, 0.01) holding
+// item0 … item499: 9585 bits in 150 longs and seven hashes.
+const (
+	guavaGoldenHeader = "010700000096"
+	guavaGoldenData   = "0b54061b48807d3c9d613297497401bc07585db33194f9dad18ec499d5237a57"
+)
+
+func TestGuavaGolden(t *testing.T) {
+	gf := NewGuava(1000, 0.01)
+	for i := 0; i < 500; i++ {
+		gf.Add([]byte("item" + strconv.Itoa(i)))
+	}
+	data := gf.Serialize()
+	if got := hex.EncodeToString(data[:6]); got != guavaGoldenHeader {
+		t.Errorf("header = %s, want %s", got, guavaGoldenHeader)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != guavaGoldenData {
+		t.Errorf("serialized filter hashes to %s, want %s", got, guavaGoldenData)
+	}
+
+	decoded, err := DeserializeGuava(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 500; i++ {
+		if !decoded.Contains([]byte("item" + strconv.Itoa(i))) {
+			t.Fatalf("decoded filter lacks item%d", i)
+		}
+	}
+}
+
+func TestDeserializeGuavaInvalid(t *testing.T) {
+	valid := NewGuava(100, 0.01).Serialize()
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", valid[:5]},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing bytes", append(valid[:len(valid):len(valid)], 0)},
+		// MURMUR128_MITZ_32, whose indexing GuavaFilter does not implement.
+		{"other strategy", append([]byte{0}, valid[1:]...)},
+		{"no hashes", append([]byte{1, 0}, valid[2:]...)},
+		{"no longs", []byte{1, 7, 0, 0, 0, 0}},
+		{"longs past end", []byte{1, 7, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DeserializeGuava(tc.data); !errors.Is(err, ErrInvalidGuavaFilter) {
+				t.Errorf("got %v, want ErrInvalidGuavaFilter", err)
+			}
+		})
+	}
+}
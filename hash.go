@@ -0,0 +1,42 @@
+package bloomfilter
+
+import (
+	"unsafe"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// hash128 returns the base and step of the double-hashing probe sequence.
+func hash128(item []byte) (uint64, uint64) {
+	return hashing.Sum128(item)
+}
+
+// hashUint64 derives the probe sequence for an integer key from two SplitMix64
+// outputs, which is far cheaper than hashing its bytes.
+func hashUint64(item uint64) (uint64, uint64) {
+	return hashing.SplitMix64(item), hashing.SplitMix64(item ^ hashing.SplitMix64Gamma)
+}
+
+// stringBytes views s as a byte slice without copying. Only the hash
+// functions see the result, and they neither modify nor retain it.
+func stringBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+// location returns the bit index of the i-th probe, h1 + i*h2 mod size. The
+// step is made odd, so that an h2 of zero cannot put every probe on one bit,
+// and so that the probes of a power-of-two size all differ.
+func location(h1, h2 uint64, i int, size uint64) uint64 {
+	return (h1 + uint64(i)*(h2|1)) % size
+}
+
+// probe returns the bit index of the i-th probe in a filter of the given
+// shape. In a partitioned filter each probe is confined to its own slice of
+// size/numHashes bits.
+func probe(h1, h2 uint64, i int, size uint64, numHashes int, partitioned bool) uint64 {
+	if partitioned {
+		partition := size / uint64(numHashes)
+		return uint64(i)*partition + location(h1, h2, i, partition)
+	}
+	return location(h1, h2, i, size)
+}
@@ -0,0 +1,190 @@
+package bloomfilter
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"hash/fnv"
+	"math/bits"
+	"sync"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// Hasher maps an item to the two 64-bit values from which a BloomFilter
+// derives its probe positions by double hashing. Both values should be
+// uniformly distributed; the second is used as the step between probes.
+//
+// Serialized filters name their hasher, so a Hasher must always produce the
+// same values for the same item, and a filter can only be read back where
+// its hasher is registered with RegisterHasher.
+type Hasher interface {
+	Name() string
+	Hash128(item []byte) (uint64, uint64)
+}
+
+// The built-in hashers. New filters use Murmur3 unless WithHasher says
+// otherwise.
+var (
+	// Murmur3 is MurmurHash3 x64 128-bit with seed 0, whose two halves are
+	// used as they are.
+	Murmur3 Hasher = murmur3Hasher{}
+	// XXHash64 is XXH64 with seed 0, the fastest choice for long keys. Its
+	// one 64-bit hash is stretched to two by SplitMix64.
+	XXHash64 Hasher = xxHasher{}
+	// FNV1a128 is 128-bit FNV-1a with each half finalized by the MurmurHash3
+	// mixer.
+	FNV1a128 Hasher = fnvHasher{}
+)
+
+// legacyHasher places items as filters did before version 2: the 64-bit
+// FNV-1 hash of the item, taken mod size, with no second hash. It only
+// serves version 1 filters, which always have a single probe.
+var legacyHasher Hasher = fnv1Hasher{}
+
+var defaultHasher = Murmur3
+
+type murmur3Hasher struct{}
+
+func (murmur3Hasher) Name() string { return "murmur3-128" }
+
+func (murmur3Hasher) Hash128(item []byte) (uint64, uint64) {
+	return hashing.Murmur3x64_128(item, 0)
+}
+
+type xxHasher struct{}
+
+func (xxHasher) Name() string { return "xxhash64" }
+
+func (xxHasher) Hash128(item []byte) (uint64, uint64) {
+	h := hashing.XXHash64(item, 0)
+	return h, hashing.SplitMix64(h)
+}
+
+type fnvHasher struct{}
+
+type fnv1Hasher struct{}
+
+func (fnv1Hasher) Name() string { return "fnv1-64-legacy" }
+
+func (fnv1Hasher) Hash128(item []byte) (uint64, uint64) {
+	h := fnv.New64()
+	h.Write(item)
+	return h.Sum64(), 0
+}
+
+func (fnvHasher) Name() string { return "fnv1a-128" }
+
+func (fnvHasher) Hash128(item []byte) (uint64, uint64) {
+	return hashing.Sum128(item)
+}
+
+// WithSeed perturbs the hasher's output with seed, so that filters with
+// different seeds place the same items on unrelated bits and do not share
+// false positives. The seed is serialized with the filter. A seed of 0 is
+// the same as none.
+func WithSeed(seed uint64) Option {
+	return func(bf *BloomFilter) {
+		bf.seed = seed
+	}
+}
+
+// WithRandomSeed is WithSeed with a seed drawn from crypto/rand.
+func WithRandomSeed() Option {
+	var b [8]byte
+	rand.Read(b[:])
+	return WithSeed(binary.LittleEndian.Uint64(b[:]) | 1)
+}
+
+// hashedLike copies the hasher and seed of bf, for filters derived from it.
+func hashedLike(bf *BloomFilter) Option {
+	return func(derived *BloomFilter) {
+		derived.hasher = bf.hasher
+		derived.seed = bf.seed
+	}
+}
+
+func (bf *BloomFilter) hash(item []byte) (uint64, uint64) {
+	return bf.seeded(bf.hasher.Hash128(item))
+}
+
+// seeded remixes both hash halves with the seed. The mixer is a nonlinear
+// bijection, so distinct seeds give unrelated probe positions.
+func (bf *BloomFilter) seeded(h1, h2 uint64) (uint64, uint64) {
+	if bf.seed == 0 {
+		return h1, h2
+	}
+	return hashing.Mix64(h1 ^ bf.seed), hashing.Mix64(h2 ^ bits.RotateLeft64(bf.seed, 32))
+}
+
+// WithKey switches to SipHash-2-4 keyed with key, so probe positions cannot
+// be predicted, and false positives manufactured, without it. The key is not
+// serialized: read such a filter back with UnmarshalBinary or ReadFrom on a
+// filter built WithKey(key).
+func WithKey(key [16]byte) Option {
+	return WithHasher(sipHasher{
+		k0: binary.LittleEndian.Uint64(key[0:8]),
+		k1: binary.LittleEndian.Uint64(key[8:16]),
+	})
+}
+
+type sipHasher struct {
+	k0, k1 uint64
+}
+
+func (sipHasher) Name() string { return "siphash-2-4-128" }
+
+func (h sipHasher) Hash128(item []byte) (uint64, uint64) {
+	return hashing.SipHash128(item, h.k0, h.k1)
+}
+
+// sameHasher reports whether two hashers place items identically, as far as
+// can be told: by name, and by key for SipHash.
+func sameHasher(a, b Hasher) bool {
+	ka, aKeyed := a.(sipHasher)
+	kb, bKeyed := b.(sipHasher)
+	return a.Name() == b.Name() && aKeyed == bKeyed && ka == kb
+}
+
+var (
+	hashersMu sync.RWMutex
+	hashers   = map[string]Hasher{
+		Murmur3.Name():  Murmur3,
+		XXHash64.Name(): XXHash64,
+		FNV1a128.Name(): FNV1a128,
+		// So that a version 1 filter survives being saved again.
+		legacyHasher.Name(): legacyHasher,
+	}
+)
+
+// RegisterHasher makes h available to Deserialize and the other decoders
+// under h.Name(). It panics if the name is taken, or too long to serialize.
+func RegisterHasher(h Hasher) {
+	name := h.Name()
+	if name == "" || len(name) > 255 {
+		panic("bloomfilter: hasher name must be 1 to 255 bytes")
+	}
+
+	hashersMu.Lock()
+	defer hashersMu.Unlock()
+	if _, dup := hashers[name]; dup {
+		panic("bloomfilter: hasher " + name + " registered twice")
+	}
+	hashers[name] = h
+}
+
+func lookupHasher(name string) (Hasher, error) {
+	hashersMu.RLock()
+	defer hashersMu.RUnlock()
+	if h, ok := hashers[name]; ok {
+		return h, nil
+	}
+	return nil, ErrUnknownHasher
+}
+
+// WithHasher selects the hash function used to place items. The default is
+// Murmur3.
+func WithHasher(h Hasher) Option {
+	return func(bf *BloomFilter) {
+		bf.hasher = h
+	}
+}
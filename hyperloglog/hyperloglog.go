@@ -0,0 +1,163 @@
+// Package hyperloglog implements HyperLogLog, which estimates the number of
+// distinct items in a stream using a few kilobytes of memory.
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"sync"
+	"unsafe"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+const (
+	MinPrecision = 4
+	MaxPrecision = 18
+)
+
+var (
+	ErrIncompatible = errors.New("hyperloglog: sketches must have the same precision")
+	ErrInvalidData  = errors.New("hyperloglog: invalid serialized sketch")
+)
+
+// Sketch keeps 2^precision one-byte registers. Its standard error is about
+// 1.04/sqrt(2^precision): 1.6% at the default precision of 12, in 4 KiB.
+type Sketch struct {
+	mu        sync.RWMutex
+	registers []uint8
+	precision uint8
+}
+
+// New returns a sketch of the given precision, between MinPrecision and
+// MaxPrecision. Zero selects 12.
+func New(precision uint8) *Sketch {
+	if precision == 0 {
+		precision = 12
+	}
+	if precision < MinPrecision || precision > MaxPrecision {
+		panic("hyperloglog: precision must be between 4 and 18")
+	}
+	return &Sketch{
+		registers: make([]uint8, 1<<precision),
+		precision: precision,
+	}
+}
+
+// Add records item. The top precision bits of its hash pick a register,
+// which keeps the longest run of leading zeros seen in the rest.
+func (s *Sketch) Add(item []byte) {
+	h, _ := hashing.Sum128(item)
+	index := h >> (64 - s.precision)
+	rank := uint8(bits.LeadingZeros64(h<<s.precision|1<<(s.precision-1))) + 1
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registers[index] = max(s.registers[index], rank)
+}
+
+// Estimate returns the approximate number of distinct items added. Small
+// counts fall back to linear counting over the empty registers, which is
+// more accurate there; the 64-bit hash needs no large-range correction.
+func (s *Sketch) Estimate() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := float64(len(s.registers))
+	var sum float64
+	var zeros int
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(len(s.registers)) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
+
+// Merge folds other into s, which then estimates the size of the union.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.precision != other.precision {
+		return ErrIncompatible
+	}
+	if s == other {
+		return nil
+	}
+	defer lockMerge(s, other)()
+
+	for i, r := range other.registers {
+		s.registers[i] = max(s.registers[i], r)
+	}
+	return nil
+}
+
+// lockMerge write-locks dst and read-locks src, which must differ, in
+// address order, so that a.Merge(b) and b.Merge(a) running at once cannot
+// deadlock.
+func lockMerge(dst, src *Sketch) (unlock func()) {
+	if uintptr(unsafe.Pointer(dst)) < uintptr(unsafe.Pointer(src)) {
+		dst.mu.Lock()
+		src.mu.RLock()
+	} else {
+		src.mu.RLock()
+		dst.mu.Lock()
+	}
+	return func() {
+		src.mu.RUnlock()
+		dst.mu.Unlock()
+	}
+}
+
+func (s *Sketch) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.registers)
+}
+
+// Serialize uses the same framing as the Bloom filter: a little-endian
+// uint64 header field (the precision) followed by the registers, a byte
+// each.
+func (s *Sketch) Serialize() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	serialized := make([]byte, 8, 8+len(s.registers))
+	binary.LittleEndian.PutUint64(serialized[0:8], uint64(s.precision))
+	return append(serialized, s.registers...)
+}
+
+func Deserialize(data []byte) (*Sketch, error) {
+	if len(data) < 8 {
+		return nil, ErrInvalidData
+	}
+	p := binary.LittleEndian.Uint64(data[0:8])
+	if p < MinPrecision || p > MaxPrecision || len(data) != 8+1<<p {
+		return nil, ErrInvalidData
+	}
+	s := New(uint8(p))
+	copy(s.registers, data[8:])
+	for _, r := range s.registers {
+		if r > 64-uint8(p)+1 {
+			return nil, ErrInvalidData
+		}
+	}
+	return s, nil
+}
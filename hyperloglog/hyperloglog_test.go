@@ -0,0 +1,114 @@
+package hyperloglog
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// Estimates stay within four standard errors, 4*1.04/sqrt(2^precision),
+// from the small range where linear counting applies to the large.
+func TestEstimateError(t *testing.T) {
+	for _, p := range []uint8{10, 12, 14} {
+		s := New(p)
+		bound := 4 * 1.04 / math.Sqrt(float64(uint(1)<<p))
+		added := 0
+		for _, n := range []int{10, 100, 1000, 10000, 100000} {
+			for ; added < n; added++ {
+				s.Add([]byte(strconv.Itoa(added)))
+			}
+			// Adding an item again changes nothing.
+			s.Add([]byte("0"))
+			est := float64(s.Estimate())
+			if e := math.Abs(est-float64(n)) / float64(n); e > bound {
+				t.Errorf("precision %d, %d items: estimate %.0f, error %.3f over %.3f", p, n, est, e, bound)
+			}
+		}
+	}
+	if est := New(0).Estimate(); est != 0 {
+		t.Errorf("empty sketch estimates %d", est)
+	}
+}
+
+// A merged sketch estimates the union, the same as one fed both streams.
+func TestMerge(t *testing.T) {
+	a, b, both := New(0), New(0), New(0)
+	for i := 0; i < 20000; i++ {
+		item := []byte(strconv.Itoa(i))
+		if i < 15000 {
+			a.Add(item)
+		}
+		if i >= 5000 {
+			b.Add(item)
+		}
+		both.Add(item)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.registers, both.registers) {
+		t.Error("merged sketch differs from one fed both streams")
+	}
+	if err := a.Merge(a); err != nil || !bytes.Equal(a.registers, both.registers) {
+		t.Errorf("self-merge: %v, or the registers changed", err)
+	}
+	if err := a.Merge(New(10)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("got %v, want ErrIncompatible", err)
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	s := New(10)
+	for i := 0; i < 5000; i++ {
+		s.Add([]byte(strconv.Itoa(i)))
+	}
+	blob := s.Serialize()
+	got, err := Deserialize(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.precision != s.precision || !bytes.Equal(got.registers, s.registers) || got.Estimate() != s.Estimate() {
+		t.Error("round trip changed the sketch")
+	}
+
+	badRank := bytes.Clone(blob)
+	badRank[8] = 64 - 10 + 2
+	for name, data := range map[string][]byte{
+		"empty":           nil,
+		"truncated":       blob[:len(blob)-1],
+		"trailing":        append(bytes.Clone(blob), 0),
+		"low precision":   append([]byte{MinPrecision - 1, 0, 0, 0, 0, 0, 0, 0}, make([]byte, 1<<(MinPrecision-1))...),
+		"impossible rank": badRank,
+	} {
+		if _, err := Deserialize(data); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: got %v, want ErrInvalidData", name, err)
+		}
+	}
+}
+
+// Merge takes its locks in address order whichever way round it is called,
+// so a.Merge(b) and b.Merge(a) running at once cannot deadlock.
+func TestMergeLockOrder(t *testing.T) {
+	lo, hi := New(0), New(0)
+	if uintptr(unsafe.Pointer(lo)) > uintptr(unsafe.Pointer(hi)) {
+		lo, hi = hi, lo
+	}
+
+	// Hold lo as a merge into it would, and merge it into hi meanwhile.
+	lo.mu.Lock()
+	done := make(chan error)
+	go func() { done <- hi.Merge(lo) }()
+	time.Sleep(10 * time.Millisecond)
+	if !hi.mu.TryRLock() {
+		t.Fatal("Merge holds the destination while waiting for the source")
+	}
+	hi.mu.RUnlock()
+	lo.mu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package iblt implements an invertible Bloom lookup table over 64-bit keys.
+// Two peers can each insert their keys, subtract one table from the other
+// and decode the result to get the exact symmetric difference of their
+// sets, at a cost proportional to the difference rather than the sets.
+package iblt
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sync"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// numHashes is the number of cells each key goes into, one in each of as
+// many equal partitions of the table.
+const numHashes = 3
+
+// checkSeed keys the hash that tells a cell holding one key from a mix.
+const checkSeed = 0x5bd1e9955bd1e995
+
+var (
+	ErrIncompatible = errors.New("iblt: tables must have the same size")
+	ErrDecodeFailed = errors.New("iblt: difference too large to decode")
+	ErrInvalidData  = errors.New("iblt: invalid serialized table")
+)
+
+type cell struct {
+	count   int64
+	keySum  uint64
+	hashSum uint64
+}
+
+// pure reports whether the cell holds exactly one key, inserted or deleted.
+func (c cell) pure() bool {
+	return (c.count == 1 || c.count == -1) && c.hashSum == check(c.keySum)
+}
+
+func check(key uint64) uint64 {
+	return hashing.Mix64(key ^ checkSeed)
+}
+
+// Table is an invertible Bloom lookup table. Decoding succeeds with high
+// probability while the table holds no more than about cells/1.5 keys, so
+// size it for the difference expected, not the sets; for differences below
+// a hundred or so, allow a few times that.
+type Table struct {
+	mu    sync.RWMutex
+	cells []cell
+}
+
+// New returns a table of cells cells, rounded up to a multiple of three.
+func New(cells uint) *Table {
+	n := max((cells+numHashes-1)/numHashes, 1) * numHashes
+	return &Table{cells: make([]cell, n)}
+}
+
+// index returns the cell of key in partition i.
+func (t *Table) index(key uint64, i int) int {
+	part := uint64(len(t.cells) / numHashes)
+	j, _ := bits.Mul64(hashing.Mix64(key^hashing.SplitMix64(uint64(i))), part)
+	return i*int(part) + int(j)
+}
+
+func (t *Table) update(key uint64, delta int64) {
+	h := check(key)
+	for i := 0; i < numHashes; i++ {
+		c := &t.cells[t.index(key, i)]
+		c.count += delta
+		c.keySum ^= key
+		c.hashSum ^= h
+	}
+}
+
+func (t *Table) Insert(key uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.update(key, 1)
+}
+
+// Delete removes key. Deleting a key that was never inserted is allowed, and
+// is what Subtract does in bulk: it then decodes as removed.
+func (t *Table) Delete(key uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.update(key, -1)
+}
+
+// Subtract deletes every key of other from t, leaving t holding the keys
+// only in t as inserted and those only in other as removed.
+func (t *Table) Subtract(other *Table) error {
+	if t == other {
+		other = other.Clone()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	if len(t.cells) != len(other.cells) {
+		return ErrIncompatible
+	}
+	for i, c := range other.cells {
+		t.cells[i].count -= c.count
+		t.cells[i].keySum ^= c.keySum
+		t.cells[i].hashSum ^= c.hashSum
+	}
+	return nil
+}
+
+// Decode lists the keys in t, those with a net insert as added and those
+// with a net delete as removed, leaving t unchanged. If t holds too many
+// keys to peel apart it returns what it recovered with ErrDecodeFailed.
+func (t *Table) Decode() (added, removed []uint64, err error) {
+	t.mu.RLock()
+	cells := append([]cell(nil), t.cells...)
+	t.mu.RUnlock()
+	work := &Table{cells: cells}
+
+	queue := make([]int, 0, len(cells))
+	for i, c := range cells {
+		if c.pure() {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		c := cells[i]
+		if !c.pure() {
+			continue
+		}
+		if c.count == 1 {
+			added = append(added, c.keySum)
+		} else {
+			removed = append(removed, c.keySum)
+		}
+		work.update(c.keySum, -c.count)
+		for j := 0; j < numHashes; j++ {
+			if k := work.index(c.keySum, j); cells[k].pure() {
+				queue = append(queue, k)
+			}
+		}
+	}
+
+	for _, c := range cells {
+		if c != (cell{}) {
+			return added, removed, ErrDecodeFailed
+		}
+	}
+	return added, removed, nil
+}
+
+// Clone returns an independent copy of t.
+func (t *Table) Clone() *Table {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return &Table{cells: append([]cell(nil), t.cells...)}
+}
+
+func (t *Table) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.cells)
+}
+
+// Serialize uses the same framing as the Bloom filter: a little-endian
+// uint64 header field (the cell count) followed by each cell's count, key
+// sum and hash sum.
+func (t *Table) Serialize() []byte {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	serialized := make([]byte, 8, 8+24*len(t.cells))
+	binary.LittleEndian.PutUint64(serialized[0:8], uint64(len(t.cells)))
+	for _, c := range t.cells {
+		serialized = binary.LittleEndian.AppendUint64(serialized, uint64(c.count))
+		serialized = binary.LittleEndian.AppendUint64(serialized, c.keySum)
+		serialized = binary.LittleEndian.AppendUint64(serialized, c.hashSum)
+	}
+	return serialized
+}
+
+func Deserialize(data []byte) (*Table, error) {
+	if len(data) < 8 {
+		return nil, ErrInvalidData
+	}
+	n := binary.LittleEndian.Uint64(data[0:8])
+	if n == 0 || n%numHashes != 0 || (len(data)-8)%24 != 0 || n != uint64(len(data)-8)/24 {
+		return nil, ErrInvalidData
+	}
+	t := &Table{cells: make([]cell, n)}
+	for i := range t.cells {
+		b := data[8+24*i:]
+		t.cells[i] = cell{
+			count:   int64(binary.LittleEndian.Uint64(b[0:8])),
+			keySum:  binary.LittleEndian.Uint64(b[8:16]),
+			hashSum: binary.LittleEndian.Uint64(b[16:24]),
+		}
+	}
+	return t, nil
+}
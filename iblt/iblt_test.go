@@ -0,0 +1,119 @@
+package iblt
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// peers returns tables over a shared set of keys plus onlyA keys in a and
+// onlyB in b, with the keys unique to each.
+func peers(cells uint, shared, onlyA, onlyB int) (a, b *Table, wantA, wantB []uint64) {
+	a, b = New(cells), New(cells)
+	key := uint64(1)
+	for range shared {
+		a.Insert(key)
+		b.Insert(key)
+		key++
+	}
+	for range onlyA {
+		a.Insert(key)
+		wantA = append(wantA, key)
+		key++
+	}
+	for range onlyB {
+		b.Insert(key)
+		wantB = append(wantB, key)
+		key++
+	}
+	return a, b, wantA, wantB
+}
+
+// Subtracting one peer's table from the other's decodes to exactly the keys
+// each has and the other lacks, however large the shared set.
+func TestDecodeDifference(t *testing.T) {
+	a, b, wantA, wantB := peers(150, 10000, 30, 20)
+	if err := a.Subtract(b); err != nil {
+		t.Fatal(err)
+	}
+	before := a.Serialize()
+	added, removed, err := a.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	if !slices.Equal(added, wantA) || !slices.Equal(removed, wantB) {
+		t.Errorf("decoded %v and %v, want %v and %v", added, removed, wantA, wantB)
+	}
+	if !bytes.Equal(a.Serialize(), before) {
+		t.Error("Decode changed the table")
+	}
+}
+
+// A difference far past the table's capacity fails to decode, and says so.
+func TestDecodeTooLarge(t *testing.T) {
+	a, b, _, _ := peers(30, 100, 200, 200)
+	if err := a.Subtract(b); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.Decode(); !errors.Is(err, ErrDecodeFailed) {
+		t.Errorf("got %v, want ErrDecodeFailed", err)
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	a, _, _, _ := peers(60, 100, 10, 0)
+	if err := a.Subtract(a); err != nil {
+		t.Fatal(err)
+	}
+	if added, removed, err := a.Decode(); err != nil || len(added)+len(removed) != 0 {
+		t.Errorf("self-difference decoded to %v, %v, %v", added, removed, err)
+	}
+	if err := a.Subtract(New(90)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("got %v, want ErrIncompatible", err)
+	}
+
+	// Deleting what was inserted cancels out.
+	c := New(60)
+	c.Insert(7)
+	c.Delete(7)
+	c.Delete(8)
+	if added, removed, err := c.Decode(); err != nil || len(added) != 0 || !slices.Equal(removed, []uint64{8}) {
+		t.Errorf("decoded %v, %v, %v; want only 8 removed", added, removed, err)
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	a, b, wantA, _ := peers(60, 500, 10, 0)
+	blob := a.Serialize()
+	got, err := Deserialize(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Serialize(), blob) {
+		t.Fatal("round trip changed the table")
+	}
+	if err := got.Subtract(b); err != nil {
+		t.Fatal(err)
+	}
+	added, _, err := got.Decode()
+	slices.Sort(added)
+	if err != nil || !slices.Equal(added, wantA) {
+		t.Errorf("decoded %v, %v after a round trip, want %v", added, err, wantA)
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"no cells":  make([]byte, 8),
+		"truncated": blob[:len(blob)-1],
+		"short":     blob[:len(blob)-24],
+		"trailing":  append(bytes.Clone(blob), 0),
+		"odd cells": append([]byte{1, 0, 0, 0, 0, 0, 0, 0}, make([]byte, 24)...),
+	} {
+		if _, err := Deserialize(data); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: got %v, want ErrInvalidData", name, err)
+		}
+	}
+}
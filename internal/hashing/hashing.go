@@ -0,0 +1,49 @@
+// Package hashing holds the hash primitives shared by the filters in this
+// module.
+package hashing
+
+import "math/bits"
+
+const (
+	fnvOffset128Higher = 0x6c62272e07bb0142
+	fnvOffset128Lower  = 0x62b821756295c58d
+	fnvPrime128Lower   = 0x13b
+	fnvPrime128Shift   = 24
+)
+
+// Sum128 returns the two halves of the 128-bit FNV-1a hash of data, each run
+// through Mix64.
+func Sum128(data []byte) (uint64, uint64) {
+	h1, h2 := uint64(fnvOffset128Higher), uint64(fnvOffset128Lower)
+	for _, c := range data {
+		h2 ^= uint64(c)
+		hi, lo := bits.Mul64(fnvPrime128Lower, h2)
+		hi += h2<<fnvPrime128Shift + fnvPrime128Lower*h1
+		h1, h2 = hi, lo
+	}
+	// FNV only diffuses upwards, so short keys leave most of each half
+	// unchanged; mix before the halves are reduced modulo a table size.
+	return Mix64(h1), Mix64(h2)
+}
+
+// Mix64 is the MurmurHash3 64-bit finalizer.
+func Mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// SplitMix64Gamma is the increment of the SplitMix64 sequence.
+const SplitMix64Gamma = 0x9e3779b97f4a7c15
+
+// SplitMix64 returns the SplitMix64 output for state x: x advanced by
+// SplitMix64Gamma and then mixed.
+func SplitMix64(x uint64) uint64 {
+	z := x + SplitMix64Gamma
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
@@ -0,0 +1,90 @@
+package hashing
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	murmurC1 = 0x87c37b91114253d5
+	murmurC2 = 0x4cf5ad432745937f
+)
+
+// Murmur3x64_128 returns the two halves of MurmurHash3_x64_128 of data.
+func Murmur3x64_128(data []byte, seed uint32) (uint64, uint64) {
+	return murmur3(data, nil, len(data), seed)
+}
+
+// Murmur3x64_128Append is Murmur3x64_128 of data followed by the byte b,
+// without copying data to append it.
+func Murmur3x64_128Append(data []byte, b byte, seed uint32) (uint64, uint64) {
+	var buf [16]byte
+	tail := append(append(buf[:0], data[len(data)&^15:]...), b)
+	return murmur3(data, tail, len(data)+1, seed)
+}
+
+// murmur3 hashes the whole blocks of data, then tail in place of the rest
+// of data if it is not nil, as a message of n bytes.
+func murmur3(data, tail []byte, n int, seed uint32) (uint64, uint64) {
+	h1, h2 := uint64(seed), uint64(seed)
+
+	for ; len(data) >= 16; data = data[16:] {
+		h1, h2 = murmurBlock(h1, h2, data)
+	}
+	if tail != nil {
+		data = tail
+	}
+	if len(data) == 16 {
+		h1, h2 = murmurBlock(h1, h2, data)
+		data = nil
+	}
+
+	var k1, k2 uint64
+	for i := len(data) - 1; i >= 8; i-- {
+		k2 |= uint64(data[i]) << ((i - 8) * 8)
+	}
+	if len(data) > 8 {
+		h2 ^= murmurMixK2(k2)
+	}
+	for i := min(len(data), 8) - 1; i >= 0; i-- {
+		k1 |= uint64(data[i]) << (i * 8)
+	}
+	if len(data) > 0 {
+		h1 ^= murmurMixK1(k1)
+	}
+
+	h1 ^= uint64(n)
+	h2 ^= uint64(n)
+	h1 += h2
+	h2 += h1
+	h1 = Mix64(h1)
+	h2 = Mix64(h2)
+	h1 += h2
+	h2 += h1
+	return h1, h2
+}
+
+func murmurBlock(h1, h2 uint64, block []byte) (uint64, uint64) {
+	h1 ^= murmurMixK1(binary.LittleEndian.Uint64(block[0:8]))
+	h1 = bits.RotateLeft64(h1, 27)
+	h1 += h2
+	h1 = h1*5 + 0x52dce729
+
+	h2 ^= murmurMixK2(binary.LittleEndian.Uint64(block[8:16]))
+	h2 = bits.RotateLeft64(h2, 31)
+	h2 += h1
+	h2 = h2*5 + 0x38495ab5
+	return h1, h2
+}
+
+func murmurMixK1(k uint64) uint64 {
+	k *= murmurC1
+	k = bits.RotateLeft64(k, 31)
+	return k * murmurC2
+}
+
+func murmurMixK2(k uint64) uint64 {
+	k *= murmurC2
+	k = bits.RotateLeft64(k, 33)
+	return k * murmurC1
+}
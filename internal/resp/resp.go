@@ -0,0 +1,269 @@
+// Package resp implements the parts of the Redis serialization protocol
+// (RESP2) needed to talk to a Redis server, or to be one.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Error is an error reply sent by the server.
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+var ErrProtocol = errors.New("resp: protocol error")
+
+// Limits bounds the values ReadValueLimits accepts. Bulk strings and arrays
+// declare their lengths up front, so a reader that trusted them could be
+// made to allocate any amount. Zero fields take the defaults.
+type Limits struct {
+	// MaxBulkLen bounds the bytes of a bulk string. It defaults to
+	// DefaultMaxBulkLen, the default proto-max-bulk-len of Redis.
+	MaxBulkLen int
+	// MaxArrayLen bounds the elements of an array. It defaults to
+	// DefaultMaxArrayLen.
+	MaxArrayLen int
+}
+
+const (
+	DefaultMaxBulkLen  = 512 << 20
+	DefaultMaxArrayLen = 1 << 20
+)
+
+const (
+	// maxDepth bounds the nesting of arrays, which are read recursively.
+	maxDepth = 32
+	// readChunk is the most a bulk string grows by before the bytes it
+	// claims have arrived, and arrayChunk the same for array elements.
+	readChunk  = 64 << 10
+	arrayChunk = 1 << 10
+)
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxBulkLen <= 0 {
+		l.MaxBulkLen = DefaultMaxBulkLen
+	}
+	if l.MaxArrayLen <= 0 {
+		l.MaxArrayLen = DefaultMaxArrayLen
+	}
+	return l
+}
+
+// Value is a decoded reply. Exactly one of the fields is meaningful,
+// depending on the reply type; Null is set for nil bulk strings and arrays.
+type Value struct {
+	Str   []byte
+	Int   int64
+	Array []Value
+	Null  bool
+}
+
+// Conn is a client connection. It is not safe for concurrent use. Commands
+// are buffered until Flush, so several can be pipelined before their replies
+// are read in order.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+func Dial(addr string, timeout time.Duration) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(conn), nil
+}
+
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Send buffers a command. Arguments are strings, byte slices or integers.
+func (c *Conn) Send(args ...any) error {
+	c.w.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		case int:
+			b = strconv.AppendInt(nil, int64(v), 10)
+		case int64:
+			b = strconv.AppendInt(nil, v, 10)
+		case uint64:
+			b = strconv.AppendUint(nil, v, 10)
+		default:
+			return fmt.Errorf("resp: unsupported argument type %T", arg)
+		}
+		WriteBulk(c.w, b)
+	}
+	return nil
+}
+
+func (c *Conn) Flush() error {
+	return c.w.Flush()
+}
+
+// Receive reads one reply. Error replies are returned as Error.
+func (c *Conn) Receive() (Value, error) {
+	return ReadValue(c.r)
+}
+
+// Do sends one command and waits for its reply.
+func (c *Conn) Do(args ...any) (Value, error) {
+	if err := c.Send(args...); err != nil {
+		return Value{}, err
+	}
+	if err := c.Flush(); err != nil {
+		return Value{}, err
+	}
+	return c.Receive()
+}
+
+func WriteBulk(w *bufio.Writer, b []byte) {
+	w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		if err == bufio.ErrBufferFull {
+			return nil, ErrProtocol
+		}
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, ErrProtocol
+	}
+	return line[:len(line)-2], nil
+}
+
+// ReadValue reads one RESP2 value within the default Limits.
+func ReadValue(r *bufio.Reader) (Value, error) {
+	return ReadValueLimits(r, Limits{})
+}
+
+// ReadValueLimits reads one RESP2 value. Lengths over l fail with an error
+// matching ErrProtocol before anything is allocated for them.
+func ReadValueLimits(r *bufio.Reader, l Limits) (Value, error) {
+	return readValue(r, l.withDefaults(), 0)
+}
+
+func readValue(r *bufio.Reader, l Limits, depth int) (Value, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return Value{}, err
+	}
+	if len(line) == 0 {
+		return Value{}, ErrProtocol
+	}
+
+	switch line[0] {
+	case '+':
+		return Value{Str: append([]byte(nil), line[1:]...)}, nil
+	case '-':
+		return Value{}, Error(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil {
+			return Value{}, ErrProtocol
+		}
+		return Value{Int: n}, nil
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 {
+			return Value{}, ErrProtocol
+		}
+		if n == -1 {
+			return Value{Null: true}, nil
+		}
+		if n > l.MaxBulkLen {
+			return Value{}, fmt.Errorf("%w: bulk length %d over %d", ErrProtocol, n, l.MaxBulkLen)
+		}
+		b, err := readBulk(r, n+2)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Str: b[:n]}, nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 {
+			return Value{}, ErrProtocol
+		}
+		if n == -1 {
+			return Value{Null: true}, nil
+		}
+		if n > l.MaxArrayLen {
+			return Value{}, fmt.Errorf("%w: array length %d over %d", ErrProtocol, n, l.MaxArrayLen)
+		}
+		if depth == maxDepth {
+			return Value{}, fmt.Errorf("%w: arrays nested over %d deep", ErrProtocol, maxDepth)
+		}
+		values := make([]Value, 0, min(n, arrayChunk))
+		for i := 0; i < n; i++ {
+			v, err := readValue(r, l, depth+1)
+			if err != nil {
+				return Value{}, err
+			}
+			values = append(values, v)
+		}
+		return Value{Array: values}, nil
+	}
+	return Value{}, ErrProtocol
+}
+
+// readBulk reads n bytes, growing the buffer as they arrive so that memory
+// follows what the peer actually sends rather than what it declares.
+func readBulk(r *bufio.Reader, n int) ([]byte, error) {
+	b := make([]byte, 0, min(n, readChunk))
+	for len(b) < n {
+		m := min(n-len(b), max(len(b), readChunk))
+		b = slices.Grow(b, m)[:len(b)+m]
+		if _, err := io.ReadFull(r, b[len(b)-m:]); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// The Write functions encode replies, for servers.
+
+func WriteSimple(w *bufio.Writer, s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+func WriteError(w *bufio.Writer, msg string) {
+	w.WriteString("-" + msg + "\r\n")
+}
+
+func WriteInt(w *bufio.Writer, n int64) {
+	w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func WriteArrayHeader(w *bufio.Writer, n int) {
+	w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+func WriteNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
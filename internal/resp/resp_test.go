@@ -0,0 +1,58 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func read(s string, l Limits) (Value, error) {
+	return ReadValueLimits(bufio.NewReader(strings.NewReader(s)), l)
+}
+
+func TestReadValueLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		l    Limits
+		err  error
+	}{
+		{"bulk", "$3\r\nabc\r\n", Limits{}, nil},
+		{"bulk at limit", "$3\r\nabc\r\n", Limits{MaxBulkLen: 3}, nil},
+		{"bulk over limit", "$4\r\nabcd\r\n", Limits{MaxBulkLen: 3}, ErrProtocol},
+		{"bulk over default", "$536870913\r\n", Limits{}, ErrProtocol},
+		{"array over limit", "*3\r\n:1\r\n:2\r\n:3\r\n", Limits{MaxArrayLen: 2}, ErrProtocol},
+		{"array over default", "*1048577\r\n", Limits{}, ErrProtocol},
+		{"nested too deep", strings.Repeat("*1\r\n", maxDepth+1) + ":1\r\n", Limits{}, ErrProtocol},
+		{"nested", strings.Repeat("*1\r\n", maxDepth) + ":1\r\n", Limits{}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := read(tc.in, tc.l); !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+// A declared length is not allocated until its bytes arrive, so a short
+// stream claiming a huge value fails cheaply.
+func TestReadValueTruncated(t *testing.T) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := read("$536870000\r\nabc", Limits{}); err == nil {
+		t.Fatal("truncated bulk string read")
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes reading a truncated bulk string", n)
+	}
+
+	big := strings.Repeat("x", 3*readChunk+5)
+	v, err := read("$"+strconv.Itoa(len(big))+"\r\n"+big+"\r\n", Limits{})
+	if err != nil || string(v.Str) != big {
+		t.Errorf("bulk of %d bytes: got %d bytes, %v", len(big), len(v.Str), err)
+	}
+}
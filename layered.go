@@ -0,0 +1,99 @@
+package bloomfilter
+
+import "sync"
+
+// LayeredFilter counts occurrences of each item up to a small limit by
+// stacking filters: the c-th Add of an item goes into layer c, so "seen at
+// least c times" means present in the first c layers. A false positive in a
+// layer can overcount an item, never undercount it.
+type LayeredFilter struct {
+	mu     sync.Mutex
+	layers []*BloomFilter
+}
+
+// NewLayered returns a filter counting up to layers occurrences, each layer
+// sized like NewWithEstimates for capacity items at fpRate. WithBackend is
+// not supported.
+func NewLayered(layers int, capacity uint, fpRate float64, opts ...Option) *LayeredFilter {
+	if layers < 1 {
+		panic("bloomfilter: layered filter needs at least one layer")
+	}
+	lf := &LayeredFilter{layers: make([]*BloomFilter, layers)}
+	lf.layers[0] = NewWithEstimates(capacity, fpRate, opts...)
+	if lf.layers[0].backend != nil {
+		panic("bloomfilter: LayeredFilter does not support a Backend")
+	}
+	for i := 1; i < layers; i++ {
+		lf.layers[i] = NewWithEstimates(capacity, fpRate, append(opts, hashedLike(lf.layers[0]))...)
+	}
+	return lf
+}
+
+// occurrences counts the leading layers that hold h1, h2.
+func (lf *LayeredFilter) occurrences(h1, h2 uint64) int {
+	for i, layer := range lf.layers {
+		if !layer.contains(h1, h2) {
+			return i
+		}
+	}
+	return len(lf.layers)
+}
+
+func (lf *LayeredFilter) Add(item []byte) {
+	lf.TestAndAdd(item)
+}
+
+// TestAndAdd adds item and returns how many times it had been seen before,
+// up to the number of layers. To act on the second occurrence of an event,
+// act when it returns 1.
+func (lf *LayeredFilter) TestAndAdd(item []byte) int {
+	h1, h2 := lf.layers[0].hash(item)
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	n := lf.occurrences(h1, h2)
+	if n < len(lf.layers) {
+		lf.layers[n].add(h1, h2)
+	}
+	return n
+}
+
+// Occurrences is the number of times item has been added, up to the number
+// of layers.
+func (lf *LayeredFilter) Occurrences(item []byte) int {
+	return lf.occurrences(lf.layers[0].hash(item))
+}
+
+// AtLeast reports whether item has been added at least c times.
+func (lf *LayeredFilter) AtLeast(item []byte, c int) bool {
+	if c <= 0 {
+		return true
+	}
+	if c > len(lf.layers) {
+		return false
+	}
+	h1, h2 := lf.layers[0].hash(item)
+	for _, layer := range lf.layers[:c] {
+		if !layer.contains(h1, h2) {
+			return false
+		}
+	}
+	return true
+}
+
+func (lf *LayeredFilter) Contains(item []byte) bool {
+	return lf.AtLeast(item, 1)
+}
+
+// Layers returns the layers themselves, not copies; layer i holds the
+// items seen more than i times.
+func (lf *LayeredFilter) Layers() []*BloomFilter {
+	return lf.layers
+}
+
+func (lf *LayeredFilter) Reset() {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	for _, layer := range lf.layers {
+		layer.Reset()
+	}
+}
@@ -0,0 +1,67 @@
+package bloomfilter
+
+import (
+	"strconv"
+	"testing"
+)
+
+// Each item counts its adds up to the number of layers, and never fewer
+// than were made.
+func TestLayeredCounts(t *testing.T) {
+	lf := NewLayered(4, 1000, 0.001)
+	for i := 0; i < 600; i++ {
+		item := []byte(strconv.Itoa(i))
+		for c := 0; c < i%6; c++ {
+			if seen := lf.TestAndAdd(item); seen < min(c, 4) {
+				t.Fatalf("item %d: TestAndAdd saw it %d times before, want at least %d", i, seen, min(c, 4))
+			}
+		}
+	}
+
+	over := 0
+	for i := 0; i < 600; i++ {
+		item := []byte(strconv.Itoa(i))
+		want := min(i%6, 4)
+		got := lf.Occurrences(item)
+		if got < want {
+			t.Fatalf("item %d: %d occurrences, want %d", i, got, want)
+		}
+		if got > want {
+			over++
+		}
+		if !lf.AtLeast(item, want) || lf.AtLeast(item, 5) || !lf.AtLeast(item, 0) {
+			t.Errorf("item %d: AtLeast disagrees with %d occurrences", i, want)
+		}
+		if lf.Contains(item) != (got > 0) {
+			t.Errorf("item %d: Contains disagrees with %d occurrences", i, got)
+		}
+	}
+	// Only a false positive in the next layer overcounts.
+	if over > 5 {
+		t.Errorf("%d of 600 items overcounted", over)
+	}
+	if n := lf.Layers()[0].Count(); n != 500 {
+		t.Errorf("first layer holds %d items, want the 500 added at least once", n)
+	}
+
+	lf.Reset()
+	if lf.Occurrences([]byte("5")) != 0 {
+		t.Error("Reset left counts behind")
+	}
+}
+
+func TestNewLayeredInvalid(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"no layers": func() { NewLayered(0, 100, 0.01) },
+		"backend":   func() { NewLayered(2, 100, 0.01, WithBackend(NewSparseBackend())) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("NewLayered did not panic")
+				}
+			}()
+			fn()
+		})
+	}
+}
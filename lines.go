@@ -0,0 +1,96 @@
+package bloomfilter
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// LineOptions adjusts how AddLines reads keys.
+type LineOptions struct {
+	// TrimSpace strips leading and trailing white space from each line.
+	TrimSpace bool
+	// Lowercase folds each line to lower case.
+	Lowercase bool
+	// SkipEmpty drops empty lines, after trimming, instead of adding the
+	// empty key.
+	SkipEmpty bool
+	// MaxLineBytes bounds a line; longer ones fail with
+	// bufio.ErrTooLong. Default 1 MiB.
+	MaxLineBytes int
+	// BatchSize is how many keys go to each AddMany call. Default 1024.
+	BatchSize int
+	// Progress reports the keys added so far, checked after each batch.
+	Progress Progress
+}
+
+// AddLines adds every line read from r as a key, without its line ending,
+// and returns how many it added. Reads are buffered and keys go in through
+// AddMany, so a filter with a Backend makes one round trip per batch. On a
+// read error the lines before it have been added.
+func (bf *BloomFilter) AddLines(r io.Reader, o LineOptions) (int, error) {
+	if o.MaxLineBytes <= 0 {
+		o.MaxLineBytes = 1 << 20
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1024
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, min(o.MaxLineBytes, 64<<10)), o.MaxLineBytes)
+	// The scanner reuses its buffer, so copy each batch into an arena.
+	var arena []byte
+	ends := make([]int, 0, o.BatchSize)
+	batch := make([][]byte, 0, o.BatchSize)
+	added := 0
+	t := o.Progress.track(-1, 64<<10)
+	flush := func() {
+		start := 0
+		for _, end := range ends {
+			batch = append(batch, arena[start:end:end])
+			start = end
+		}
+		bf.AddMany(batch)
+		added += len(batch)
+		t.advance(int64(len(batch)))
+		arena, ends, batch = arena[:0], ends[:0], batch[:0]
+	}
+
+	for sc.Scan() {
+		line := sc.Bytes()
+		if o.TrimSpace {
+			line = bytes.TrimSpace(line)
+		}
+		if o.SkipEmpty && len(line) == 0 {
+			continue
+		}
+		arena = appendKey(arena, line, o.Lowercase)
+		ends = append(ends, len(arena))
+		if len(ends) == o.BatchSize {
+			flush()
+		}
+	}
+	flush()
+	t.finish()
+	return added, sc.Err()
+}
+
+func appendKey(dst, line []byte, lower bool) []byte {
+	if !lower {
+		return append(dst, line...)
+	}
+	for _, c := range line {
+		if c >= utf8.RuneSelf {
+			return append(dst, bytes.ToLower(line)...)
+		}
+	}
+	start := len(dst)
+	dst = append(dst, line...)
+	for i, c := range dst[start:] {
+		if 'A' <= c && c <= 'Z' {
+			dst[start+i] = c + 'a' - 'A'
+		}
+	}
+	return dst
+}
@@ -1,15 +1,18 @@
+//go:build ignore
+
 package main
 
-import {
+import (
 	"fmt"
-	"github.com/hriday-13th/bloom-filter"
-}
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
 
 func main() {
-	bf := BloomFilter.New(1000, 3)
+	bf := bloomfilter.New(1000, 3)
 
 	elements := []string{"apple", "banana", "cherry"}
-	
+
 	for _, e := range elements {
 		bf.Add([]byte(e))
 	}
@@ -29,6 +32,10 @@ func main() {
 	fmt.Println("Union contains 'date':", union.Contains([]byte("date")))
 
 	serialized := bf.Serialize()
-	deserialized := bloomfilter.Deserialize(serialized)
+	deserialized, err := bloomfilter.Deserialize(serialized)
+	if err != nil {
+		fmt.Println("Deserialize:", err)
+		return
+	}
 	fmt.Println("Deserialized filter contains 'banana':", deserialized.Contains([]byte("banana")))
-}
\ No newline at end of file
+}
@@ -0,0 +1,208 @@
+package bloomfilter
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// managerSuffix ends the file name of each filter a Manager saves.
+const managerSuffix = ".bloom"
+
+var ErrInvalidName = errors.New("bloomfilter: filter names must be non-empty and free of path separators")
+
+// FilterConfig sizes the filters a Manager creates, as for NewWithEstimates.
+type FilterConfig struct {
+	Capacity uint
+	FPRate   float64
+	Options  []Option
+}
+
+// Manager owns a set of named filters. Get returns a filter by name,
+// loading it from the manager's directory the first time or, failing
+// that, creating it from its configuration. Save and SaveAll write filters
+// back, one file per name.
+type Manager struct {
+	// Limits bounds the files Get loads.
+	Limits DecodeOptions
+
+	dir      string
+	defaults FilterConfig
+
+	mu      sync.Mutex
+	configs map[string]FilterConfig
+	filters map[string]*managedFilter
+}
+
+// managedFilter is a filter that is loaded, or being loaded; ready closes
+// once bf or err is set.
+type managedFilter struct {
+	ready chan struct{}
+	bf    *BloomFilter
+	err   error
+}
+
+// NewManager returns a manager keeping filters in dir, or only in memory if
+// dir is empty, and creating them from defaults unless Configure says
+// otherwise.
+func NewManager(dir string, defaults FilterConfig) *Manager {
+	return &Manager{
+		dir:      dir,
+		defaults: defaults,
+		configs:  make(map[string]FilterConfig),
+		filters:  make(map[string]*managedFilter),
+	}
+}
+
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`) && !strings.ContainsRune(name, 0)
+}
+
+func (m *Manager) path(name string) string {
+	return filepath.Join(m.dir, name+managerSuffix)
+}
+
+// Configure sets how the filter called name is created, for the next time
+// Get needs to create it.
+func (m *Manager) Configure(name string, cfg FilterConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.configs[name] = cfg
+}
+
+// Get returns the filter called name. Concurrent calls for a filter not
+// yet in memory share one load, and a failed load is retried by the next
+// Get.
+func (m *Manager) Get(name string) (*BloomFilter, error) {
+	if !validName(name) {
+		return nil, ErrInvalidName
+	}
+	m.mu.Lock()
+	if mf, ok := m.filters[name]; ok {
+		m.mu.Unlock()
+		<-mf.ready
+		return mf.bf, mf.err
+	}
+	mf := &managedFilter{ready: make(chan struct{})}
+	m.filters[name] = mf
+	cfg, ok := m.configs[name]
+	if !ok {
+		cfg = m.defaults
+	}
+	m.mu.Unlock()
+
+	mf.bf, mf.err = m.load(name, cfg)
+	if mf.err != nil {
+		m.mu.Lock()
+		if m.filters[name] == mf {
+			delete(m.filters, name)
+		}
+		m.mu.Unlock()
+	}
+	close(mf.ready)
+	return mf.bf, mf.err
+}
+
+func (m *Manager) load(name string, cfg FilterConfig) (*BloomFilter, error) {
+	if m.dir != "" {
+		bf, err := m.Limits.LoadFile(m.path(name))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return bf, err
+		}
+	}
+	return NewWithEstimates(cfg.Capacity, cfg.FPRate, cfg.Options...), nil
+}
+
+// Set installs bf under name, replacing any filter already there.
+func (m *Manager) Set(name string, bf *BloomFilter) error {
+	if !validName(name) {
+		return ErrInvalidName
+	}
+	mf := &managedFilter{ready: make(chan struct{}), bf: bf}
+	close(mf.ready)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.filters[name] = mf
+	return nil
+}
+
+// Delete drops the filter called name from memory and from the directory.
+func (m *Manager) Delete(name string) error {
+	if !validName(name) {
+		return ErrInvalidName
+	}
+	m.mu.Lock()
+	delete(m.filters, name)
+	m.mu.Unlock()
+	if m.dir == "" {
+		return nil
+	}
+	if err := os.Remove(m.path(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Names lists every filter, in memory or in the directory, sorted.
+func (m *Manager) Names() ([]string, error) {
+	m.mu.Lock()
+	names := make(map[string]struct{}, len(m.filters))
+	for name := range m.filters {
+		names[name] = struct{}{}
+	}
+	m.mu.Unlock()
+
+	if m.dir != "" {
+		entries, err := os.ReadDir(m.dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, e := range entries {
+			if name, ok := strings.CutSuffix(e.Name(), managerSuffix); ok && e.Type().IsRegular() && validName(name) {
+				names[name] = struct{}{}
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(names)), nil
+}
+
+// Save writes the filter called name to the directory, if it is in memory.
+func (m *Manager) Save(name string) error {
+	if m.dir == "" {
+		return nil
+	}
+	m.mu.Lock()
+	mf, ok := m.filters[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	<-mf.ready
+	if mf.err != nil {
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	return mf.bf.SaveFile(m.path(name))
+}
+
+// SaveAll saves every filter in memory, returning all the errors joined.
+func (m *Manager) SaveAll() error {
+	m.mu.Lock()
+	names := slices.Collect(maps.Keys(m.filters))
+	m.mu.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if err := m.Save(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,138 @@
+package bloomfilter
+
+import "unsafe"
+
+// The MemoryUsage methods return the approximate bytes a filter holds in
+// this process: its bit array or counters, plus the fixed bookkeeping around
+// them. Bits kept in a Backend live elsewhere and are not counted.
+
+// MemoryUsage also counts pages copied for open snapshots and the change
+// log kept by TrackChanges. A memory-mapped bit array is counted in full
+// although the OS pages it in from the file on demand.
+func (bf *BloomFilter) MemoryUsage() uint64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	n := uint64(unsafe.Sizeof(*bf)) + 8*uint64(cap(bf.bitset))
+	if p := bf.snapshots.Load(); p != nil {
+		for _, pages := range *p {
+			n += uint64(unsafe.Sizeof(*pages)) + uint64(cap(pages.saved))*8
+			for i := range pages.saved {
+				if pages.saved[i].Load() != nil {
+					n += 8 * snapshotPageWords
+				}
+			}
+		}
+	}
+	if log := bf.changes.Load(); log != nil {
+		n += uint64(unsafe.Sizeof(*log)) + 8*uint64(cap(log.dirty))
+	}
+	n += uint64(len(bf.watermarks)) * uint64(unsafe.Sizeof(watermark{})+8)
+	return n
+}
+
+func (cf *CountingBloomFilter) MemoryUsage() uint64 {
+	cf.mu.RLock()
+	defer cf.mu.RUnlock()
+	return uint64(unsafe.Sizeof(*cf)) + uint64(cap(cf.counters))
+}
+
+func (bf *BlockedBloomFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*bf)) + uint64(cap(bf.blocks))*uint64(unsafe.Sizeof(block{}))
+}
+
+func (sbf *ScalableBloomFilter) MemoryUsage() uint64 {
+	sbf.mu.RLock()
+	defer sbf.mu.RUnlock()
+	n := uint64(unsafe.Sizeof(*sbf)) + uint64(cap(sbf.stages))*uint64(unsafe.Sizeof(scalableStage{}))
+	for _, s := range sbf.stages {
+		n += s.filter.MemoryUsage()
+	}
+	return n
+}
+
+func (sf *ShardedFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*sf)) + 8*uint64(cap(sf.shards)) + sumUsage(sf.shards)
+}
+
+func (sbf *StableBloomFilter) MemoryUsage() uint64 {
+	sbf.mu.Lock()
+	defer sbf.mu.Unlock()
+	return uint64(unsafe.Sizeof(*sbf)) + uint64(cap(sbf.cells))
+}
+
+func (ff *FrozenFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*ff)) + ff.bf.MemoryUsage()
+}
+
+func (uf *UnlockedFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*uf)) + uf.bf.MemoryUsage()
+}
+
+func (xf *XorFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*xf)) + uint64(cap(xf.fingerprints))
+}
+
+func (qf *QuotientFilter) MemoryUsage() uint64 {
+	qf.mu.RLock()
+	defer qf.mu.RUnlock()
+	return uint64(unsafe.Sizeof(*qf)) + 8*uint64(cap(qf.slots))
+}
+
+func (sb *SplitBlockFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*sb)) + uint64(cap(sb.blocks))*uint64(unsafe.Sizeof(sbbfBlock{}))
+}
+
+func (gf *GuavaFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*gf)) + 8*uint64(cap(gf.data))
+}
+
+func (gs *GolombSet) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*gs)) + uint64(cap(gs.data))
+}
+
+func (rf *RotatingFilter) MemoryUsage() uint64 {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+	return uint64(unsafe.Sizeof(*rf)) + 8*uint64(cap(rf.gens)) + sumUsage(rf.gens)
+}
+
+func (tf *TimeRotatingFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*tf)-unsafe.Sizeof(tf.rf)) + tf.rf.MemoryUsage()
+}
+
+func (lf *LayeredFilter) MemoryUsage() uint64 {
+	return uint64(unsafe.Sizeof(*lf)) + 8*uint64(cap(lf.layers)) + sumUsage(lf.layers)
+}
+
+func (sf *SpectralBloomFilter) MemoryUsage() uint64 {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return uint64(unsafe.Sizeof(*sf)) + 4*uint64(cap(sf.counters))
+}
+
+func (ef *ExpiringFilter) MemoryUsage() uint64 {
+	ef.mu.RLock()
+	defer ef.mu.RUnlock()
+	return uint64(unsafe.Sizeof(*ef)) + 4*uint64(cap(ef.cells))
+}
+
+func (df *DecayingFilter) MemoryUsage() uint64 {
+	df.mu.Lock()
+	defer df.mu.Unlock()
+	return uint64(unsafe.Sizeof(*df)) + uint64(cap(df.counters))
+}
+
+func (af *AgePartitionedFilter) MemoryUsage() uint64 {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+	return uint64(unsafe.Sizeof(*af)) + 8*uint64(cap(af.bitset))
+}
+
+func sumUsage(filters []*BloomFilter) uint64 {
+	var n uint64
+	for _, bf := range filters {
+		n += bf.MemoryUsage()
+	}
+	return n
+}
@@ -0,0 +1,143 @@
+// Package minhash builds MinHash signatures, fixed-size summaries of a set
+// from which the Jaccard similarity of two sets can be estimated.
+package minhash
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+var (
+	ErrIncompatible = errors.New("minhash: signatures must have the same size")
+	ErrInvalidData  = errors.New("minhash: invalid serialized signature")
+)
+
+// MinHash keeps, for each of size hash functions, the smallest hash of any
+// item added. The fraction of positions where two signatures agree
+// estimates the Jaccard similarity of their sets, with a standard error of
+// about 1/sqrt(size).
+type MinHash struct {
+	mu   sync.RWMutex
+	mins []uint64
+}
+
+// New returns an empty signature of size hash functions; 128 gives about
+// 9% error, 1024 about 3%.
+func New(size int) *MinHash {
+	if size < 1 {
+		panic("minhash: size must be positive")
+	}
+	mins := make([]uint64, size)
+	for i := range mins {
+		mins[i] = math.MaxUint64
+	}
+	return &MinHash{mins: mins}
+}
+
+// function i hashes an item's h by remixing it with a seed of its own.
+// Mix64 is a bijection, so each function is a permutation of the hashes.
+func function(h uint64, i int) uint64 {
+	return hashing.Mix64(h ^ hashing.SplitMix64(uint64(i)))
+}
+
+func (m *MinHash) Add(item []byte) {
+	h, _ := hashing.Sum128(item)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, v := range m.mins {
+		m.mins[i] = min(v, function(h, i))
+	}
+}
+
+func (m *MinHash) AddString(item string) {
+	m.Add([]byte(item))
+}
+
+// Similarity estimates the Jaccard similarity, the size of the
+// intersection over the size of the union, of the sets behind m and other.
+func (m *MinHash) Similarity(other *MinHash) (float64, error) {
+	if m == other {
+		return 1, nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	if len(m.mins) != len(other.mins) {
+		return 0, ErrIncompatible
+	}
+
+	var equal int
+	for i, v := range m.mins {
+		if v == other.mins[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(m.mins)), nil
+}
+
+// Merge folds other into m, which then summarizes the union of both sets.
+func (m *MinHash) Merge(other *MinHash) error {
+	if m == other {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	if len(m.mins) != len(other.mins) {
+		return ErrIncompatible
+	}
+	for i, v := range other.mins {
+		m.mins[i] = min(m.mins[i], v)
+	}
+	return nil
+}
+
+// Signature returns a copy of the minimum hashes.
+func (m *MinHash) Signature() []uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]uint64(nil), m.mins...)
+}
+
+func (m *MinHash) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.mins {
+		m.mins[i] = math.MaxUint64
+	}
+}
+
+// Serialize uses the same framing as the Bloom filter: a little-endian
+// uint64 header field (the size) followed by the minimum hashes.
+func (m *MinHash) Serialize() []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	serialized := make([]byte, 8, 8+8*len(m.mins))
+	binary.LittleEndian.PutUint64(serialized[0:8], uint64(len(m.mins)))
+	for _, v := range m.mins {
+		serialized = binary.LittleEndian.AppendUint64(serialized, v)
+	}
+	return serialized
+}
+
+func Deserialize(data []byte) (*MinHash, error) {
+	if len(data) < 8 {
+		return nil, ErrInvalidData
+	}
+	size := binary.LittleEndian.Uint64(data[0:8])
+	if size == 0 || size != uint64(len(data)-8)/8 || len(data)%8 != 0 {
+		return nil, ErrInvalidData
+	}
+	m := &MinHash{mins: make([]uint64, size)}
+	for i := range m.mins {
+		m.mins[i] = binary.LittleEndian.Uint64(data[8+8*i:])
+	}
+	return m, nil
+}
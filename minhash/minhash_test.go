@@ -0,0 +1,94 @@
+package minhash
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// sets returns signatures of {0..n} and {shift..shift+n}, whose Jaccard
+// similarity is (n-shift)/(n+shift).
+func sets(size, n, shift int) (*MinHash, *MinHash) {
+	a, b := New(size), New(size)
+	for i := 0; i < n; i++ {
+		a.AddString(strconv.Itoa(i))
+		b.AddString(strconv.Itoa(i + shift))
+	}
+	return a, b
+}
+
+// Estimates stay within four standard errors, 4/sqrt(size), of the true
+// similarity.
+func TestSimilarity(t *testing.T) {
+	const size, n = 1024, 1000
+	for _, shift := range []int{0, 100, 333, 600, 1000} {
+		a, b := sets(size, n, shift)
+		got, err := a.Similarity(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := float64(n-shift) / float64(n+shift)
+		if math.Abs(got-want) > 4/math.Sqrt(size) {
+			t.Errorf("shift %d: similarity %.3f, want %.3f", shift, got, want)
+		}
+		if shift == 0 && got != 1 {
+			t.Errorf("identical sets: similarity %f, want 1", got)
+		}
+	}
+	a, _ := sets(size, n, 0)
+	if got, _ := a.Similarity(a); got != 1 {
+		t.Errorf("self-similarity %f, want 1", got)
+	}
+	if _, err := a.Similarity(New(size / 2)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("got %v, want ErrIncompatible", err)
+	}
+}
+
+// A merged signature is the signature of the union.
+func TestMerge(t *testing.T) {
+	a, b := sets(128, 500, 250)
+	union := New(128)
+	for i := 0; i < 750; i++ {
+		union.AddString(strconv.Itoa(i))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(a.Signature(), union.Signature()) {
+		t.Error("merged signature differs from the union's")
+	}
+	if err := a.Merge(New(64)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("got %v, want ErrIncompatible", err)
+	}
+
+	a.Reset()
+	if !slices.Equal(a.Signature(), New(128).Signature()) {
+		t.Error("Reset left hashes behind")
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	m, _ := sets(64, 100, 0)
+	blob := m.Serialize()
+	got, err := Deserialize(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim, _ := got.Similarity(m); !slices.Equal(got.Signature(), m.Signature()) || sim != 1 {
+		t.Error("round trip changed the signature")
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"no hashes": make([]byte, 8),
+		"truncated": blob[:len(blob)-1],
+		"short":     blob[:len(blob)-8],
+		"trailing":  append(slices.Clone(blob), 0),
+	} {
+		if _, err := Deserialize(data); !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: got %v, want ErrInvalidData", name, err)
+		}
+	}
+}
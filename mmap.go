@@ -0,0 +1,185 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	mmapHeaderSize = 32
+
+	mmapFlagPartitioned = 1 << 0
+	mmapHasherShift     = 8
+)
+
+// mmapHashers numbers the hashers a filter file can use, in the second byte
+// of the flags. Files written before hashers were selectable hold 0, FNV.
+var mmapHashers = []Hasher{FNV1a128, Murmur3, XXHash64}
+
+var (
+	ErrMmapUnsupported = errors.New("bloomfilter: memory-mapped filters are not supported on this platform")
+	ErrInvalidMmapFile = errors.New("bloomfilter: invalid memory-mapped filter file")
+
+	// errInvalidMmapHeader is both, so callers of either kind catch a header
+	// no filter could have written.
+	errInvalidMmapHeader = fmt.Errorf("%w: %w", ErrInvalidMmapFile, ErrInvalidFormat)
+)
+
+// mmapState is the file behind a memory-mapped filter. For a file opened
+// with OpenShared, flushed is the file's count as of the last Flush.
+type mmapState struct {
+	file    *os.File
+	data    []byte
+	shared  bool
+	flushed uint64
+}
+
+// CreateMmap creates a filter file at path and maps it into memory. The file
+// holds a 32-byte header (size, count, hash count, flags; little-endian)
+// followed by the bit array in native byte order, so the OS pages the bits in
+// and out on demand and the filter survives restarts. Call Flush to persist
+// the count and sync to disk, and Close to unmap.
+func CreateMmap(path string, size uint, numHashes int) (*BloomFilter, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	length := mmapHeaderSize + 8*int64(wordsFor(uint64(size)))
+	if err := f.Truncate(length); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	bf, err := mapFilter(f, length)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	bf.size = uint64(size)
+	bf.numHashes = numHashes
+	bf.hasher = defaultHasher
+	bf.writeMmapHeader()
+	return bf, nil
+}
+
+// OpenMmap maps an existing filter file created by CreateMmap.
+func OpenMmap(path string) (*BloomFilter, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() < mmapHeaderSize {
+		f.Close()
+		return nil, ErrInvalidMmapFile
+	}
+
+	bf, err := mapFilter(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	header := bf.mmap.data[:mmapHeaderSize]
+	bf.size = binary.LittleEndian.Uint64(header[0:8])
+	bf.count.Store(binary.LittleEndian.Uint64(header[8:16]))
+	bf.numHashes = int(binary.LittleEndian.Uint64(header[16:24]))
+	flags := binary.LittleEndian.Uint64(header[24:32])
+	bf.partitioned = flags&mmapFlagPartitioned != 0
+	bf.hasher = mmapHasher(flags)
+	err = checkMmapHeader(bf.size, bf.numHashes, bf.hasher)
+	if err == nil && info.Size() != mmapHeaderSize+8*int64(wordsFor(bf.size)) {
+		err = ErrInvalidMmapFile
+	}
+	if err != nil {
+		// Release without Close, which would rewrite the header.
+		unmap(bf.mmap.data)
+		f.Close()
+		return nil, err
+	}
+	return bf, nil
+}
+
+// checkMmapHeader vets the fields of a filter file header before the filter
+// probes with them.
+func checkMmapHeader(size uint64, numHashes int, hasher Hasher) error {
+	if (DecodeOptions{}).checkBits(size) != nil || numHashes < 1 || numHashes > MaxHashes || hasher == nil {
+		return errInvalidMmapHeader
+	}
+	return nil
+}
+
+func (bf *BloomFilter) writeMmapHeader() {
+	header := bf.mmap.data[:mmapHeaderSize]
+	binary.LittleEndian.PutUint64(header[0:8], bf.size)
+	binary.LittleEndian.PutUint64(header[8:16], bf.count.Load())
+	binary.LittleEndian.PutUint64(header[16:24], uint64(bf.numHashes))
+	var flags uint64
+	if bf.partitioned {
+		flags |= mmapFlagPartitioned
+	}
+	for id, h := range mmapHashers {
+		if h == bf.hasher {
+			flags |= uint64(id) << mmapHasherShift
+		}
+	}
+	binary.LittleEndian.PutUint64(header[24:32], flags)
+}
+
+func mmapHasher(flags uint64) Hasher {
+	if id := flags >> mmapHasherShift & 0xff; id < uint64(len(mmapHashers)) {
+		return mmapHashers[id]
+	}
+	return nil
+}
+
+// Flush writes the current count into the header and syncs the mapping to
+// disk. It does nothing for filters that are not memory-mapped.
+func (bf *BloomFilter) Flush() error {
+	if bf.mmap == nil {
+		return nil
+	}
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	if bf.mmap.shared {
+		// Count is the flushed total plus local adds; publish only the
+		// latter, then pick up everyone else's. Count is below the flushed
+		// total only if it was reset or replaced since.
+		var local uint64
+		if count := bf.count.Load(); count > bf.mmap.flushed {
+			local = count - bf.mmap.flushed
+		}
+		bf.count.Store(bf.sharedCount(local))
+	} else {
+		bf.writeMmapHeader()
+	}
+	return bf.mmap.file.Sync()
+}
+
+// Close flushes and unmaps a memory-mapped filter, which must not be used
+// afterwards. It does nothing for other filters.
+func (bf *BloomFilter) Close() error {
+	if bf.mmap == nil {
+		return nil
+	}
+	err := bf.Flush()
+
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	if uerr := unmap(bf.mmap.data); err == nil {
+		err = uerr
+	}
+	if cerr := bf.mmap.file.Close(); err == nil {
+		err = cerr
+	}
+	bf.bitset = nil
+	bf.mmap = nil
+	return err
+}
@@ -0,0 +1,21 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package bloomfilter
+
+import "os"
+
+func mapFilter(f *os.File, length int64) (*BloomFilter, error) {
+	return nil, ErrMmapUnsupported
+}
+
+func unmap(data []byte) error {
+	return ErrMmapUnsupported
+}
+
+func lockFile(f *os.File) error {
+	return ErrMmapUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return ErrMmapUnsupported
+}
@@ -0,0 +1,117 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Reset clears the count in the file too, so that neither handle publishes a
+// negative delta on its next Flush.
+func TestSharedResetFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared")
+	a, err := OpenShared(path, 1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := OpenShared(path, 1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	for _, item := range []string{"a1", "a2", "a3"} {
+		a.AddString(item)
+	}
+	b.AddString("b1")
+	b.AddString("b2")
+	// a picks up b's adds on its second Flush.
+	flush(t, a, b, a)
+	if a.Count() != 5 || b.Count() != 5 {
+		t.Fatalf("counts %d and %d after Flush, want 5", a.Count(), b.Count())
+	}
+
+	a.Reset()
+	flush(t, a)
+	if a.Count() != 0 {
+		t.Errorf("count %d after Reset and Flush, want 0", a.Count())
+	}
+	if b.ContainsString("b1") {
+		t.Error("Reset did not clear the shared bits")
+	}
+	// A second Reset elsewhere must not take the already cleared count
+	// below zero.
+	b.Reset()
+	flush(t, b, a)
+	if a.Count() != 0 || b.Count() != 0 {
+		t.Errorf("counts %d and %d after both Reset, want 0", a.Count(), b.Count())
+	}
+
+	b.AddString("b3")
+	flush(t, b, a)
+	if a.Count() != 1 || b.Count() != 1 {
+		t.Errorf("counts %d and %d after one more add, want 1", a.Count(), b.Count())
+	}
+}
+
+// A header no filter could have written is refused before the filter probes
+// with it, by OpenMmap and NewDiskFilter alike.
+func TestOpenMmapBadHeader(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name      string
+		size      uint64
+		numHashes uint64
+		flags     uint64
+	}{
+		{"no bits", 0, 3, 0},
+		{"too many bits", math.MaxUint64, 3, 0},
+		{"no hashes", 64, 0, 0},
+		{"too many hashes", 64, MaxHashes + 1, 0},
+		{"negative hashes", 64, math.MaxUint64, 0},
+		{"unknown hasher", 64, 3, 0xff << mmapHasherShift},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			header := binary.LittleEndian.AppendUint64(nil, tc.size)
+			header = binary.LittleEndian.AppendUint64(header, 0)
+			header = binary.LittleEndian.AppendUint64(header, tc.numHashes)
+			header = binary.LittleEndian.AppendUint64(header, tc.flags)
+			path := filepath.Join(dir, tc.name)
+			if err := os.WriteFile(path, append(header, make([]byte, 8)...), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := OpenMmap(path); !errors.Is(err, ErrInvalidFormat) || !errors.Is(err, ErrInvalidMmapFile) {
+				t.Errorf("OpenMmap: got %v, want ErrInvalidFormat", err)
+			}
+			if _, err := OpenDiskFilter(path); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("OpenDiskFilter: got %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+
+	// A sound header over the wrong length is still refused.
+	bf, err := CreateMmap(filepath.Join(dir, "short"), 1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf.Close()
+	if err := os.Truncate(filepath.Join(dir, "short"), mmapHeaderSize+8); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenMmap(filepath.Join(dir, "short")); !errors.Is(err, ErrInvalidMmapFile) {
+		t.Errorf("truncated file: got %v, want ErrInvalidMmapFile", err)
+	}
+}
+
+func flush(t *testing.T, filters ...*BloomFilter) {
+	t.Helper()
+	for _, bf := range filters {
+		if err := bf.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package bloomfilter
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func mapFilter(f *os.File, length int64) (*BloomFilter, error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(length), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	bf := &BloomFilter{mmap: &mmapState{file: f, data: data}}
+	if numWords := (len(data) - mmapHeaderSize) / 8; numWords > 0 {
+		bf.bitset = unsafe.Slice((*uint64)(unsafe.Pointer(&data[mmapHeaderSize])), numWords)
+	}
+	return bf, nil
+}
+
+func unmap(data []byte) error {
+	return syscall.Munmap(data)
+}
+
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,21 @@
+package bloomfilter
+
+// Option configures a filter built by New or NewWithEstimates.
+type Option func(*BloomFilter)
+
+// WithPartitioned splits the bit array into numHashes equal partitions, with
+// the i-th probe of every item confined to the i-th partition. Any bits left
+// over from the division are unused.
+func WithPartitioned() Option {
+	return func(bf *BloomFilter) {
+		bf.partitioned = true
+	}
+}
+
+// WithBackend keeps the bits in backend instead of in memory; see
+// NewWithBackend.
+func WithBackend(backend Backend) Option {
+	return func(bf *BloomFilter) {
+		bf.backend = backend
+	}
+}
@@ -0,0 +1,210 @@
+package bloomfilter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PersistOptions configures a Persister. A filter is saved when either
+// trigger fires; with neither set it is saved only by Save and Close.
+type PersistOptions struct {
+	// Interval saves the filter this often, if anything was added since the
+	// last save.
+	Interval time.Duration
+	// EveryN saves the filter once this many items were added since the
+	// last save. It is checked about once a second.
+	EveryN uint64
+	// Keep is how many snapshot files to retain, the newest first; at least
+	// one is always kept.
+	Keep int
+	// WAL, if set, logs the adds to the filter; each save drops the records
+	// the snapshot covers.
+	WAL *WAL
+}
+
+const (
+	persistPrefix = "bloom-"
+	persistSuffix = ".snapshot"
+)
+
+// Persister saves a filter to a directory in the background. Each save
+// writes a Snapshot, so writers are not held up, to a temporary file that is
+// synced and renamed into place; a crash leaves the previous snapshots
+// intact. LoadLatest reads the newest of them back.
+type Persister struct {
+	bf   *BloomFilter
+	dir  string
+	opts PersistOptions
+
+	mu        sync.Mutex
+	lastCount uint64
+	err       error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPersister starts saving bf to dir, which must exist.
+func NewPersister(bf *BloomFilter, dir string, opts PersistOptions) *Persister {
+	if opts.Keep < 1 {
+		opts.Keep = 1
+	}
+	p := &Persister{
+		bf:        bf,
+		dir:       dir,
+		opts:      opts,
+		lastCount: bf.count.Load(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *Persister) run() {
+	defer close(p.done)
+
+	var interval, check <-chan time.Time
+	if p.opts.Interval > 0 {
+		t := time.NewTicker(p.opts.Interval)
+		defer t.Stop()
+		interval = t.C
+	}
+	if p.opts.EveryN > 0 {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		check = t.C
+	}
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-interval:
+			if p.bf.count.Load() != p.saved() {
+				p.background()
+			}
+		case <-check:
+			// A count below the last save's means the filter was reset
+			// or replaced since, which is worth saving too.
+			if count, saved := p.bf.count.Load(), p.saved(); count < saved || count-saved >= p.opts.EveryN {
+				p.background()
+			}
+		}
+	}
+}
+
+func (p *Persister) saved() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastCount
+}
+
+func (p *Persister) background() {
+	if err := p.Save(); err != nil {
+		p.mu.Lock()
+		p.err = err
+		p.mu.Unlock()
+	}
+}
+
+// Err returns the most recent error from a background save, if any.
+func (p *Persister) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Save writes a snapshot now and prunes old ones.
+func (p *Persister) Save() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var mark int64
+	if p.opts.WAL != nil {
+		mark = p.opts.WAL.mark()
+	}
+	s := p.bf.Snapshot()
+	if s == nil {
+		return p.bf.Err()
+	}
+	defer s.Close()
+
+	name := fmt.Sprintf("%s%020d%s", persistPrefix, time.Now().UnixNano(), persistSuffix)
+	if err := writeFileAtomic(filepath.Join(p.dir, name), func(w io.Writer) error {
+		_, err := s.WriteTo(w)
+		return err
+	}); err != nil {
+		return err
+	}
+	p.lastCount = s.h.count
+	if p.opts.WAL != nil {
+		if err := p.opts.WAL.discardTo(mark); err != nil {
+			return err
+		}
+	}
+
+	snapshots, err := listSnapshots(p.dir)
+	if err != nil {
+		return err
+	}
+	for _, old := range snapshots[min(p.opts.Keep, len(snapshots)):] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the background saves and saves once more.
+func (p *Persister) Close() error {
+	close(p.stop)
+	<-p.done
+	return p.Save()
+}
+
+// LoadLatest reads the newest snapshot a Persister left in dir, falling back
+// to older ones if it is unreadable. It returns os.ErrNotExist if there are
+// none.
+func LoadLatest(dir string, o DecodeOptions) (*BloomFilter, error) {
+	snapshots, err := listSnapshots(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, os.ErrNotExist
+	}
+	var errs []error
+	for _, path := range snapshots {
+		bf, err := o.LoadFile(path)
+		if err == nil {
+			return bf, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", path, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// listSnapshots returns the snapshot files in dir, newest first.
+func listSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []string
+	for _, e := range entries {
+		if name := e.Name(); strings.HasPrefix(name, persistPrefix) && strings.HasSuffix(name, persistSuffix) {
+			snapshots = append(snapshots, filepath.Join(dir, name))
+		}
+	}
+	// The names embed a fixed-width timestamp, so they sort by age.
+	slices.Sort(snapshots)
+	slices.Reverse(snapshots)
+	return snapshots, nil
+}
@@ -0,0 +1,45 @@
+package bloomfilter
+
+import "sync"
+
+// Pool recycles filters of one shape for short-lived uses, such as one per
+// request or batch, so that their bit arrays are reused instead of
+// allocated and collected each time. It is safe for concurrent use.
+type Pool struct {
+	pool      sync.Pool
+	size      uint64
+	numHashes int
+}
+
+// NewPool returns a pool of filters made by New with these arguments.
+func NewPool(size uint, numHashes int, opts ...Option) *Pool {
+	p := &Pool{size: uint64(size), numHashes: numHashes}
+	p.pool.New = func() any {
+		return New64(p.size, numHashes, opts...)
+	}
+	return p
+}
+
+// NewPoolWithEstimates returns a pool of filters sized like
+// NewWithEstimates.
+func NewPoolWithEstimates(expectedElements uint, fpRate float64, opts ...Option) *Pool {
+	size, numHashes := EstimateParameters(expectedElements, fpRate)
+	return NewPool(size, numHashes, opts...)
+}
+
+// Get returns an empty filter, reused if one is available.
+func (p *Pool) Get() *BloomFilter {
+	return p.pool.Get().(*BloomFilter)
+}
+
+// Put clears bf in place and returns it to the pool; bf must not be used
+// afterwards. Filters of another shape, or not held in memory, are left
+// for the garbage collector.
+func (p *Pool) Put(bf *BloomFilter) {
+	if bf == nil || bf.size != p.size || bf.numHashes != p.numHashes || bf.backend != nil || bf.mmap != nil {
+		return
+	}
+	bf.Reset()
+	bf.ResetStats()
+	p.pool.Put(bf)
+}
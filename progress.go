@@ -0,0 +1,141 @@
+package bloomfilter
+
+import (
+	"io"
+	"os"
+)
+
+// Progress asks a long-running operation to report how far it has got, so
+// that a frontend can draw a progress bar.
+type Progress struct {
+	// Every is how many items, or bytes, pass between calls to Func. By
+	// default it is 64 Ki items or 1 MiB.
+	Every int64
+	// Func receives the items or bytes done so far and the total, or -1
+	// when the total is not known up front. It is called once more when
+	// the operation finishes.
+	Func func(done, total int64)
+}
+
+// progressTracker counts work done and calls Func each time another Every
+// units have passed.
+type progressTracker struct {
+	p          Progress
+	done, next int64
+	reported   int64
+	total      int64
+}
+
+func (p Progress) track(total, every int64) *progressTracker {
+	if p.Func == nil {
+		return nil
+	}
+	if p.Every > 0 {
+		every = p.Every
+	}
+	p.Every = every
+	return &progressTracker{p: p, next: every, reported: -1, total: total}
+}
+
+func (t *progressTracker) advance(n int64) {
+	if t == nil {
+		return
+	}
+	t.done += n
+	if t.done >= t.next {
+		t.report()
+		t.next = t.done + t.p.Every
+	}
+}
+
+// finish makes the final report, unless the last one already covered
+// everything.
+func (t *progressTracker) finish() {
+	if t != nil && t.reported != t.done {
+		t.report()
+	}
+}
+
+func (t *progressTracker) report() {
+	t.reported = t.done
+	t.p.Func(t.done, t.total)
+}
+
+type progressWriter struct {
+	w io.Writer
+	t *progressTracker
+}
+
+func (pw progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.t.advance(int64(n))
+	return n, err
+}
+
+type progressReader struct {
+	r io.Reader
+	t *progressTracker
+}
+
+func (pr progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.t.advance(int64(n))
+	return n, err
+}
+
+// AddManyProgress is AddMany in batches of p.Every items, reporting after
+// each one.
+func (bf *BloomFilter) AddManyProgress(items [][]byte, p Progress) {
+	t := p.track(int64(len(items)), 64<<10)
+	if t == nil {
+		bf.AddMany(items)
+		return
+	}
+	for len(items) > 0 {
+		n := int(min(int64(len(items)), t.p.Every))
+		bf.AddMany(items[:n])
+		t.advance(int64(n))
+		items = items[n:]
+	}
+	t.finish()
+}
+
+// WriteToProgress is WriteTo, reporting the bytes written out of the total
+// the filter encodes to.
+func (bf *BloomFilter) WriteToProgress(w io.Writer, p Progress) (int64, error) {
+	h := bf.header()
+	t := p.track(int64(h.encodedLen())+8*int64(wordsFor(bf.size))+4, 1<<20)
+	if t == nil {
+		return bf.WriteTo(w)
+	}
+	n, err := bf.WriteTo(progressWriter{w, t})
+	if err == nil {
+		t.finish()
+	}
+	return n, err
+}
+
+// SaveFileProgress is SaveFile, reporting as WriteToProgress does.
+func (bf *BloomFilter) SaveFileProgress(path string, p Progress) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := bf.WriteToProgress(w, p)
+		return err
+	})
+}
+
+// decodeProgress wraps r to report o.Progress as it is read.
+func (o DecodeOptions) decodeProgress(r io.Reader, total int64) (io.Reader, *progressTracker) {
+	t := o.Progress.track(total, 1<<20)
+	if t == nil {
+		return r, nil
+	}
+	return progressReader{r, t}, t
+}
+
+// fileSize is the size of f, or -1 if it cannot be found.
+func fileSize(f *os.File) int64 {
+	if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+		return fi.Size()
+	}
+	return -1
+}
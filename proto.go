@@ -0,0 +1,64 @@
+package bloomfilter
+
+import (
+	"sync/atomic"
+
+	"github.com/hriday-13th/bloom-filter/bloompb"
+)
+
+// ToProto converts the filter to its protobuf message. It returns nil if the
+// bits cannot be read from the backend; see Err.
+func (bf *BloomFilter) ToProto() *bloompb.BloomFilter {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	words, err := bf.words()
+	if err != nil {
+		bf.setErr(err)
+		return nil
+	}
+
+	h := bf.header()
+	m := &bloompb.BloomFilter{
+		Hasher:      h.hasher,
+		Seeds:       h.seeds,
+		Size:        h.size,
+		NumHashes:   h.numHashes,
+		Partitioned: bf.partitioned,
+		Count:       h.count,
+		Words:       make([]uint64, len(words)),
+	}
+	for i := range words {
+		m.Words[i] = atomic.LoadUint64(&words[i])
+	}
+	return m
+}
+
+// FromProto builds a filter from a message produced by ToProto.
+func FromProto(m *bloompb.BloomFilter) (*BloomFilter, error) {
+	h := header{
+		hasher:    m.GetHasher(),
+		numHashes: m.GetNumHashes(),
+		size:      m.GetSize(),
+		count:     m.GetCount(),
+		seeds:     m.GetSeeds(),
+	}
+	if m.GetPartitioned() {
+		h.flags |= formatFlagPartitioned
+	}
+	if uint64(len(m.GetWords())) != uint64(wordsFor(h.size)) {
+		return nil, ErrInvalidFormat
+	}
+
+	bf, err := h.newFilter(DecodeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// The length check above vouches for the size.
+	bf.bitset = make([]uint64, wordsFor(h.size))
+	copy(bf.bitset, m.GetWords())
+	if len(bf.bitset) > 0 {
+		bf.bitset[len(bf.bitset)-1] &= lastWordMask(bf.size)
+	}
+	return bf, nil
+}
@@ -0,0 +1,373 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"sync"
+)
+
+const (
+	qfOccupied     = 1 << 0
+	qfContinuation = 1 << 1
+	qfShifted      = 1 << 2
+	qfMetadata     = qfOccupied | qfContinuation | qfShifted
+
+	// qfMaxLoad is the fill ratio at which Add grows the table.
+	qfMaxLoad = 0.75
+)
+
+var (
+	ErrQuotientFull     = errors.New("bloomfilter: quotient filter cannot grow any further")
+	ErrQuotientMismatch = errors.New("bloomfilter: quotient filters have different fingerprint sizes")
+)
+
+// QuotientFilter stores a p-bit fingerprint of every item, split into a q-bit
+// quotient that selects a slot and an r-bit remainder kept in it. Because the
+// full fingerprint can be rebuilt from the table, the filter can be resized
+// and merged without access to the original keys: each doubling moves one bit
+// from the remainder to the quotient, so the false-positive rate roughly
+// doubles too.
+type QuotientFilter struct {
+	mu      sync.RWMutex
+	slots   []uint64
+	qBits   uint
+	rBits   uint
+	entries uint
+}
+
+// NewQuotient returns a filter with 2^qBits slots holding rBits-bit
+// remainders. qBits+rBits may not exceed 64.
+func NewQuotient(qBits, rBits uint) *QuotientFilter {
+	if qBits < 1 || rBits < 1 || qBits+rBits > 64 || rBits > 61 {
+		panic("bloomfilter: invalid quotient filter dimensions")
+	}
+	return &QuotientFilter{
+		slots: make([]uint64, 1<<qBits),
+		qBits: qBits,
+		rBits: rBits,
+	}
+}
+
+func (qf *QuotientFilter) fingerprint(item []byte) uint64 {
+	h, _ := hash128(item)
+	return h & lowMask(qf.qBits+qf.rBits)
+}
+
+func lowMask(n uint) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<n - 1
+}
+
+// Add inserts item and reports whether it was stored. When the table passes
+// its load limit it is doubled first; Add only fails once the remainder has
+// been shrunk to a single bit and the table is full.
+func (qf *QuotientFilter) Add(item []byte) bool {
+	qf.mu.Lock()
+	defer qf.mu.Unlock()
+
+	if float64(qf.entries+1) > qfMaxLoad*float64(len(qf.slots)) && qf.rBits > 1 {
+		qf.resize()
+	}
+	if qf.entries == uint(len(qf.slots)) {
+		return false
+	}
+	qf.insert(qf.fingerprint(item))
+	return true
+}
+
+func (qf *QuotientFilter) Contains(item []byte) bool {
+	qf.mu.RLock()
+	defer qf.mu.RUnlock()
+
+	fp := qf.fingerprint(item)
+	fq, fr := fp>>qf.rBits, fp&lowMask(qf.rBits)
+	if qf.slots[fq]&qfOccupied == 0 {
+		return false
+	}
+
+	s := qf.findRun(fq)
+	for {
+		rem := qf.slots[s] >> 3
+		if rem == fr {
+			return true
+		}
+		if rem > fr {
+			return false
+		}
+		s = qf.incr(s)
+		if qf.slots[s]&qfContinuation == 0 {
+			return false
+		}
+	}
+}
+
+func (qf *QuotientFilter) Count() uint {
+	qf.mu.RLock()
+	defer qf.mu.RUnlock()
+	return qf.entries
+}
+
+// Resize doubles the number of slots in place, moving one bit of every
+// fingerprint from its remainder to its quotient.
+func (qf *QuotientFilter) Resize() error {
+	qf.mu.Lock()
+	defer qf.mu.Unlock()
+
+	if qf.rBits <= 1 {
+		return ErrQuotientFull
+	}
+	qf.resize()
+	return nil
+}
+
+func (qf *QuotientFilter) resize() {
+	fingerprints := qf.fingerprints()
+	qf.qBits++
+	qf.rBits--
+	qf.slots = make([]uint64, 1<<qf.qBits)
+	qf.entries = 0
+	for _, fp := range fingerprints {
+		qf.insert(fp)
+	}
+}
+
+// MergeQuotient returns a new filter holding the fingerprints of both a and
+// b, which must use the same fingerprint size. The result is sized to stay
+// under the load limit.
+func MergeQuotient(a, b *QuotientFilter) (*QuotientFilter, error) {
+	defer rlockPair(&a.mu, &b.mu)()
+
+	p := a.qBits + a.rBits
+	if p != b.qBits+b.rBits {
+		return nil, ErrQuotientMismatch
+	}
+
+	q := max(a.qBits, b.qBits)
+	for float64(a.entries+b.entries) > qfMaxLoad*float64(uint64(1)<<q) && p-q > 1 {
+		q++
+	}
+	if a.entries+b.entries > uint(1)<<q {
+		return nil, ErrQuotientFull
+	}
+
+	merged := NewQuotient(q, p-q)
+	for _, fp := range a.fingerprints() {
+		merged.insert(fp)
+	}
+	for _, fp := range b.fingerprints() {
+		merged.insert(fp)
+	}
+	return merged, nil
+}
+
+func (qf *QuotientFilter) incr(i uint64) uint64 {
+	return (i + 1) & lowMask(qf.qBits)
+}
+
+func (qf *QuotientFilter) decr(i uint64) uint64 {
+	return (i - 1) & lowMask(qf.qBits)
+}
+
+// findRun returns the slot where the run of remainders for quotient fq
+// starts, by walking back to the start of its cluster and then forward
+// run by run.
+func (qf *QuotientFilter) findRun(fq uint64) uint64 {
+	b := fq
+	for qf.slots[b]&qfShifted != 0 {
+		b = qf.decr(b)
+	}
+
+	s := b
+	for b != fq {
+		for {
+			s = qf.incr(s)
+			if qf.slots[s]&qfContinuation == 0 {
+				break
+			}
+		}
+		for {
+			b = qf.incr(b)
+			if qf.slots[b]&qfOccupied != 0 {
+				break
+			}
+		}
+	}
+	return s
+}
+
+// insert adds fp, keeping each run sorted by remainder. Duplicate
+// fingerprints are stored once. The caller guarantees a free slot.
+func (qf *QuotientFilter) insert(fp uint64) {
+	fq, fr := fp>>qf.rBits, fp&lowMask(qf.rBits)
+	canonical := qf.slots[fq]
+	entry := fr << 3
+
+	if canonical&qfMetadata == 0 {
+		qf.slots[fq] = entry | qfOccupied
+		qf.entries++
+		return
+	}
+	qf.slots[fq] |= qfOccupied
+
+	start := qf.findRun(fq)
+	s := start
+	if canonical&qfOccupied != 0 {
+		for {
+			rem := qf.slots[s] >> 3
+			if rem == fr {
+				return
+			}
+			if rem > fr {
+				break
+			}
+			s = qf.incr(s)
+			if qf.slots[s]&qfContinuation == 0 {
+				break
+			}
+		}
+		if s == start {
+			qf.slots[start] |= qfContinuation
+		} else {
+			entry |= qfContinuation
+		}
+	}
+	if s != fq {
+		entry |= qfShifted
+	}
+
+	qf.shiftInsert(s, entry)
+	qf.entries++
+}
+
+// shiftInsert places entry at slot s and shifts the following entries right
+// up to the next empty slot. Occupied bits belong to slots rather than
+// entries, so they stay where they are.
+func (qf *QuotientFilter) shiftInsert(s, entry uint64) {
+	curr := entry
+	for {
+		prev := qf.slots[s]
+		empty := prev&qfMetadata == 0
+		if !empty {
+			prev |= qfShifted
+			if prev&qfOccupied != 0 {
+				curr |= qfOccupied
+				prev &^= qfOccupied
+			}
+		}
+		qf.slots[s] = curr
+		if empty {
+			return
+		}
+		curr = prev
+		s = qf.incr(s)
+	}
+}
+
+// fingerprints rebuilds every stored fingerprint by walking the table from
+// the start of a cluster and tracking which quotient each run belongs to.
+func (qf *QuotientFilter) fingerprints() []uint64 {
+	fingerprints := make([]uint64, 0, qf.entries)
+	if qf.entries == 0 {
+		return fingerprints
+	}
+
+	size := uint64(len(qf.slots))
+	start := uint64(0)
+	for start < size {
+		elt := qf.slots[start]
+		if elt&qfOccupied != 0 && elt&(qfContinuation|qfShifted) == 0 {
+			break
+		}
+		start++
+	}
+
+	quotient := start
+	index := start
+	for visited := uint64(0); visited < size; visited++ {
+		elt := qf.slots[index]
+		switch {
+		case elt&qfOccupied != 0 && elt&(qfContinuation|qfShifted) == 0:
+			quotient = index
+		case elt&qfContinuation == 0 && elt&(qfOccupied|qfShifted) != 0:
+			for {
+				quotient = qf.incr(quotient)
+				if qf.slots[quotient]&qfOccupied != 0 {
+					break
+				}
+			}
+		}
+		if elt&qfMetadata != 0 {
+			fingerprints = append(fingerprints, quotient<<qf.rBits|elt>>3)
+		}
+		index = qf.incr(index)
+	}
+	return fingerprints
+}
+
+func (qf *QuotientFilter) Serialize() []byte {
+	qf.mu.RLock()
+	defer qf.mu.RUnlock()
+
+	serialized := make([]byte, 24+8*len(qf.slots))
+	binary.LittleEndian.PutUint64(serialized[0:8], uint64(qf.qBits))
+	binary.LittleEndian.PutUint64(serialized[8:16], uint64(qf.rBits))
+	binary.LittleEndian.PutUint64(serialized[16:24], uint64(qf.entries))
+	for i, slot := range qf.slots {
+		binary.LittleEndian.PutUint64(serialized[24+8*i:], slot)
+	}
+	return serialized
+}
+
+// DeserializeQuotient accepts only a table that inserting its own
+// fingerprints into an empty one reproduces: lookups walk runs and clusters
+// by their metadata bits, and would not terminate on a malformed table.
+func DeserializeQuotient(data []byte) (*QuotientFilter, error) {
+	if len(data) < 24 {
+		return nil, ErrInvalidFormat
+	}
+	qBits := binary.LittleEndian.Uint64(data[0:8])
+	rBits := binary.LittleEndian.Uint64(data[8:16])
+	if qBits < 1 || rBits < 1 || qBits > 64 || rBits > 61 || qBits+rBits > 64 ||
+		uint64(len(data)-24)/8 != 1<<qBits || len(data)%8 != 0 {
+		return nil, ErrInvalidFormat
+	}
+	qf := &QuotientFilter{
+		slots:   make([]uint64, 1<<qBits),
+		qBits:   uint(qBits),
+		rBits:   uint(rBits),
+		entries: uint(binary.LittleEndian.Uint64(data[16:24])),
+	}
+
+	// fingerprints needs remainders that fit and, unless the table is
+	// empty, a slot starting a cluster.
+	var used uint
+	clustered := false
+	for i := range qf.slots {
+		slot := binary.LittleEndian.Uint64(data[24+8*i:])
+		if slot>>3 > lowMask(qf.rBits) || slot&qfMetadata == 0 && slot != 0 {
+			return nil, ErrInvalidFormat
+		}
+		if slot&qfMetadata != 0 {
+			used++
+		}
+		if slot&qfMetadata == qfOccupied {
+			clustered = true
+		}
+		qf.slots[i] = slot
+	}
+	if used != qf.entries || used > 0 && !clustered {
+		return nil, ErrInvalidFormat
+	}
+
+	rebuilt := NewQuotient(qf.qBits, qf.rBits)
+	for _, fp := range qf.fingerprints() {
+		rebuilt.insert(fp)
+	}
+	if rebuilt.entries != qf.entries || !slices.Equal(rebuilt.slots, qf.slots) {
+		return nil, ErrInvalidFormat
+	}
+	return qf, nil
+}
@@ -0,0 +1,128 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestQuotientRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 48, 1000} {
+		qf := NewQuotient(6, 10)
+		for i := 0; i < n; i++ {
+			qf.Add([]byte(strconv.Itoa(i)))
+		}
+		decoded, err := DeserializeQuotient(qf.Serialize())
+		if err != nil {
+			t.Fatalf("%d items: %v", n, err)
+		}
+		if decoded.Count() != qf.Count() {
+			t.Errorf("%d items: Count = %d, want %d", n, decoded.Count(), qf.Count())
+		}
+		for i := 0; i < n; i++ {
+			if !decoded.Contains([]byte(strconv.Itoa(i))) {
+				t.Fatalf("%d items: decoded filter lacks %d", n, i)
+			}
+		}
+	}
+}
+
+func TestDeserializeQuotientInvalid(t *testing.T) {
+	qf := NewQuotient(4, 8)
+	for i := 0; i < 10; i++ {
+		qf.Add([]byte(strconv.Itoa(i)))
+	}
+	valid := qf.Serialize()
+	header := func(q, r uint64) []byte {
+		data := append([]byte(nil), valid...)
+		binary.LittleEndian.PutUint64(data[0:], q)
+		binary.LittleEndian.PutUint64(data[8:], r)
+		return data
+	}
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", valid[:23]},
+		{"truncated", valid[:len(valid)-8]},
+		{"zero q", header(0, 8)},
+		{"zero r", header(4, 0)},
+		{"q too large", header(64, 1)},
+		{"fingerprint over 64 bits", header(4, 61)},
+		{"r past 61", header(1, 62)},
+		{"wrong slot count", header(5, 8)},
+		{"wrong entry count", func() []byte {
+			data := append([]byte(nil), valid...)
+			binary.LittleEndian.PutUint64(data[16:], 3)
+			return data
+		}()},
+		{"every slot shifted", func() []byte {
+			data := append([]byte(nil), valid...)
+			binary.LittleEndian.PutUint64(data[16:], 16)
+			for i := 0; i < 16; i++ {
+				binary.LittleEndian.PutUint64(data[24+8*i:], 1<<3|qfShifted|qfContinuation)
+			}
+			return data
+		}()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DeserializeQuotient(tc.data); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("got %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+}
+
+// A damaged table is either rejected or decodes to one that lookups and
+// inserts can walk.
+func TestDeserializeQuotientDamaged(t *testing.T) {
+	qf := NewQuotient(5, 8)
+	for i := 0; i < 20; i++ {
+		qf.Add([]byte(strconv.Itoa(i)))
+	}
+	valid := qf.Serialize()
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 5000 {
+		data := append([]byte(nil), valid...)
+		for range 1 + r.IntN(3) {
+			i := 24 + r.IntN(len(data)-24)
+			data[i] ^= 1 << r.IntN(8)
+		}
+		if decoded, err := DeserializeQuotient(data); err == nil {
+			for i := 0; i < 40; i++ {
+				decoded.Contains([]byte(strconv.Itoa(i)))
+				decoded.Add([]byte("x" + strconv.Itoa(i)))
+			}
+		}
+	}
+}
+
+// MergeQuotient locks its arguments in address order whichever way round
+// they come, so merges of a pair in opposite orders cannot deadlock behind a
+// waiting writer.
+func TestMergeQuotientLockOrder(t *testing.T) {
+	lo, hi := NewQuotient(4, 8), NewQuotient(4, 8)
+	if uintptr(unsafe.Pointer(lo)) > uintptr(unsafe.Pointer(hi)) {
+		lo, hi = hi, lo
+	}
+
+	lo.mu.Lock()
+	done := make(chan error)
+	go func() {
+		_, err := MergeQuotient(hi, lo)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if !hi.mu.TryLock() {
+		t.Fatal("MergeQuotient holds hi while waiting for lo")
+	}
+	hi.mu.Unlock()
+	lo.mu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
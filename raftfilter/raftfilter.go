@@ -0,0 +1,190 @@
+// Package raftfilter replicates a filter with hashicorp/raft for deployments
+// that need every replica to agree: adds are committed to the Raft log and
+// applied by each replica in log order, and Raft snapshots are the filter's
+// serialization.
+package raftfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hashicorp/raft"
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+const (
+	opAdd byte = iota + 1
+	opAddMany
+	opReset
+)
+
+var ErrInvalidCommand = errors.New("raftfilter: invalid log command")
+
+// EncodeAdd, EncodeAddMany and EncodeReset build the log commands FSM
+// applies, for callers that submit them to Raft themselves.
+func EncodeAdd(item []byte) []byte {
+	return append([]byte{opAdd}, item...)
+}
+
+func EncodeAddMany(items [][]byte) []byte {
+	cmd := binary.AppendUvarint([]byte{opAddMany}, uint64(len(items)))
+	for _, item := range items {
+		cmd = binary.AppendUvarint(cmd, uint64(len(item)))
+		cmd = append(cmd, item...)
+	}
+	return cmd
+}
+
+func EncodeReset() []byte {
+	return []byte{opReset}
+}
+
+// FSM is a raft.FSM applying log commands to a filter. Pass it to
+// raft.NewRaft; every replica's filter must start out with the same shape,
+// hasher and seed.
+type FSM struct {
+	bf *bloomfilter.BloomFilter
+}
+
+func NewFSM(bf *bloomfilter.BloomFilter) *FSM {
+	return &FSM{bf: bf}
+}
+
+// Filter returns the replica's filter, for reads. Writing to it directly
+// makes the replicas diverge.
+func (f *FSM) Filter() *bloomfilter.BloomFilter {
+	return f.bf
+}
+
+// Apply applies one command. It returns nil, or an error for a command it
+// does not understand.
+func (f *FSM) Apply(l *raft.Log) any {
+	if l.Type != raft.LogCommand || len(l.Data) == 0 {
+		return nil
+	}
+	switch cmd := l.Data[1:]; l.Data[0] {
+	case opAdd:
+		f.bf.Add(cmd)
+	case opAddMany:
+		items, err := decodeItems(cmd)
+		if err != nil {
+			return err
+		}
+		f.bf.AddMany(items)
+	case opReset:
+		f.bf.Reset()
+	default:
+		return ErrInvalidCommand
+	}
+	return nil
+}
+
+func decodeItems(cmd []byte) ([][]byte, error) {
+	n, k := binary.Uvarint(cmd)
+	if k <= 0 || n > uint64(len(cmd)) {
+		return nil, ErrInvalidCommand
+	}
+	cmd = cmd[k:]
+	items := make([][]byte, n)
+	for i := range items {
+		size, k := binary.Uvarint(cmd)
+		if k <= 0 || size > uint64(len(cmd)-k) {
+			return nil, ErrInvalidCommand
+		}
+		items[i], cmd = cmd[k:k+int(size)], cmd[k+int(size):]
+	}
+	return items, nil
+}
+
+// Snapshot takes a copy-on-write Snapshot of the filter, so adds carry on
+// while Raft persists it.
+func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
+	snap := f.bf.Snapshot()
+	if snap == nil {
+		return nil, f.bf.Err()
+	}
+	return fsmSnapshot{snap}, nil
+}
+
+// Restore replaces the filter with a snapshot, as ReadFrom does.
+func (f *FSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+	_, err := f.bf.ReadFrom(rc)
+	return err
+}
+
+type fsmSnapshot struct {
+	snap *bloomfilter.Snapshot
+}
+
+func (s fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if _, err := s.snap.WriteTo(sink); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s fsmSnapshot) Release() {
+	s.snap.Close()
+}
+
+// Filter submits writes to Raft and reads from the local replica. Writes
+// must go to the leader; on a follower they fail with raft.ErrNotLeader.
+type Filter struct {
+	r   *raft.Raft
+	fsm *FSM
+	// Timeout bounds how long a write waits to be enqueued. Zero waits
+	// indefinitely.
+	Timeout time.Duration
+}
+
+// New returns a Filter over r, which must have been created with fsm.
+func New(r *raft.Raft, fsm *FSM) *Filter {
+	return &Filter{r: r, fsm: fsm, Timeout: 5 * time.Second}
+}
+
+// apply commits cmd and waits until this replica has applied it.
+func (f *Filter) apply(cmd []byte) error {
+	future := f.r.Apply(cmd, f.Timeout)
+	if err := future.Error(); err != nil {
+		return err
+	}
+	if err, ok := future.Response().(error); ok {
+		return fmt.Errorf("raftfilter: apply: %w", err)
+	}
+	return nil
+}
+
+func (f *Filter) Add(item []byte) error {
+	return f.apply(EncodeAdd(item))
+}
+
+func (f *Filter) AddMany(items [][]byte) error {
+	return f.apply(EncodeAddMany(items))
+}
+
+func (f *Filter) Reset() error {
+	return f.apply(EncodeReset())
+}
+
+// Contains answers from the local replica, which on a follower may lag
+// behind the leader.
+func (f *Filter) Contains(item []byte) bool {
+	return f.fsm.bf.Contains(item)
+}
+
+// ContainsConsistent answers on the leader after confirming it still
+// leads, so it sees every add committed before the call.
+func (f *Filter) ContainsConsistent(item []byte) (bool, error) {
+	if err := f.r.VerifyLeader().Error(); err != nil {
+		return false, err
+	}
+	if err := f.r.Barrier(f.Timeout).Error(); err != nil {
+		return false, err
+	}
+	return f.fsm.bf.Contains(item), nil
+}
@@ -0,0 +1,259 @@
+package raftfilter
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+func newFilter() *bloomfilter.BloomFilter {
+	return bloomfilter.New(1<<16, 5)
+}
+
+func apply(fsm *FSM, cmd []byte) any {
+	return fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmd})
+}
+
+func TestFSMApply(t *testing.T) {
+	fsm := NewFSM(newFilter())
+	for _, cmd := range [][]byte{
+		EncodeAdd([]byte("a")),
+		EncodeAddMany([][]byte{[]byte("b"), []byte("c"), {}}),
+	} {
+		if res := apply(fsm, cmd); res != nil {
+			t.Fatalf("Apply(%x) = %v", cmd, res)
+		}
+	}
+	for _, item := range []string{"a", "b", "c", ""} {
+		if !fsm.Filter().ContainsString(item) {
+			t.Errorf("%q missing after Apply", item)
+		}
+	}
+	if n := fsm.Filter().Count(); n != 4 {
+		t.Errorf("count %d, want 4", n)
+	}
+
+	// Logs other than commands, such as configuration changes, are skipped.
+	if res := fsm.Apply(&raft.Log{Type: raft.LogConfiguration, Data: []byte{0xff}}); res != nil {
+		t.Errorf("Apply of a configuration log = %v", res)
+	}
+
+	many := EncodeAddMany([][]byte{[]byte("item")})
+	for name, cmd := range map[string][]byte{
+		"unknown op":       {0xff},
+		"truncated item":   many[:len(many)-1],
+		"huge count":       {opAddMany, 0xff, 0xff, 0xff, 0xff, 0x0f},
+		"missing count":    {opAddMany},
+		"huge item length": {opAddMany, 1, 0xff, 0x0f},
+	} {
+		if res := apply(fsm, cmd); res != ErrInvalidCommand {
+			t.Errorf("%s: Apply = %v, want ErrInvalidCommand", name, res)
+		}
+	}
+	if n := fsm.Filter().Count(); n != 4 {
+		t.Errorf("count %d after invalid commands, want 4", n)
+	}
+
+	if res := apply(fsm, EncodeReset()); res != nil {
+		t.Fatalf("Apply(reset) = %v", res)
+	}
+	if fsm.Filter().ContainsString("a") || fsm.Filter().Count() != 0 {
+		t.Error("filter not empty after reset")
+	}
+}
+
+// A snapshot holds the filter as it was when taken, and restores into a
+// fresh replica.
+func TestFSMSnapshotRestore(t *testing.T) {
+	fsm := NewFSM(newFilter())
+	for i := 0; i < 100; i++ {
+		apply(fsm, EncodeAdd([]byte(strconv.Itoa(i))))
+	}
+	snap, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snap.Release()
+	apply(fsm, EncodeAdd([]byte("after")))
+
+	store := raft.NewInmemSnapshotStore()
+	sink, err := store.Create(raft.SnapshotVersionMax, 100, 1, raft.Configuration{}, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snap.Persist(sink); err != nil {
+		t.Fatal(err)
+	}
+	_, rc, err := store.Open(sink.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewFSM(newFilter())
+	if err := restored.Restore(rc); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if !restored.Filter().ContainsString(strconv.Itoa(i)) {
+			t.Fatalf("item %d missing after Restore", i)
+		}
+	}
+	if restored.Filter().ContainsString("after") || restored.Filter().Count() != 100 {
+		t.Error("the snapshot holds an add made after it was taken")
+	}
+
+	if err := restored.Restore(io.NopCloser(errReader{})); err == nil {
+		t.Error("Restore from a failing reader succeeded")
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+type replica struct {
+	r     *raft.Raft
+	fsm   *FSM
+	f     *Filter
+	trans *raft.InmemTransport
+}
+
+func startReplica(t *testing.T, id string) *replica {
+	t.Helper()
+	conf := raft.DefaultConfig()
+	conf.LocalID = raft.ServerID(id)
+	conf.HeartbeatTimeout = 50 * time.Millisecond
+	conf.ElectionTimeout = 50 * time.Millisecond
+	conf.LeaderLeaseTimeout = 50 * time.Millisecond
+	conf.CommitTimeout = 5 * time.Millisecond
+	// Keep no log behind a snapshot, so a replica that joins later can only
+	// catch up from one.
+	conf.TrailingLogs = 0
+	conf.LogOutput = io.Discard
+
+	_, trans := raft.NewInmemTransport(raft.ServerAddress(id))
+	store := raft.NewInmemStore()
+	fsm := NewFSM(newFilter())
+	r, err := raft.NewRaft(conf, fsm, store, store, raft.NewInmemSnapshotStore(), trans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Shutdown().Error() })
+	return &replica{r: r, fsm: fsm, f: New(r, fsm), trans: trans}
+}
+
+// startCluster bootstraps n replicas over in-memory transports and returns
+// them with the leader first.
+func startCluster(t *testing.T, n int) []*replica {
+	t.Helper()
+	var reps []*replica
+	var servers []raft.Server
+	for i := range n {
+		rep := startReplica(t, "node"+strconv.Itoa(i))
+		for _, other := range reps {
+			connect(rep, other)
+		}
+		reps = append(reps, rep)
+		servers = append(servers, raft.Server{ID: raft.ServerID(rep.trans.LocalAddr()), Address: rep.trans.LocalAddr()})
+	}
+	if err := reps[0].r.BootstrapCluster(raft.Configuration{Servers: servers}).Error(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "a leader", func() bool {
+		for i, rep := range reps {
+			if rep.r.State() == raft.Leader {
+				reps[0], reps[i] = reps[i], reps[0]
+				return true
+			}
+		}
+		return false
+	})
+	return reps
+}
+
+func connect(a, b *replica) {
+	a.trans.Connect(b.trans.LocalAddr(), b.trans)
+	b.trans.Connect(a.trans.LocalAddr(), a.trans)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+// Adds on the leader reach every replica; followers refuse writes.
+func TestCluster(t *testing.T) {
+	reps := startCluster(t, 3)
+	leader := reps[0]
+	if err := leader.f.Add([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := leader.f.AddMany([][]byte{[]byte("b"), []byte("c")}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := leader.f.ContainsConsistent([]byte("c")); err != nil || !ok {
+		t.Errorf("ContainsConsistent = %t, %v; want true", ok, err)
+	}
+	for _, rep := range reps[1:] {
+		waitFor(t, "the adds on a follower", func() bool {
+			return rep.f.Contains([]byte("a")) && rep.f.Contains([]byte("c"))
+		})
+		if err := rep.f.Add([]byte("d")); !errors.Is(err, raft.ErrNotLeader) {
+			t.Errorf("Add on a follower: got %v, want raft.ErrNotLeader", err)
+		}
+		if _, err := rep.f.ContainsConsistent([]byte("a")); !errors.Is(err, raft.ErrNotLeader) {
+			t.Errorf("ContainsConsistent on a follower: got %v, want raft.ErrNotLeader", err)
+		}
+	}
+
+	if err := leader.f.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	for _, rep := range reps {
+		waitFor(t, "the reset on every replica", func() bool {
+			return rep.fsm.Filter().Count() == 0
+		})
+	}
+}
+
+// A replica that joins after the log was compacted is restored from the
+// leader's snapshot.
+func TestClusterSnapshotRestore(t *testing.T) {
+	reps := startCluster(t, 1)
+	leader := reps[0]
+	for i := 0; i < 50; i++ {
+		if err := leader.f.Add([]byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := leader.r.Snapshot().Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	joined := startReplica(t, "joined")
+	connect(leader, joined)
+	addr := joined.trans.LocalAddr()
+	if err := leader.r.AddVoter(raft.ServerID(addr), addr, 0, 0).Error(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the snapshot on the new replica", func() bool {
+		return joined.fsm.Filter().Count() == 50
+	})
+	if joined.r.Stats()["last_snapshot_index"] == "0" {
+		t.Error("the new replica caught up without restoring a snapshot")
+	}
+	for i := 0; i < 50; i++ {
+		if !joined.f.Contains([]byte(strconv.Itoa(i))) {
+			t.Fatalf("item %d missing on the new replica", i)
+		}
+	}
+}
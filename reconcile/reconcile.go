@@ -0,0 +1,177 @@
+// Package reconcile works out which keys two replicas are missing from each
+// other without shipping either key set. Keys are reduced to 64-bit IDs and
+// the replicas exchange invertible Bloom lookup tables of them, growing the
+// table until the difference decodes; only if the sets turn out too far
+// apart does it fall back to exchanging Bloom filters.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+	"github.com/hriday-13th/bloom-filter/iblt"
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// Peer is the remote side of a reconciliation, usually a client for a
+// LocalPeer on the other replica.
+type Peer interface {
+	// Sketch returns an IBLT of cells cells holding the ID of every key.
+	Sketch(ctx context.Context, cells uint) (*iblt.Table, error)
+	// Keys returns the keys with the given IDs; unknown IDs are skipped.
+	Keys(ctx context.Context, ids []uint64) ([][]byte, error)
+	// KeysNotIn returns every key that f does not contain.
+	KeysNotIn(ctx context.Context, f *bloomfilter.BloomFilter) ([][]byte, error)
+	// Filter returns a Bloom filter of every key at the given
+	// false-positive rate.
+	Filter(ctx context.Context, fpRate float64) (*bloomfilter.BloomFilter, error)
+}
+
+// Options tune Reconcile. The zero value is usable.
+type Options struct {
+	// InitialCells sizes the first sketch; each retry makes it four times
+	// larger. Default 64.
+	InitialCells uint
+	// MaxCells is the largest sketch tried before falling back to Bloom
+	// filters. Default 1<<20.
+	MaxCells uint
+	// FPRate is the false-positive rate of the fallback filters, and so
+	// roughly the fraction of differing keys the fallback misses. Default
+	// 0.001.
+	FPRate float64
+}
+
+// Result holds the keys only one side has.
+type Result struct {
+	// Missing are the peer's keys that the local set lacks.
+	Missing [][]byte
+	// Extra are the local keys that the peer lacks.
+	Extra [][]byte
+	// Approximate is set when the Bloom filter fallback ran, which can miss
+	// a few keys on each side; running Reconcile again after syncing
+	// catches them.
+	Approximate bool
+}
+
+// ID is the 64-bit ID a key goes by in sketches.
+func ID(key []byte) uint64 {
+	h, _ := hashing.Sum128(key)
+	return h
+}
+
+// Reconcile compares the local keys with the peer's.
+func Reconcile(ctx context.Context, local iter.Seq[[]byte], peer Peer, opts Options) (Result, error) {
+	lp := NewLocalPeer(local)
+	cells := opts.InitialCells
+	if cells == 0 {
+		cells = 64
+	}
+	maxCells := opts.MaxCells
+	if maxCells == 0 {
+		maxCells = 1 << 20
+	}
+
+	for ; cells <= maxCells; cells *= 4 {
+		remote, err := peer.Sketch(ctx, cells)
+		if err != nil {
+			return Result{}, err
+		}
+		diff := lp.sketch(cells)
+		if err := diff.Subtract(remote); err != nil {
+			return Result{}, err
+		}
+		ours, theirs, err := diff.Decode()
+		if errors.Is(err, iblt.ErrDecodeFailed) {
+			continue
+		}
+		if err != nil {
+			return Result{}, err
+		}
+
+		var res Result
+		for _, id := range ours {
+			res.Extra = append(res.Extra, lp.keys[id])
+		}
+		if len(theirs) > 0 {
+			if res.Missing, err = peer.Keys(ctx, theirs); err != nil {
+				return Result{}, err
+			}
+		}
+		return res, nil
+	}
+	return lp.reconcileFilters(ctx, peer, opts.FPRate)
+}
+
+func (lp *LocalPeer) reconcileFilters(ctx context.Context, peer Peer, fpRate float64) (Result, error) {
+	if fpRate == 0 {
+		fpRate = 0.001
+	}
+	remote, err := peer.Filter(ctx, fpRate)
+	if err != nil {
+		return Result{}, err
+	}
+	local, _ := lp.Filter(ctx, fpRate)
+	missing, err := peer.KeysNotIn(ctx, local)
+	if err != nil {
+		return Result{}, err
+	}
+	extra, _ := lp.KeysNotIn(ctx, remote)
+	return Result{Missing: missing, Extra: extra, Approximate: true}, nil
+}
+
+// LocalPeer answers a Peer's requests from a snapshot of a key set, for
+// serving the other side of Reconcile over a transport of your choice.
+type LocalPeer struct {
+	keys map[uint64][]byte
+}
+
+// NewLocalPeer snapshots every key of keys.
+func NewLocalPeer(keys iter.Seq[[]byte]) *LocalPeer {
+	lp := &LocalPeer{keys: make(map[uint64][]byte)}
+	for key := range keys {
+		lp.keys[ID(key)] = key
+	}
+	return lp
+}
+
+func (lp *LocalPeer) sketch(cells uint) *iblt.Table {
+	t := iblt.New(cells)
+	for id := range lp.keys {
+		t.Insert(id)
+	}
+	return t
+}
+
+func (lp *LocalPeer) Sketch(ctx context.Context, cells uint) (*iblt.Table, error) {
+	return lp.sketch(cells), nil
+}
+
+func (lp *LocalPeer) Keys(ctx context.Context, ids []uint64) ([][]byte, error) {
+	var keys [][]byte
+	for _, id := range ids {
+		if key, ok := lp.keys[id]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (lp *LocalPeer) KeysNotIn(ctx context.Context, f *bloomfilter.BloomFilter) ([][]byte, error) {
+	var keys [][]byte
+	for _, key := range lp.keys {
+		if !f.Contains(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (lp *LocalPeer) Filter(ctx context.Context, fpRate float64) (*bloomfilter.BloomFilter, error) {
+	f := bloomfilter.NewWithEstimates(uint(len(lp.keys)), fpRate)
+	for _, key := range lp.keys {
+		f.Add(key)
+	}
+	return f, nil
+}
@@ -0,0 +1,123 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/hriday-13th/bloom-filter/iblt"
+)
+
+// keySets returns a shared set of keys plus onlyA keys for one side and
+// onlyB for the other.
+func keySets(shared, onlyA, onlyB int) (a, b, wantA, wantB [][]byte) {
+	for i := 0; i < shared; i++ {
+		key := []byte("shared" + strconv.Itoa(i))
+		a = append(a, key)
+		b = append(b, key)
+	}
+	for i := 0; i < onlyA; i++ {
+		wantA = append(wantA, []byte("a"+strconv.Itoa(i)))
+	}
+	for i := 0; i < onlyB; i++ {
+		wantB = append(wantB, []byte("b"+strconv.Itoa(i)))
+	}
+	return append(a, wantA...), append(b, wantB...), wantA, wantB
+}
+
+// recordingPeer records the sketch sizes asked of a LocalPeer.
+type recordingPeer struct {
+	*LocalPeer
+	cells []uint
+	err   error
+}
+
+func (p *recordingPeer) Sketch(ctx context.Context, cells uint) (*iblt.Table, error) {
+	p.cells = append(p.cells, cells)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.LocalPeer.Sketch(ctx, cells)
+}
+
+func sorted(keys [][]byte) []string {
+	var s []string
+	for _, k := range keys {
+		s = append(s, string(k))
+	}
+	slices.Sort(s)
+	return s
+}
+
+func TestReconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		onlyA, onlyB int
+		cells        []uint
+	}{
+		{"identical", 0, 0, []uint{64}},
+		{"small difference", 5, 3, []uint{64}},
+		{"one side only", 0, 20, []uint{64}},
+		// Too much for the first sketches, so they grow until it decodes.
+		{"large difference", 300, 200, []uint{64, 256, 1024}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b, wantA, wantB := keySets(5000, tc.onlyA, tc.onlyB)
+			peer := &recordingPeer{LocalPeer: NewLocalPeer(slices.Values(b))}
+			res, err := Reconcile(context.Background(), slices.Values(a), peer, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Approximate {
+				t.Error("fell back to Bloom filters")
+			}
+			if !slices.Equal(sorted(res.Extra), sorted(wantA)) || !slices.Equal(sorted(res.Missing), sorted(wantB)) {
+				t.Errorf("extra %q, missing %q; want %q and %q", sorted(res.Extra), sorted(res.Missing), sorted(wantA), sorted(wantB))
+			}
+			if !slices.Equal(peer.cells, tc.cells) {
+				t.Errorf("sketch sizes %v, want %v", peer.cells, tc.cells)
+			}
+		})
+	}
+}
+
+// Past MaxCells, Bloom filters find the difference, give or take a
+// false positive's worth of keys, and never report a shared key.
+func TestReconcileFallback(t *testing.T) {
+	a, b, wantA, wantB := keySets(5000, 500, 500)
+	peer := NewLocalPeer(slices.Values(b))
+	res, err := Reconcile(context.Background(), slices.Values(a), peer, Options{MaxCells: 256, FPRate: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Approximate {
+		t.Error("Approximate unset after the fallback")
+	}
+	for _, side := range []struct {
+		name      string
+		got, want [][]byte
+	}{
+		{"extra", res.Extra, wantA},
+		{"missing", res.Missing, wantB},
+	} {
+		want := sorted(side.want)
+		for _, key := range sorted(side.got) {
+			if _, ok := slices.BinarySearch(want, key); !ok {
+				t.Errorf("%s: %q is not in the difference", side.name, key)
+			}
+		}
+		if len(side.got) < len(side.want)*95/100 {
+			t.Errorf("%s: found %d of %d keys", side.name, len(side.got), len(side.want))
+		}
+	}
+}
+
+func TestReconcilePeerError(t *testing.T) {
+	errPeer := errors.New("peer unreachable")
+	peer := &recordingPeer{LocalPeer: NewLocalPeer(slices.Values([][]byte{[]byte("a")})), err: errPeer}
+	if _, err := Reconcile(context.Background(), slices.Values([][]byte{[]byte("b")}), peer, Options{}); !errors.Is(err, errPeer) {
+		t.Errorf("got %v, want the peer's error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package bloomfilter
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hriday-13th/bloom-filter/internal/resp"
+)
+
+var ErrUnexpectedReply = errors.New("bloomfilter: unexpected reply from redis")
+
+// RedisBackend keeps the bit array in a Redis string, so every application
+// instance pointing at the same key shares one logical filter. Each Add and
+// Contains is one BITFIELD round trip covering all probes. Redis strings are
+// limited to 2^32 bits.
+type RedisBackend struct {
+	mu   sync.Mutex
+	conn *resp.Conn
+	key  string
+}
+
+// DialRedisBackend connects to the Redis server at addr and stores the
+// filter under key.
+func DialRedisBackend(addr, key string) (*RedisBackend, error) {
+	conn, err := resp.Dial(addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisBackend{conn: conn, key: key}, nil
+}
+
+// NewRedisBackend uses an existing connection, for example one wrapped in
+// TLS or already authenticated.
+func NewRedisBackend(conn net.Conn, key string) *RedisBackend {
+	return &RedisBackend{conn: resp.NewConn(conn), key: key}
+}
+
+func (rb *RedisBackend) Close() error {
+	return rb.conn.Close()
+}
+
+func (rb *RedisBackend) bitfield(op string, positions []uint64, withValue bool) (resp.Value, error) {
+	args := make([]any, 0, 2+4*len(positions))
+	args = append(args, "BITFIELD", rb.key)
+	for _, pos := range positions {
+		args = append(args, op, "u1", pos)
+		if withValue {
+			args = append(args, 1)
+		}
+	}
+
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.conn.Do(args...)
+}
+
+func (rb *RedisBackend) SetBits(positions []uint64) error {
+	_, err := rb.bitfield("SET", positions, true)
+	return err
+}
+
+func (rb *RedisBackend) TestBits(positions []uint64) (bool, error) {
+	reply, err := rb.bitfield("GET", positions, false)
+	if err != nil {
+		return false, err
+	}
+	if len(reply.Array) != len(positions) {
+		return false, ErrUnexpectedReply
+	}
+	for _, v := range reply.Array {
+		if v.Int == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Words converts from Redis bit order, where offset 0 is the most
+// significant bit of the first byte.
+func (rb *RedisBackend) Words(size uint64) ([]uint64, error) {
+	rb.mu.Lock()
+	reply, err := rb.conn.Do("GET", rb.key)
+	rb.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]uint64, (size+63)/64)
+	for i, b := range reply.Str {
+		for j := 0; j < 8; j++ {
+			bit := uint64(i)*8 + uint64(j)
+			if bit >= size {
+				break
+			}
+			if b&(0x80>>j) != 0 {
+				words[bit/64] |= 1 << (bit % 64)
+			}
+		}
+	}
+	return words, nil
+}
+
+func (rb *RedisBackend) Clear() error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	_, err := rb.conn.Do("DEL", rb.key)
+	return err
+}
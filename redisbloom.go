@@ -0,0 +1,451 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+)
+
+// Option bits of a RedisBloom chain, as stored in its dump header.
+const (
+	redisBloomOptNoRound   = 1
+	redisBloomOptEntsBits  = 2
+	redisBloomOptForce64   = 4
+	redisBloomOptNoScaling = 8
+)
+
+const (
+	redisBloomLn2          = 0.693147180559945
+	redisBloomLn2Squared   = 0.480453013918201
+	redisBloomTightening   = 0.5
+	redisBloomHeaderSize   = 20
+	redisBloomLinkSize     = 53
+	redisBloomMaxChunkSize = 16 << 20
+	// redisBloomMaxHashes is what bloom_init computes for the smallest
+	// positive error rate; a header claiming more is not from RedisBloom.
+	redisBloomMaxHashes = 1075
+)
+
+var (
+	ErrInvalidRedisBloom     = errors.New("bloomfilter: redisbloom capacity must be positive and false-positive rate in (0, 1)")
+	ErrInvalidRedisBloomDump = errors.New("bloomfilter: invalid redisbloom dump")
+	// ErrRedisBloomTooLarge is returned for a link of more than 2^63 bits,
+	// which bloom_init cannot address either.
+	ErrRedisBloomTooLarge = errors.New("bloomfilter: redisbloom filter too large")
+)
+
+type redisBloomLink struct {
+	bf      []byte
+	bits    uint64
+	size    uint64
+	entries uint64
+	errRate float64
+	bpe     float64
+	hashes  uint32
+	n2      uint8
+}
+
+// RedisBloomFilter reproduces a RedisBloom scalable filter (the SBChain
+// behind BF.RESERVE and BF.ADD): the same sizing, MurmurHash64A double
+// hashing, bit layout and growth, so the output of ScanDump can be fed to
+// BF.LOADCHUNK and the replies of BF.SCANDUMP can be fed to LoadChunk.
+//
+// The zero value is an empty filter ready for Reserve or LoadChunk.
+type RedisBloomFilter struct {
+	// Limits bounds filters built from untrusted input. MaxBits caps the
+	// bits of all links together, as set by Reserve, by a header passed to
+	// LoadChunk and by Add when the filter grows; MaxBytes caps a header
+	// and the bit arrays it declares. Set it before the filter is shared.
+	Limits DecodeOptions
+
+	mu      sync.RWMutex
+	links   []redisBloomLink
+	size    uint64
+	options uint32
+	growth  uint32
+}
+
+// NewRedisBloom behaves like BF.RESERVE key errorRate capacity EXPANSION
+// expansion. An expansion of zero selects NONSCALING.
+func NewRedisBloom(capacity uint64, errorRate float64, expansion uint32) (*RedisBloomFilter, error) {
+	rf := new(RedisBloomFilter)
+	if err := rf.Reserve(capacity, errorRate, expansion); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// Reserve replaces the contents of the filter with an empty one, sized as
+// NewRedisBloom does, within rf.Limits.
+func (rf *RedisBloomFilter) Reserve(capacity uint64, errorRate float64, expansion uint32) error {
+	if !(errorRate > 0 && errorRate < 1) {
+		return ErrInvalidRedisBloom
+	}
+	options := uint32(redisBloomOptForce64)
+	growth := expansion
+	if expansion == 0 {
+		options |= redisBloomOptNoScaling
+		growth = 1
+	}
+	link, err := newRedisBloomLink(capacity, errorRate*redisBloomTightening, options)
+	if err != nil {
+		return err
+	}
+	if err := rf.Limits.checkBits(link.bits); err != nil {
+		return err
+	}
+	link.alloc()
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.links = []redisBloomLink{link}
+	rf.size = 0
+	rf.options = options
+	rf.growth = growth
+	return nil
+}
+
+// newRedisBloomLink mirrors bloom_init, including its checks, except that
+// it also refuses the 2^64-bit link whose probe modulus would wrap to zero.
+// The bit array is allocated by the caller once it is within limits.
+func newRedisBloomLink(entries uint64, errRate float64, options uint32) (redisBloomLink, error) {
+	if entries == 0 || !(errRate > 0 && errRate < 1) {
+		return redisBloomLink{}, ErrInvalidRedisBloom
+	}
+	link := redisBloomLink{
+		entries: entries,
+		errRate: errRate,
+		bpe:     math.Abs(-math.Log(errRate) / redisBloomLn2Squared),
+	}
+
+	var bits uint64
+	want := float64(entries) * link.bpe
+	if options&redisBloomOptNoRound != 0 {
+		if want > 1<<63 {
+			return redisBloomLink{}, ErrRedisBloomTooLarge
+		}
+		bits = uint64(want)
+	} else {
+		bn2 := math.Logb(want)
+		if bn2 > 62 {
+			return redisBloomLink{}, ErrRedisBloomTooLarge
+		}
+		link.n2 = uint8(bn2 + 1)
+		bits = 1 << link.n2
+		bitDiff := uint64(float64(bits) - want)
+		link.entries += uint64(float64(bitDiff) / link.bpe)
+	}
+
+	numBytes := bits / 8
+	if bits%64 != 0 {
+		numBytes = (bits/64 + 1) * 8
+	}
+	link.bits = numBytes * 8
+	link.hashes = uint32(math.Ceil(redisBloomLn2 * link.bpe))
+	return link, nil
+}
+
+// totalBits is the bits held by all links.
+func (rf *RedisBloomFilter) totalBits() uint64 {
+	var n uint64
+	for i := range rf.links {
+		n += rf.links[i].bits
+	}
+	return n
+}
+
+func (link *redisBloomLink) alloc() {
+	link.bf = make([]byte, link.bits/8)
+}
+
+func (rf *RedisBloomFilter) hash(item []byte) (uint64, uint64) {
+	if rf.options&redisBloomOptForce64 != 0 {
+		a := murmurHash64A(item, 0xc6a4a7935bd1e995)
+		return a, murmurHash64A(item, a)
+	}
+	a := murmurHash2(item, 0x9747b28c)
+	return uint64(a), uint64(murmurHash2(item, a))
+}
+
+// modulus is the probe range used by bloom_check_add: a power of two for
+// rounded filters, the raw bit count for NOROUND ones.
+func (link *redisBloomLink) modulus() uint64 {
+	if link.n2 > 0 {
+		return 1 << link.n2
+	}
+	return link.bits
+}
+
+func (link *redisBloomLink) test(a, b uint64) bool {
+	mod := link.modulus()
+	for i := uint64(0); i < uint64(link.hashes); i++ {
+		x := (a + i*b) % mod
+		if link.bf[x>>3]&(1<<(x%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (link *redisBloomLink) set(a, b uint64) {
+	mod := link.modulus()
+	for i := uint64(0); i < uint64(link.hashes); i++ {
+		x := (a + i*b) % mod
+		link.bf[x>>3] |= 1 << (x % 8)
+	}
+}
+
+// Add behaves like BF.ADD: it reports false when the item was probably
+// already present, when a non-scaling filter is full, or when a scaling one
+// cannot grow without exceeding rf.Limits or the largest link.
+func (rf *RedisBloomFilter) Add(item []byte) bool {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if len(rf.links) == 0 {
+		return false
+	}
+	a, b := rf.hash(item)
+	for i := len(rf.links) - 1; i >= 0; i-- {
+		if rf.links[i].test(a, b) {
+			return false
+		}
+	}
+
+	cur := &rf.links[len(rf.links)-1]
+	if cur.size >= cur.entries {
+		if rf.options&redisBloomOptNoScaling != 0 {
+			return false
+		}
+		if cur.entries > math.MaxUint64/uint64(rf.growth) {
+			return false
+		}
+		next, err := newRedisBloomLink(cur.entries*uint64(rf.growth), cur.errRate*redisBloomTightening, rf.options)
+		if err != nil || next.bits > math.MaxUint64-rf.totalBits() || rf.Limits.checkBits(rf.totalBits()+next.bits) != nil {
+			return false
+		}
+		next.alloc()
+		rf.links = append(rf.links, next)
+		cur = &rf.links[len(rf.links)-1]
+	}
+	cur.set(a, b)
+	cur.size++
+	rf.size++
+	return true
+}
+
+func (rf *RedisBloomFilter) Contains(item []byte) bool {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+
+	if len(rf.links) == 0 {
+		return false
+	}
+	a, b := rf.hash(item)
+	for i := len(rf.links) - 1; i >= 0; i-- {
+		if rf.links[i].test(a, b) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rf *RedisBloomFilter) Count() uint {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+	return uint(rf.size)
+}
+
+// ScanDump behaves like BF.SCANDUMP key iter: start with iter 0 to get the
+// header, pass each returned iterator back in, and stop when it returns 0.
+// Every (iterator, data) pair it returns is a valid BF.LOADCHUNK call.
+func (rf *RedisBloomFilter) ScanDump(iter int64) (int64, []byte) {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+
+	if iter == 0 {
+		return 1, rf.encodeHeader()
+	}
+
+	link, offset := rf.linkAt(uint64(iter - 1))
+	if link == nil {
+		return 0, nil
+	}
+	n := min(uint64(len(link.bf))-offset, redisBloomMaxChunkSize)
+	chunk := make([]byte, n)
+	copy(chunk, link.bf[offset:])
+	return iter + int64(n), chunk
+}
+
+// LoadChunk behaves like BF.LOADCHUNK key iter data. The header, returned by
+// the first BF.SCANDUMP call with iterator 1, must be loaded first and
+// replaces the contents of the filter. A header declaring more than
+// rf.Limits allow fails with a LimitError before anything is allocated.
+func (rf *RedisBloomFilter) LoadChunk(iter int64, data []byte) error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if iter == 1 {
+		return rf.decodeHeader(data)
+	}
+	if len(rf.links) == 0 || iter <= 0 || iter < int64(len(data)) {
+		return ErrInvalidRedisBloomDump
+	}
+
+	link, offset := rf.linkAt(uint64(iter) - uint64(len(data)) - 1)
+	if link == nil || offset+uint64(len(data)) > uint64(len(link.bf)) {
+		return ErrInvalidRedisBloomDump
+	}
+	copy(link.bf[offset:], data)
+	return nil
+}
+
+// linkAt maps a byte position in the concatenation of all bit arrays to a
+// link and an offset within it.
+func (rf *RedisBloomFilter) linkAt(pos uint64) (*redisBloomLink, uint64) {
+	for i := range rf.links {
+		n := uint64(len(rf.links[i].bf))
+		if pos < n {
+			return &rf.links[i], pos
+		}
+		pos -= n
+	}
+	return nil, 0
+}
+
+// encodeHeader writes the packed dumpedChainHeader and dumpedChainLink
+// structs in little-endian order.
+func (rf *RedisBloomFilter) encodeHeader() []byte {
+	hdr := make([]byte, redisBloomHeaderSize+redisBloomLinkSize*len(rf.links))
+	binary.LittleEndian.PutUint64(hdr[0:8], rf.size)
+	binary.LittleEndian.PutUint32(hdr[8:12], uint32(len(rf.links)))
+	binary.LittleEndian.PutUint32(hdr[12:16], rf.options)
+	binary.LittleEndian.PutUint32(hdr[16:20], rf.growth)
+
+	for i, link := range rf.links {
+		b := hdr[redisBloomHeaderSize+i*redisBloomLinkSize:]
+		binary.LittleEndian.PutUint64(b[0:8], uint64(len(link.bf)))
+		binary.LittleEndian.PutUint64(b[8:16], link.bits)
+		binary.LittleEndian.PutUint64(b[16:24], link.size)
+		binary.LittleEndian.PutUint64(b[24:32], math.Float64bits(link.errRate))
+		binary.LittleEndian.PutUint64(b[32:40], math.Float64bits(link.bpe))
+		binary.LittleEndian.PutUint32(b[40:44], link.hashes)
+		binary.LittleEndian.PutUint64(b[44:52], link.entries)
+		b[52] = link.n2
+	}
+	return hdr
+}
+
+func (rf *RedisBloomFilter) decodeHeader(hdr []byte) error {
+	if len(hdr) < redisBloomHeaderSize {
+		return ErrInvalidRedisBloomDump
+	}
+	numLinks := binary.LittleEndian.Uint32(hdr[8:12])
+	if numLinks == 0 || uint64(len(hdr)) != redisBloomHeaderSize+redisBloomLinkSize*uint64(numLinks) {
+		return ErrInvalidRedisBloomDump
+	}
+
+	links := make([]redisBloomLink, numLinks)
+	var totalBits uint64
+	for i := range links {
+		b := hdr[redisBloomHeaderSize+i*redisBloomLinkSize:]
+		link := redisBloomLink{
+			bits:    binary.LittleEndian.Uint64(b[8:16]),
+			size:    binary.LittleEndian.Uint64(b[16:24]),
+			errRate: math.Float64frombits(binary.LittleEndian.Uint64(b[24:32])),
+			bpe:     math.Float64frombits(binary.LittleEndian.Uint64(b[32:40])),
+			hashes:  binary.LittleEndian.Uint32(b[40:44]),
+			entries: binary.LittleEndian.Uint64(b[44:52]),
+			n2:      b[52],
+		}
+		numBytes := binary.LittleEndian.Uint64(b[0:8])
+		if numBytes == 0 || numBytes > 1<<60 || link.bits != numBytes*8 || link.n2 > 63 || link.modulus() > link.bits {
+			return ErrInvalidRedisBloomDump
+		}
+		// Every probe loops over the hashes, and growth derives the next
+		// link from the error rate, so both must be what bloom_init could
+		// have left.
+		if link.hashes == 0 || link.hashes > redisBloomMaxHashes || !(link.errRate > 0 && link.errRate < 1) {
+			return ErrInvalidRedisBloomDump
+		}
+		if link.bits > math.MaxUint64-totalBits {
+			return ErrInvalidRedisBloomDump
+		}
+		totalBits += link.bits
+		links[i] = link
+	}
+
+	options := binary.LittleEndian.Uint32(hdr[12:16])
+	growth := binary.LittleEndian.Uint32(hdr[16:20])
+	if options&redisBloomOptNoScaling == 0 && growth == 0 {
+		return ErrInvalidRedisBloomDump
+	}
+	if err := rf.Limits.checkBits(totalBits); err != nil {
+		return err
+	}
+	if err := rf.Limits.checkBytes(uint64(len(hdr)) + totalBits/8); err != nil {
+		return err
+	}
+	for i := range links {
+		links[i].alloc()
+	}
+
+	rf.links = links
+	rf.size = binary.LittleEndian.Uint64(hdr[0:8])
+	rf.options = options
+	rf.growth = growth
+	return nil
+}
+
+func murmurHash64A(data []byte, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+
+	h := seed ^ uint64(len(data))*m
+	for ; len(data) >= 8; data = data[8:] {
+		k := binary.LittleEndian.Uint64(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+	}
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * i)
+		}
+		h *= m
+	}
+
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+func murmurHash2(data []byte, seed uint32) uint32 {
+	const m = 0x5bd1e995
+	const r = 24
+
+	h := seed ^ uint32(len(data))
+	for ; len(data) >= 4; data = data[4:] {
+		k := binary.LittleEndian.Uint32(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint32(data[i]) << (8 * i)
+		}
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
@@ -0,0 +1,185 @@
+package bloomfilter
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// The golden vectors below come from the reference MurmurHash2 and
+// MurmurHash64A and from RedisBloom's bloom_init and SBChain growth, built
+// from C.
+
+func TestRedisBloomHashes(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		h64 uint64
+		h32 uint32
+	}{
+		{"", 0x1ab11ea5a7b2c56e, 0x106e08d9},
+		{"a", 0x4292cee227b9150a, 0xa2d0b27c},
+		{"abcdefg", 0x9fa0b24601c1e9a9, 0xeb595499},
+		{"abcdefgh", 0xd435df7a565a8af1, 0xc70d55dd},
+		{"hello, world", 0x3eb828dd01be3c18, 0x32e6f3a9},
+	} {
+		if got := murmurHash64A([]byte(tc.in), 0xc6a4a7935bd1e995); got != tc.h64 {
+			t.Errorf("murmurHash64A(%q) = %#x, want %#x", tc.in, got, tc.h64)
+		}
+		if got := murmurHash2([]byte(tc.in), 0x9747b28c); got != tc.h32 {
+			t.Errorf("murmurHash2(%q) = %#x, want %#x", tc.in, got, tc.h32)
+		}
+	}
+}
+
+// BF.RESERVE k 0.01 100 followed by BF.ADD k item0 … item299, which grows
+// the chain to a second link.
+const (
+	redisBloomGoldenHeader = "2c0100000000000002000000040000000200000000010000000000000008000000000000b9000000000000007b14ae47e17a743fe9862fb2350e264008000000b9000000000000000b0004000000000000002000000000000073000000000000007b14ae47e17a643f4af7d49edef028400900000090020000000000000d"
+	redisBloomGoldenBits   = "2a11fed204500902c11e4cebc461ae809c0faf4c7694e7ce3852b81a88ead64f"
+)
+
+func redisBloomGolden(t *testing.T) *RedisBloomFilter {
+	t.Helper()
+	rf, err := NewRedisBloom(100, 0.01, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 300; i++ {
+		if !rf.Add([]byte("item" + strconv.Itoa(i))) {
+			t.Fatalf("Add(item%d) = false", i)
+		}
+	}
+	return rf
+}
+
+func TestRedisBloomGolden(t *testing.T) {
+	rf := redisBloomGolden(t)
+
+	iter, hdr := rf.ScanDump(0)
+	if got := hex.EncodeToString(hdr); got != redisBloomGoldenHeader {
+		t.Errorf("header = %s, want %s", got, redisBloomGoldenHeader)
+	}
+	chunks := map[int64][]byte{1: hdr}
+	bits := sha256.New()
+	for iter != 0 {
+		var data []byte
+		iter, data = rf.ScanDump(iter)
+		if iter != 0 {
+			chunks[iter] = data
+			bits.Write(data)
+		}
+	}
+	if got := hex.EncodeToString(bits.Sum(nil)); got != redisBloomGoldenBits {
+		t.Errorf("bits hash to %s, want %s", got, redisBloomGoldenBits)
+	}
+
+	var loaded RedisBloomFilter
+	if err := loaded.LoadChunk(1, chunks[1]); err != nil {
+		t.Fatal(err)
+	}
+	for iter, data := range chunks {
+		if iter != 1 {
+			if err := loaded.LoadChunk(iter, data); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if loaded.Count() != 300 {
+		t.Errorf("loaded Count = %d, want 300", loaded.Count())
+	}
+	for i := 0; i < 300; i++ {
+		if !loaded.Contains([]byte("item" + strconv.Itoa(i))) {
+			t.Fatalf("loaded filter lacks item%d", i)
+		}
+	}
+}
+
+func TestRedisBloomInvalidParameters(t *testing.T) {
+	for _, tc := range []struct {
+		capacity  uint64
+		errorRate float64
+		err       error
+	}{
+		{0, 0.01, ErrInvalidRedisBloom},
+		{100, 0, ErrInvalidRedisBloom},
+		{100, 1, ErrInvalidRedisBloom},
+		{100, math.NaN(), ErrInvalidRedisBloom},
+		{1 << 60, 0.01, ErrRedisBloomTooLarge},
+		{math.MaxUint64, 0.01, ErrRedisBloomTooLarge},
+	} {
+		if _, err := NewRedisBloom(tc.capacity, tc.errorRate, 2); !errors.Is(err, tc.err) {
+			t.Errorf("NewRedisBloom(%d, %v): got %v, want %v", tc.capacity, tc.errorRate, err, tc.err)
+		}
+	}
+}
+
+func TestRedisBloomLimits(t *testing.T) {
+	rf := &RedisBloomFilter{Limits: DecodeOptions{MaxBits: 512}}
+	if err := rf.Reserve(100, 0.01, 2); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Reserve over MaxBits: got %v, want ErrTooLarge", err)
+	}
+
+	// The first link takes 1024 bits and the second 2048, which the limit
+	// leaves no room for, so the filter stops accepting items instead.
+	rf.Limits.MaxBits = 2048
+	if err := rf.Reserve(100, 0.01, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 300; i++ {
+		rf.Add([]byte("item" + strconv.Itoa(i)))
+	}
+	if n := rf.Count(); n > 185 {
+		t.Errorf("Count = %d, more than the first link holds", n)
+	}
+
+	_, hdr := redisBloomGolden(t).ScanDump(0)
+	limited := RedisBloomFilter{Limits: DecodeOptions{MaxBits: 1 << 10}}
+	if err := limited.LoadChunk(1, hdr); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("LoadChunk over MaxBits: got %v, want ErrTooLarge", err)
+	}
+	limited.Limits = DecodeOptions{MaxBytes: 1 << 8}
+	if err := limited.LoadChunk(1, hdr); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("LoadChunk over MaxBytes: got %v, want ErrTooLarge", err)
+	}
+}
+
+func TestRedisBloomInvalidHeader(t *testing.T) {
+	_, hdr := redisBloomGolden(t).ScanDump(0)
+	link := hdr[redisBloomHeaderSize:]
+	for _, tc := range []struct {
+		name   string
+		mutate func(h, link []byte)
+	}{
+		{"short", nil},
+		{"no links", func(h, _ []byte) { binary.LittleEndian.PutUint32(h[8:], 0) }},
+		{"zero growth", func(h, _ []byte) { binary.LittleEndian.PutUint32(h[16:], 0) }},
+		{"n2 64", func(_, l []byte) { l[52] = 64 }},
+		{"no hashes", func(_, l []byte) { binary.LittleEndian.PutUint32(l[40:], 0) }},
+		{"too many hashes", func(_, l []byte) { binary.LittleEndian.PutUint32(l[40:], math.MaxUint32) }},
+		{"zero error", func(_, l []byte) { binary.LittleEndian.PutUint64(l[24:], 0) }},
+		{"NaN error", func(_, l []byte) { binary.LittleEndian.PutUint64(l[24:], math.Float64bits(math.NaN())) }},
+		{"wrapping bytes", func(_, l []byte) {
+			binary.LittleEndian.PutUint64(l[0:], 1<<61)
+			binary.LittleEndian.PutUint64(l[8:], 0)
+			l[52] = 0
+		}},
+		{"modulus past bits", func(_, l []byte) { l[52] = 20 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := append([]byte(nil), hdr...)
+			if tc.mutate == nil {
+				h = h[:redisBloomHeaderSize-1]
+			} else {
+				tc.mutate(h, h[len(hdr)-len(link):])
+			}
+			var rf RedisBloomFilter
+			if err := rf.LoadChunk(1, h); !errors.Is(err, ErrInvalidRedisBloomDump) {
+				t.Errorf("got %v, want ErrInvalidRedisBloomDump", err)
+			}
+		})
+	}
+}
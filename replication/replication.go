@@ -0,0 +1,241 @@
+// Package replication keeps read-only copies of a filter in sync with the
+// one process that writes it. A Primary serves the filter over gRPC as a
+// snapshot followed by periodic deltas of the words that changed, and each
+// Follower applies them to its own copy.
+package replication
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+	"github.com/hriday-13th/bloom-filter/bloompb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// subscriberBuffer is how many deltas a follower may fall behind before the
+// primary drops it, to resubscribe from a fresh snapshot.
+const subscriberBuffer = 16
+
+// Primary streams a filter to followers. Writers keep using the filter
+// directly.
+type Primary struct {
+	bloompb.UnimplementedReplicationServiceServer
+	bf        *bloomfilter.BloomFilter
+	mu        sync.Mutex
+	subs      map[chan *bloompb.Update]struct{}
+	lastCount uint64
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewPrimary starts tracking changes to bf and sends followers a delta of
+// them every interval.
+func NewPrimary(bf *bloomfilter.BloomFilter, interval time.Duration) *Primary {
+	bf.TrackChanges()
+	p := &Primary{
+		bf:   bf,
+		subs: make(map[chan *bloompb.Update]struct{}),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go p.run(interval)
+	return p
+}
+
+// Register serves the ReplicationService on r.
+func (p *Primary) Register(r grpc.ServiceRegistrar) {
+	bloompb.RegisterReplicationServiceServer(r, p)
+}
+
+func (p *Primary) run(interval time.Duration) {
+	defer close(p.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.publish()
+		}
+	}
+}
+
+func (p *Primary) publish() {
+	d, err := p.bf.Delta()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if errors.Is(err, bloomfilter.ErrNotTracking) {
+		// The filter was replaced wholesale, so followers need a new
+		// snapshot.
+		p.bf.TrackChanges()
+		p.dropAll()
+		return
+	}
+	if len(d.Words) == 0 && d.Count == p.lastCount {
+		return
+	}
+	p.lastCount = d.Count
+
+	u := &bloompb.Update{Update: &bloompb.Update_Delta{Delta: deltaToProto(d)}}
+	for ch := range p.subs {
+		select {
+		case ch <- u:
+		default:
+			delete(p.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (p *Primary) dropAll() {
+	for ch := range p.subs {
+		delete(p.subs, ch)
+		close(ch)
+	}
+}
+
+func (p *Primary) Subscribe(req *bloompb.SubscribeRequest, stream grpc.ServerStreamingServer[bloompb.Update]) error {
+	// Subscribe before taking the snapshot, so no change falls between it
+	// and the first delta.
+	ch := make(chan *bloompb.Update, subscriberBuffer)
+	p.mu.Lock()
+	select {
+	case <-p.stop:
+		p.mu.Unlock()
+		return status.Error(codes.Unavailable, "primary is closed")
+	default:
+	}
+	p.subs[ch] = struct{}{}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.subs, ch)
+		p.mu.Unlock()
+	}()
+
+	snapshot := p.bf.ToProto()
+	if snapshot == nil {
+		return status.Error(codes.Internal, p.bf.Err().Error())
+	}
+	if err := stream.Send(&bloompb.Update{Update: &bloompb.Update_Snapshot{Snapshot: snapshot}}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case u, ok := <-ch:
+			if !ok {
+				return status.Error(codes.Aborted, "follower fell behind; resubscribe")
+			}
+			if err := stream.Send(u); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close stops sending deltas and ends every subscription.
+func (p *Primary) Close() {
+	p.mu.Lock()
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	p.dropAll()
+	p.mu.Unlock()
+	<-p.done
+}
+
+// Follower keeps a copy of a Primary's filter.
+type Follower struct {
+	c  bloompb.ReplicationServiceClient
+	bf atomic.Pointer[bloomfilter.BloomFilter]
+}
+
+// NewFollower returns a follower of the primary at conn, typically a
+// *grpc.ClientConn. Call Run to start following.
+func NewFollower(conn grpc.ClientConnInterface) *Follower {
+	return &Follower{c: bloompb.NewReplicationServiceClient(conn)}
+}
+
+// Filter returns the copy, or nil before the first snapshot arrives. It is
+// for queries only: the next delta overwrites whatever is added to it, and
+// a new snapshot replaces it with another filter.
+func (f *Follower) Filter() *bloomfilter.BloomFilter {
+	return f.bf.Load()
+}
+
+// Run subscribes to the primary and applies its updates until the stream
+// ends or ctx is done, and returns why. Filter keeps answering from the last
+// state meanwhile; call Run again to resubscribe.
+func (f *Follower) Run(ctx context.Context) error {
+	stream, err := f.c.Subscribe(ctx, &bloompb.SubscribeRequest{})
+	if err != nil {
+		return err
+	}
+	var bf *bloomfilter.BloomFilter
+	for {
+		u, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch u := u.GetUpdate().(type) {
+		case *bloompb.Update_Snapshot:
+			if bf, err = bloomfilter.FromProto(u.Snapshot); err != nil {
+				return err
+			}
+			f.bf.Store(bf)
+		case *bloompb.Update_Delta:
+			if bf == nil {
+				return errors.New("replication: delta before snapshot")
+			}
+			d, err := deltaFromProto(u.Delta)
+			if err != nil {
+				return err
+			}
+			if err := bf.ApplyDelta(d); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func deltaToProto(d *bloomfilter.Delta) *bloompb.Delta {
+	m := &bloompb.Delta{
+		Size:      d.Size,
+		NumHashes: uint32(d.NumHashes),
+		Count:     d.Count,
+		Offsets:   make([]uint64, len(d.Words)),
+		Words:     make([]uint64, len(d.Words)),
+	}
+	for i, dw := range d.Words {
+		m.Offsets[i], m.Words[i] = dw.Offset, dw.Word
+	}
+	return m
+}
+
+func deltaFromProto(m *bloompb.Delta) (*bloomfilter.Delta, error) {
+	if len(m.GetOffsets()) != len(m.GetWords()) {
+		return nil, bloomfilter.ErrInvalidFormat
+	}
+	d := &bloomfilter.Delta{
+		Size:      m.GetSize(),
+		NumHashes: int(m.GetNumHashes()),
+		Count:     m.GetCount(),
+		Words:     make([]bloomfilter.DeltaWord, len(m.GetWords())),
+	}
+	for i := range d.Words {
+		d.Words[i] = bloomfilter.DeltaWord{Offset: m.Offsets[i], Word: m.Words[i]}
+	}
+	return d, nil
+}
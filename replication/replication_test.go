@@ -0,0 +1,120 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+	"github.com/hriday-13th/bloom-filter/bloompb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts p on an in-memory listener and returns a connection to it.
+func serve(t *testing.T, p *Primary) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	p.Register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitFor polls cond until it holds or a few seconds pass.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestFollow(t *testing.T) {
+	bf := bloomfilter.New(1<<16, 5)
+	for i := 0; i < 100; i++ {
+		bf.AddString(strconv.Itoa(i))
+	}
+	p := NewPrimary(bf, 5*time.Millisecond)
+	defer p.Close()
+
+	f := NewFollower(serve(t, p))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Run(ctx) }()
+
+	waitFor(t, "the snapshot", func() bool { return f.Filter() != nil })
+	for i := 0; i < 100; i++ {
+		if !f.Filter().ContainsString(strconv.Itoa(i)) {
+			t.Fatalf("snapshot lacks %d", i)
+		}
+	}
+
+	// Adds after the snapshot arrive as deltas.
+	for i := 100; i < 200; i++ {
+		bf.AddString(strconv.Itoa(i))
+	}
+	waitFor(t, "the delta", func() bool { return f.Filter().Equal(bf) && f.Filter().Count() == bf.Count() })
+
+	cancel()
+	if err := <-done; status.Code(err) != codes.Canceled {
+		t.Errorf("Run returned %v, want Canceled", err)
+	}
+}
+
+// Closing the primary ends every subscription, and later ones are refused.
+func TestPrimaryClose(t *testing.T) {
+	p := NewPrimary(bloomfilter.New(1024, 3), time.Hour)
+	f := NewFollower(serve(t, p))
+	done := make(chan error, 1)
+	go func() { done <- f.Run(context.Background()) }()
+	waitFor(t, "the snapshot", func() bool { return f.Filter() != nil })
+
+	p.Close()
+	if err := <-done; status.Code(err) != codes.Aborted {
+		t.Errorf("Run after Close returned %v, want Aborted", err)
+	}
+	if err := f.Run(context.Background()); status.Code(err) != codes.Unavailable {
+		t.Errorf("Run on a closed primary returned %v, want Unavailable", err)
+	}
+}
+
+func TestDeltaFromProto(t *testing.T) {
+	d := &bloomfilter.Delta{
+		Size:      1024,
+		NumHashes: 3,
+		Count:     7,
+		Words:     []bloomfilter.DeltaWord{{Offset: 1, Word: 0xf0}, {Offset: 15, Word: 1}},
+	}
+	got, err := deltaFromProto(deltaToProto(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Size != d.Size || got.NumHashes != d.NumHashes || got.Count != d.Count || len(got.Words) != 2 ||
+		got.Words[0] != d.Words[0] || got.Words[1] != d.Words[1] {
+		t.Errorf("got %+v, want %+v", got, d)
+	}
+
+	if _, err := deltaFromProto(&bloompb.Delta{Offsets: []uint64{1}}); !errors.Is(err, bloomfilter.ErrInvalidFormat) {
+		t.Errorf("mismatched offsets and words: got %v, want ErrInvalidFormat", err)
+	}
+}
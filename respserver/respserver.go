@@ -0,0 +1,343 @@
+// Package respserver serves RedisBloomFilters over the Redis protocol,
+// implementing enough of RedisBloom that its clients can point at it
+// unchanged:
+//
+//	BF.RESERVE key error_rate capacity [EXPANSION expansion] [NONSCALING]
+//	BF.ADD key item
+//	BF.MADD key item [item ...]
+//	BF.EXISTS key item
+//	BF.MEXISTS key item [item ...]
+//	BF.CARD key
+//	BF.SCANDUMP key iterator
+//	BF.LOADCHUNK key iterator data
+//
+// as well as PING and QUIT. As in RedisBloom, BF.ADD and BF.MADD create
+// missing filters with an error rate of 0.01, a capacity of 100 and an
+// expansion of 2. Filters are bounded by Server.Limits, so a filter that
+// has grown to its limit stops accepting items, and commands by the
+// argument limits, which close the connection when exceeded.
+package respserver
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+	"github.com/hriday-13th/bloom-filter/internal/resp"
+)
+
+const (
+	defaultErrorRate = 0.01
+	defaultCapacity  = 100
+	defaultExpansion = 2
+	defaultMaxBits   = 1 << 32
+)
+
+// Server holds filters by key. The zero value is not usable; call New.
+type Server struct {
+	// Limits bounds the filters BF.RESERVE, BF.ADD and BF.LOADCHUNK create,
+	// as RedisBloomFilter.Limits does; a zero MaxBits means 2^32 bits per
+	// filter.
+	Limits bloomfilter.DecodeOptions
+	// MaxBulkLen bounds each argument of a command and MaxArgs their
+	// number; zero means the defaults of resp.Limits, 512 MiB and 1 Mi.
+	MaxBulkLen int
+	MaxArgs    int
+
+	mu      sync.RWMutex
+	filters map[string]*bloomfilter.RedisBloomFilter
+}
+
+func New() *Server {
+	return &Server{filters: make(map[string]*bloomfilter.RedisBloomFilter)}
+}
+
+// Filter returns the filter stored under key, or nil.
+func (s *Server) Filter(key string) *bloomfilter.RedisBloomFilter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.filters[key]
+}
+
+// Serve accepts connections on l and serves each in its own goroutine,
+// until Accept fails.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn serves commands from conn until the client quits or the
+// connection fails, then closes it. A command that panics closes only its
+// own connection.
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	defer func() {
+		if recover() != nil {
+			resp.WriteError(w, "ERR internal error")
+			w.Flush()
+		}
+	}()
+	limits := resp.Limits{MaxBulkLen: s.MaxBulkLen, MaxArrayLen: s.MaxArgs}
+	for {
+		cmd, err := resp.ReadValueLimits(r, limits)
+		if err != nil {
+			if errors.Is(err, resp.ErrProtocol) {
+				resp.WriteError(w, "ERR Protocol error")
+				w.Flush()
+			}
+			return
+		}
+		args, ok := commandArgs(cmd)
+		if !ok {
+			resp.WriteError(w, "ERR Protocol error: expected an array of bulk strings")
+			w.Flush()
+			return
+		}
+		quit := s.dispatch(w, args)
+		// Pipelined commands are answered together, once none are waiting.
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+func commandArgs(v resp.Value) ([][]byte, bool) {
+	if v.Array == nil || len(v.Array) == 0 {
+		return nil, false
+	}
+	args := make([][]byte, len(v.Array))
+	for i, a := range v.Array {
+		if a.Str == nil && !a.Null {
+			return nil, false
+		}
+		args[i] = a.Str
+	}
+	return args, true
+}
+
+// dispatch runs one command, writing its reply, and reports whether the
+// client asked to quit.
+func (s *Server) dispatch(w *bufio.Writer, args [][]byte) bool {
+	name := strings.ToUpper(string(args[0]))
+	arity := func(min int, exact bool) bool {
+		if len(args) < min || exact && len(args) != min {
+			resp.WriteError(w, "ERR wrong number of arguments for '"+strings.ToLower(name)+"' command")
+			return false
+		}
+		return true
+	}
+
+	switch name {
+	case "PING":
+		if len(args) > 1 {
+			resp.WriteBulk(w, args[1])
+		} else {
+			resp.WriteSimple(w, "PONG")
+		}
+	case "QUIT":
+		resp.WriteSimple(w, "OK")
+		return true
+	case "BF.RESERVE":
+		if arity(4, false) {
+			s.reserve(w, args)
+		}
+	case "BF.ADD":
+		if arity(3, true) {
+			if rf := s.getOrCreate(w, string(args[1])); rf != nil {
+				resp.WriteInt(w, boolInt(rf.Add(args[2])))
+			}
+		}
+	case "BF.MADD":
+		if arity(3, false) {
+			rf := s.getOrCreate(w, string(args[1]))
+			if rf == nil {
+				break
+			}
+			resp.WriteArrayHeader(w, len(args)-2)
+			for _, item := range args[2:] {
+				resp.WriteInt(w, boolInt(rf.Add(item)))
+			}
+		}
+	case "BF.EXISTS":
+		if arity(3, true) {
+			rf := s.Filter(string(args[1]))
+			resp.WriteInt(w, boolInt(rf != nil && rf.Contains(args[2])))
+		}
+	case "BF.MEXISTS":
+		if arity(3, false) {
+			rf := s.Filter(string(args[1]))
+			resp.WriteArrayHeader(w, len(args)-2)
+			for _, item := range args[2:] {
+				resp.WriteInt(w, boolInt(rf != nil && rf.Contains(item)))
+			}
+		}
+	case "BF.CARD":
+		if arity(2, true) {
+			var n uint
+			if rf := s.Filter(string(args[1])); rf != nil {
+				n = rf.Count()
+			}
+			resp.WriteInt(w, int64(n))
+		}
+	case "BF.SCANDUMP":
+		if arity(3, true) {
+			s.scanDump(w, args)
+		}
+	case "BF.LOADCHUNK":
+		if arity(4, true) {
+			s.loadChunk(w, args)
+		}
+	default:
+		resp.WriteError(w, "ERR unknown command '"+string(args[0])+"'")
+	}
+	return false
+}
+
+func (s *Server) reserve(w *bufio.Writer, args [][]byte) {
+	errorRate, err := strconv.ParseFloat(string(args[2]), 64)
+	if err != nil || errorRate <= 0 || errorRate >= 1 {
+		resp.WriteError(w, "ERR (0 < error rate range < 1)")
+		return
+	}
+	capacity, err := strconv.ParseUint(string(args[3]), 10, 64)
+	if err != nil || capacity == 0 {
+		resp.WriteError(w, "ERR (capacity should be larger than 0)")
+		return
+	}
+	expansion := uint64(defaultExpansion)
+	for i := 4; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "NONSCALING":
+			expansion = 0
+		case "EXPANSION":
+			if i+1 == len(args) {
+				resp.WriteError(w, "ERR syntax error")
+				return
+			}
+			i++
+			if expansion, err = strconv.ParseUint(string(args[i]), 10, 32); err != nil || expansion == 0 {
+				resp.WriteError(w, "ERR bad expansion")
+				return
+			}
+		default:
+			resp.WriteError(w, "ERR syntax error")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := string(args[1])
+	if _, ok := s.filters[key]; ok {
+		resp.WriteError(w, "ERR item exists")
+		return
+	}
+	rf := s.newFilter()
+	if err := rf.Reserve(capacity, errorRate, uint32(expansion)); err != nil {
+		if errors.Is(err, bloomfilter.ErrTooLarge) {
+			resp.WriteError(w, "ERR "+err.Error())
+		} else {
+			resp.WriteError(w, "ERR could not create filter")
+		}
+		return
+	}
+	s.filters[key] = rf
+	resp.WriteSimple(w, "OK")
+}
+
+// newFilter returns an empty filter within s.Limits.
+func (s *Server) newFilter() *bloomfilter.RedisBloomFilter {
+	limits := s.Limits
+	if limits.MaxBits == 0 {
+		limits.MaxBits = defaultMaxBits
+	}
+	return &bloomfilter.RedisBloomFilter{Limits: limits}
+}
+
+// getOrCreate returns the filter under key, creating it with the defaults
+// if it is missing. It writes an error reply and returns nil if the
+// defaults are over s.Limits.
+func (s *Server) getOrCreate(w *bufio.Writer, key string) *bloomfilter.RedisBloomFilter {
+	if rf := s.Filter(key); rf != nil {
+		return rf
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rf, ok := s.filters[key]
+	if !ok {
+		rf = s.newFilter()
+		if err := rf.Reserve(defaultCapacity, defaultErrorRate, defaultExpansion); err != nil {
+			resp.WriteError(w, "ERR "+err.Error())
+			return nil
+		}
+		s.filters[key] = rf
+	}
+	return rf
+}
+
+func (s *Server) scanDump(w *bufio.Writer, args [][]byte) {
+	iter, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		resp.WriteError(w, "ERR invalid iterator")
+		return
+	}
+	rf := s.Filter(string(args[1]))
+	if rf == nil {
+		resp.WriteError(w, "ERR not found")
+		return
+	}
+	next, data := rf.ScanDump(iter)
+	resp.WriteArrayHeader(w, 2)
+	resp.WriteInt(w, next)
+	resp.WriteBulk(w, data)
+}
+
+func (s *Server) loadChunk(w *bufio.Writer, args [][]byte) {
+	iter, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		resp.WriteError(w, "ERR invalid iterator")
+		return
+	}
+	// The zero RedisBloomFilter is ready for LoadChunk, which rebuilds it
+	// from the header chunk. A new key is only stored once that succeeds, so
+	// a bad header leaves nothing behind.
+	key := string(args[1])
+	rf := s.Filter(key)
+	if rf == nil {
+		rf = s.newFilter()
+		if err := rf.LoadChunk(iter, args[3]); err != nil {
+			resp.WriteError(w, "ERR "+err.Error())
+			return
+		}
+		s.mu.Lock()
+		s.filters[key] = rf
+		s.mu.Unlock()
+	} else if err := rf.LoadChunk(iter, args[3]); err != nil {
+		resp.WriteError(w, "ERR "+err.Error())
+		return
+	}
+	resp.WriteSimple(w, "OK")
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
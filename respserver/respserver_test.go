@@ -0,0 +1,90 @@
+package respserver
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hriday-13th/bloom-filter/internal/resp"
+)
+
+func dial(t *testing.T, s *Server) *resp.Conn {
+	t.Helper()
+	client, conn := net.Pipe()
+	go s.ServeConn(conn)
+	c := resp.NewConn(client)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestFilterLimits(t *testing.T) {
+	s := New()
+	s.Limits.MaxBits = 4096
+	c := dial(t, s)
+
+	var e resp.Error
+	if _, err := c.Do("BF.RESERVE", "big", "0.01", "1000000"); !errors.As(err, &e) || !strings.Contains(string(e), "MaxBits") {
+		t.Errorf("BF.RESERVE over MaxBits: got %v", err)
+	}
+	if s.Filter("big") != nil {
+		t.Error("BF.RESERVE over MaxBits created a filter")
+	}
+
+	// BF.ADD creates a scaling filter, which stops growing at the limit.
+	for i := 0; i < 2000; i++ {
+		if _, err := c.Do("BF.ADD", "auto", "item"+strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var n int
+	rf := s.Filter("auto")
+	for iter, data := rf.ScanDump(1); iter != 0; iter, data = rf.ScanDump(iter) {
+		n += len(data)
+	}
+	if n > 4096/8 {
+		t.Errorf("filter holds %d bytes of bits, over MaxBits", n)
+	}
+}
+
+func TestDefaultFilterLimit(t *testing.T) {
+	c := dial(t, New())
+	if _, err := c.Do("BF.RESERVE", "big", "0.01", strconv.FormatUint(1<<40, 10)); err == nil {
+		t.Error("BF.RESERVE of 2^40 items succeeded without limits set")
+	}
+}
+
+func TestArgumentLimits(t *testing.T) {
+	s := New()
+	s.MaxBulkLen = 8
+	c := dial(t, s)
+
+	if v, err := c.Do("BF.ADD", "k", "12345678"); err != nil || v.Int != 1 {
+		t.Fatalf("BF.ADD at MaxBulkLen: got %v, %v", v, err)
+	}
+	var e resp.Error
+	if _, err := c.Do("BF.ADD", "k", "123456789"); !errors.As(err, &e) || !strings.HasPrefix(string(e), "ERR Protocol error") {
+		t.Errorf("BF.ADD over MaxBulkLen: got %v", err)
+	}
+	if _, err := c.Receive(); err == nil {
+		t.Error("connection still open after a protocol error")
+	}
+}
+
+// A header chunk that fails to load must not leave an empty filter behind,
+// which would refuse every later BF.ADD and BF.RESERVE.
+func TestLoadChunkBadHeader(t *testing.T) {
+	s := New()
+	c := dial(t, s)
+
+	if _, err := c.Do("BF.LOADCHUNK", "k", "1", "not a header"); err == nil {
+		t.Fatal("BF.LOADCHUNK of a bad header succeeded")
+	}
+	if s.Filter("k") != nil {
+		t.Error("a failed BF.LOADCHUNK stored a filter")
+	}
+	if v, err := c.Do("BF.ADD", "k", "a"); err != nil || v.Int != 1 {
+		t.Errorf("BF.ADD after a failed load = %d, %v; want 1", v.Int, err)
+	}
+}
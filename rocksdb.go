@@ -0,0 +1,162 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	rocksCacheLineBytes = 64
+	rocksCacheLineBits  = rocksCacheLineBytes * 8
+	rocksMaxProbes      = 30
+	rocksBloomHashSeed  = 0xbc9f1d34
+)
+
+var ErrInvalidRocksDBFilter = errors.New("bloomfilter: invalid rocksdb filter block")
+
+// RocksDBFilterBuilder produces filter blocks in the layout of RocksDB's
+// legacy full filter (LegacyBloomBitsBuilder): odd-numbered 64-byte cache
+// lines, a 32-bit MurmurHash1 of each key, all probes within one line, and a
+// five-byte trailer holding the probe count and line count. Every RocksDB
+// release can read this format.
+type RocksDBFilterBuilder struct {
+	bitsPerKey int
+	numProbes  int
+	hashes     []uint32
+}
+
+func NewRocksDBFilterBuilder(bitsPerKey int) *RocksDBFilterBuilder {
+	// RocksDB rounds down to keep probing slightly cheaper.
+	numProbes := int(float64(bitsPerKey) * 0.69)
+	numProbes = min(max(numProbes, 1), rocksMaxProbes)
+	return &RocksDBFilterBuilder{bitsPerKey: bitsPerKey, numProbes: numProbes}
+}
+
+// Add queues key for the filter. Like RocksDB, consecutive duplicate keys are
+// counted once.
+func (b *RocksDBFilterBuilder) Add(key []byte) {
+	h := rocksBloomHash(key)
+	if n := len(b.hashes); n == 0 || b.hashes[n-1] != h {
+		b.hashes = append(b.hashes, h)
+	}
+}
+
+// Finish encodes the filter block and resets the builder.
+func (b *RocksDBFilterBuilder) Finish() []byte {
+	var totalBits, numLines uint32
+	if len(b.hashes) > 0 {
+		bits := min(uint64(len(b.hashes))*uint64(b.bitsPerKey), 0xffff0000)
+		numLines = uint32((bits + rocksCacheLineBits - 1) / rocksCacheLineBits)
+		// An odd line count brings more hash bits into the line choice.
+		if numLines%2 == 0 {
+			numLines++
+		}
+		totalBits = numLines * rocksCacheLineBits
+	}
+
+	data := make([]byte, totalBits/8+5)
+	for _, h := range b.hashes {
+		rocksAddHash(data, h, numLines, b.numProbes)
+	}
+	data[totalBits/8] = byte(b.numProbes)
+	binary.LittleEndian.PutUint32(data[totalBits/8+1:], numLines)
+
+	b.hashes = b.hashes[:0]
+	return data
+}
+
+// RocksDBFilter queries a legacy full filter block.
+type RocksDBFilter struct {
+	data          []byte
+	numLines      uint32
+	numProbes     int
+	lineBytesLog2 uint
+}
+
+// ParseRocksDBFilter reads a filter block produced by RocksDBFilterBuilder or
+// by RocksDB's legacy Bloom builder. data is not copied. Blocks written by
+// the newer format_version=5 and Ribbon builders are rejected.
+func ParseRocksDBFilter(data []byte) (*RocksDBFilter, error) {
+	if len(data) < 5 {
+		return nil, ErrInvalidRocksDBFilter
+	}
+	bitsLen := len(data) - 5
+	numProbes := int(int8(data[bitsLen]))
+	numLines := binary.LittleEndian.Uint32(data[bitsLen+1:])
+	if numProbes < 1 || numProbes > rocksMaxProbes {
+		return nil, ErrInvalidRocksDBFilter
+	}
+
+	f := &RocksDBFilter{data: data[:bitsLen], numLines: numLines, numProbes: numProbes}
+	if numLines == 0 {
+		if bitsLen != 0 {
+			return nil, ErrInvalidRocksDBFilter
+		}
+		return f, nil
+	}
+	if bitsLen%int(numLines) != 0 {
+		return nil, ErrInvalidRocksDBFilter
+	}
+	lineBytes := bitsLen / int(numLines)
+	if lineBytes&(lineBytes-1) != 0 {
+		return nil, ErrInvalidRocksDBFilter
+	}
+	for 1<<f.lineBytesLog2 < lineBytes {
+		f.lineBytesLog2++
+	}
+	return f, nil
+}
+
+func (f *RocksDBFilter) Contains(key []byte) bool {
+	if f.numLines == 0 {
+		return false
+	}
+	h := rocksBloomHash(key)
+	line := f.data[int(h%f.numLines)<<f.lineBytesLog2:]
+	lineBitsMask := uint32(1)<<(f.lineBytesLog2+3) - 1
+	delta := h>>17 | h<<15
+	for i := 0; i < f.numProbes; i++ {
+		bitpos := h & lineBitsMask
+		if line[bitpos/8]&(1<<(bitpos%8)) == 0 {
+			return false
+		}
+		h += delta
+	}
+	return true
+}
+
+func rocksAddHash(data []byte, h, numLines uint32, numProbes int) {
+	line := data[(h%numLines)*rocksCacheLineBytes:]
+	delta := h>>17 | h<<15
+	for i := 0; i < numProbes; i++ {
+		bitpos := h % rocksCacheLineBits
+		line[bitpos/8] |= 1 << (bitpos % 8)
+		h += delta
+	}
+}
+
+// rocksBloomHash is RocksDB's BloomHash, a MurmurHash1 variant. The trailing
+// bytes are sign-extended, as in the reference implementation, because the
+// hash is part of the on-disk format.
+func rocksBloomHash(data []byte) uint32 {
+	const m = 0xc6a4a793
+	h := uint32(rocksBloomHashSeed) ^ uint32(len(data))*m
+	for ; len(data) >= 4; data = data[4:] {
+		h += binary.LittleEndian.Uint32(data)
+		h *= m
+		h ^= h >> 16
+	}
+	switch len(data) {
+	case 3:
+		h += uint32(int8(data[2])) << 16
+		fallthrough
+	case 2:
+		h += uint32(int8(data[1])) << 8
+		fallthrough
+	case 1:
+		h += uint32(int8(data[0]))
+		h *= m
+		h ^= h >> 24
+	}
+	return h
+}
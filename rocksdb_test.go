@@ -0,0 +1,131 @@
+package bloomfilter
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// The golden vectors below come from RocksDB's Hash and the legacy Bloom
+// builder's space calculation and AddHash, built from C, where the trailing
+// bytes of a key are read as signed char.
+
+func TestRocksDBBloomHash(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want uint32
+	}{
+		{"", 0xbc9f1d34},
+		{"a", 0x286e9db0},
+		{"ab", 0x39aca330},
+		{"abc", 0x855d012f},
+		{"abcd", 0xb9c83353},
+		{"\x80", 0x91b755f4},
+		{"\xff\xfe\xfd", 0x644d6f00},
+		{"hello, world", 0x16681015},
+	} {
+		if got := rocksBloomHash([]byte(tc.in)); got != tc.want {
+			t.Errorf("rocksBloomHash(%q) = %#x, want %#x", tc.in, got, tc.want)
+		}
+	}
+}
+
+// Ten bits per key over key0 … key999: six probes in 21 cache lines.
+const (
+	rocksGoldenTrailer = "0615000000"
+	rocksGoldenBlock   = "820ae4db8ba9830de304889f645e9214aaf762354f8db58f766073d27fc50216"
+)
+
+func TestRocksDBGolden(t *testing.T) {
+	b := NewRocksDBFilterBuilder(10)
+	for i := 0; i < 1000; i++ {
+		b.Add([]byte("key" + strconv.Itoa(i)))
+	}
+	data := b.Finish()
+	if got := hex.EncodeToString(data[len(data)-5:]); got != rocksGoldenTrailer {
+		t.Errorf("trailer = %s, want %s", got, rocksGoldenTrailer)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != rocksGoldenBlock {
+		t.Errorf("block hashes to %s, want %s", got, rocksGoldenBlock)
+	}
+
+	f, err := ParseRocksDBFilter(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.Contains([]byte("key" + strconv.Itoa(i))) {
+			t.Fatalf("filter lacks key%d", i)
+		}
+	}
+}
+
+// Lines wider than 64 bytes, as other builders may write, are probed within
+// the whole line.
+func TestRocksDBWideLines(t *testing.T) {
+	const numLines, lineBytes, numProbes = 3, 128, 6
+	data := make([]byte, numLines*lineBytes+5)
+	keys := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	for _, key := range keys {
+		h := rocksBloomHash(key)
+		line := data[(h%numLines)*lineBytes:]
+		delta := h>>17 | h<<15
+		for i := 0; i < numProbes; i++ {
+			bitpos := h % (lineBytes * 8)
+			line[bitpos/8] |= 1 << (bitpos % 8)
+			h += delta
+		}
+	}
+	data[numLines*lineBytes] = numProbes
+	binary.LittleEndian.PutUint32(data[numLines*lineBytes+1:], numLines)
+
+	f, err := ParseRocksDBFilter(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if !f.Contains(key) {
+			t.Errorf("filter lacks %s", key)
+		}
+	}
+}
+
+func TestParseRocksDBFilterInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", []byte{6, 1, 0, 0}},
+		{"no probes", rocksTrailer(64, 0, 1)},
+		{"too many probes", rocksTrailer(64, rocksMaxProbes+1, 1)},
+		{"negative probes", rocksTrailer(64, 0xff, 1)},
+		{"bits without lines", rocksTrailer(64, 6, 0)},
+		{"uneven lines", rocksTrailer(100, 6, 3)},
+		{"line not a power of two", rocksTrailer(96, 6, 1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseRocksDBFilter(tc.data); !errors.Is(err, ErrInvalidRocksDBFilter) {
+				t.Errorf("got %v, want ErrInvalidRocksDBFilter", err)
+			}
+		})
+	}
+
+	// A builder with no keys writes an empty filter that matches nothing.
+	f, err := ParseRocksDBFilter(NewRocksDBFilterBuilder(10).Finish())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Contains([]byte("a")) {
+		t.Error("empty filter contains a key")
+	}
+}
+
+func rocksTrailer(bitsLen int, numProbes byte, numLines uint32) []byte {
+	data := make([]byte, bitsLen, bitsLen+5)
+	data = append(data, numProbes)
+	return binary.LittleEndian.AppendUint32(data, numLines)
+}
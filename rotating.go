@@ -0,0 +1,155 @@
+package bloomfilter
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// RotatingFilter keeps the last few generations of a filter for "recently
+// seen" checks. Items go into the newest generation; once that reaches its
+// false-positive budget, the oldest generation is cleared and takes its
+// place, forgetting what it held. Contains checks every generation, so an
+// item is remembered for at least one generation's worth of adds.
+type RotatingFilter struct {
+	mu     sync.RWMutex
+	gens   []*BloomFilter
+	active int
+	full   atomic.Bool
+}
+
+// NewRotating returns a filter of the given number of generations, each
+// sized like NewWithEstimates for capacity items at fpRate. The combined
+// false-positive rate is up to generations times fpRate. WithBackend is not
+// supported.
+func NewRotating(capacity uint, fpRate float64, generations int, opts ...Option) *RotatingFilter {
+	rf := &RotatingFilter{}
+	opts = append(opts, WithFPHook(fpRate, func(*BloomFilter) { rf.full.Store(true) }))
+	rf.init(capacity, fpRate, generations, opts)
+	return rf
+}
+
+func (rf *RotatingFilter) init(capacity uint, fpRate float64, generations int, opts []Option) {
+	if generations < 1 {
+		panic("bloomfilter: rotating filter needs at least one generation")
+	}
+	rf.gens = make([]*BloomFilter, generations)
+	rf.gens[0] = NewWithEstimates(capacity, fpRate, opts...)
+	if rf.gens[0].backend != nil {
+		panic("bloomfilter: RotatingFilter does not support a Backend")
+	}
+	// Items are hashed once for all generations, so they must hash alike.
+	for i := 1; i < generations; i++ {
+		rf.gens[i] = NewWithEstimates(capacity, fpRate, append(opts, hashedLike(rf.gens[0]))...)
+	}
+}
+
+func (rf *RotatingFilter) add(h1, h2 uint64) {
+	rf.mu.RLock()
+	rf.gens[rf.active].add(h1, h2)
+	rf.mu.RUnlock()
+
+	if rf.full.Load() {
+		rf.mu.Lock()
+		// Another Add may have rotated already.
+		if rf.full.Load() {
+			rf.rotate()
+		}
+		rf.mu.Unlock()
+	}
+}
+
+func (rf *RotatingFilter) contains(h1, h2 uint64) bool {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+	for i := range rf.gens {
+		if rf.generation(i).contains(h1, h2) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rf *RotatingFilter) Add(item []byte) {
+	rf.add(rf.gens[0].hash(item))
+}
+
+func (rf *RotatingFilter) Contains(item []byte) bool {
+	return rf.contains(rf.gens[0].hash(item))
+}
+
+func (rf *RotatingFilter) AddString(item string) {
+	rf.Add(stringBytes(item))
+}
+
+func (rf *RotatingFilter) ContainsString(item string) bool {
+	return rf.Contains(stringBytes(item))
+}
+
+func (rf *RotatingFilter) AddUint64(item uint64) {
+	rf.add(rf.gens[0].seeded(hashUint64(item)))
+}
+
+func (rf *RotatingFilter) ContainsUint64(item uint64) bool {
+	return rf.contains(rf.gens[0].seeded(hashUint64(item)))
+}
+
+// Rotate starts a new generation now, dropping the oldest.
+func (rf *RotatingFilter) Rotate() {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.rotate()
+}
+
+func (rf *RotatingFilter) rotate() {
+	rf.active = (rf.active + 1) % len(rf.gens)
+	rf.gens[rf.active].Reset()
+	rf.full.Store(false)
+}
+
+// generation returns the i-th newest generation.
+func (rf *RotatingFilter) generation(i int) *BloomFilter {
+	return rf.gens[(rf.active-i+len(rf.gens))%len(rf.gens)]
+}
+
+// Count is the number of items added across the retained generations.
+func (rf *RotatingFilter) Count() uint {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+	var n uint
+	for _, gen := range rf.gens {
+		n += gen.Count()
+	}
+	return n
+}
+
+// EstimatedFalsePositiveRate combines the estimates of every generation.
+func (rf *RotatingFilter) EstimatedFalsePositiveRate() float64 {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+	miss := 1.0
+	for _, gen := range rf.gens {
+		miss *= 1 - gen.EstimatedFalsePositiveRate()
+	}
+	return 1 - miss
+}
+
+// Generations returns the generations themselves, not copies, newest first.
+func (rf *RotatingFilter) Generations() []*BloomFilter {
+	rf.mu.RLock()
+	defer rf.mu.RUnlock()
+	gens := make([]*BloomFilter, len(rf.gens))
+	for i := range gens {
+		gens[i] = rf.generation(i)
+	}
+	return gens
+}
+
+func (rf *RotatingFilter) Reset() {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	for _, gen := range rf.gens {
+		gen.Reset()
+	}
+	rf.active = 0
+	rf.full.Store(false)
+}
@@ -0,0 +1,94 @@
+package bloomfilter
+
+import (
+	"strconv"
+	"testing"
+)
+
+// A generation rotates once it reaches its false-positive budget, which for
+// a filter sized by NewWithEstimates is about its capacity.
+func TestRotatingBudget(t *testing.T) {
+	const capacity = 1000
+	rf := NewRotating(capacity, 0.01, 3)
+	var rotations []int
+	for i := 0; i < 5*capacity; i++ {
+		newest := rf.Generations()[0]
+		rf.AddString(strconv.Itoa(i))
+		if rf.Generations()[0] != newest {
+			if rate := newest.EstimatedFalsePositiveRate(); rate < 0.01 {
+				t.Errorf("rotated at a false-positive rate of %f", rate)
+			}
+			rotations = append(rotations, i)
+		}
+	}
+	if len(rotations) < 4 {
+		t.Fatalf("rotated after items %v, want every %d or so", rotations, capacity)
+	}
+	prev := -1
+	for _, at := range rotations {
+		if n := at - prev; n < capacity*9/10 || n > capacity*11/10 {
+			t.Errorf("a generation took %d items, want about %d", n, capacity)
+		}
+		prev = at
+	}
+
+	// The three generations kept still hold all their items; the ones
+	// dropped before them are mostly forgotten.
+	kept := rotations[len(rotations)-3] + 1
+	for i := kept; i < 5*capacity; i++ {
+		if !rf.ContainsString(strconv.Itoa(i)) {
+			t.Fatalf("item %d forgotten", i)
+		}
+	}
+	forgotten := 0
+	for i := 0; i <= rotations[0]; i++ {
+		if !rf.ContainsString(strconv.Itoa(i)) {
+			forgotten++
+		}
+	}
+	if forgotten < rotations[0]*9/10 {
+		t.Errorf("%d of the first generation's %d items forgotten", forgotten, rotations[0]+1)
+	}
+	if got, want := rf.Count(), uint(5*capacity-kept); got != want {
+		t.Errorf("count %d, want the %d items kept", got, want)
+	}
+}
+
+func TestRotatingRotate(t *testing.T) {
+	rf := NewRotating(100, 0.01, 2)
+	rf.AddString("a")
+	rf.AddUint64(7)
+	rf.Rotate()
+	if !rf.ContainsString("a") || !rf.ContainsUint64(7) {
+		t.Error("items dropped a generation early")
+	}
+	rf.AddString("b")
+	rf.Rotate()
+	if rf.ContainsString("a") || rf.ContainsUint64(7) {
+		t.Error("items outlived their generations")
+	}
+	if !rf.ContainsString("b") || rf.Count() != 1 {
+		t.Errorf("want only b, count %d", rf.Count())
+	}
+
+	rf.Reset()
+	if rf.ContainsString("b") || rf.Count() != 0 || rf.EstimatedFalsePositiveRate() != 0 {
+		t.Error("Reset left items behind")
+	}
+}
+
+func TestNewRotatingInvalid(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"no generations": func() { NewRotating(100, 0.01, 0) },
+		"backend":        func() { NewRotating(100, 0.01, 2, WithBackend(NewSparseBackend())) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("NewRotating did not panic")
+				}
+			}()
+			fn()
+		})
+	}
+}
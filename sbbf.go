@@ -0,0 +1,372 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"sync/atomic"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+const (
+	sbbfBlockBytes = 32
+	sbbfMinBytes   = sbbfBlockBytes
+	sbbfMaxBytes   = 128 << 20
+)
+
+var sbbfSalt = [8]uint32{
+	0x47b6137b, 0x44974d91, 0x8824ad5b, 0xa2b7289d,
+	0x705495c7, 0x2df1424b, 0x9efc4947, 0x5c6bfb31,
+}
+
+var ErrInvalidSplitBlock = errors.New("bloomfilter: invalid parquet split block bloom filter")
+
+type sbbfBlock [8]uint32
+
+// SplitBlockFilter implements the Apache Parquet split block Bloom filter:
+// 256-bit blocks of eight 32-bit words, one bit set per word, keyed by the
+// 64-bit xxHash of the plain-encoded value. Bytes and ParseSplitBlock use the
+// bitset layout Parquet stores on disk, so filters can be written into or read
+// from Parquet column chunks.
+type SplitBlockFilter struct {
+	blocks []sbbfBlock
+}
+
+// NewSplitBlock returns a filter of numBytes, rounded up to a whole number of
+// 32-byte blocks.
+func NewSplitBlock(numBytes uint) *SplitBlockFilter {
+	numBlocks := (numBytes + sbbfBlockBytes - 1) / sbbfBlockBytes
+	if numBlocks == 0 {
+		numBlocks = 1
+	}
+	return &SplitBlockFilter{blocks: make([]sbbfBlock, numBlocks)}
+}
+
+// NewSplitBlockWithEstimates sizes a filter for ndv distinct values at the
+// given false-positive rate the way parquet-mr does, rounding up to a power of
+// two between 32 bytes and 128MiB.
+func NewSplitBlockWithEstimates(ndv uint, fpRate float64) *SplitBlockFilter {
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
+	}
+	numBits := -8 * float64(ndv) / math.Log(1-math.Pow(fpRate, 1.0/8))
+	numBytes := uint64(math.Ceil(numBits / 8))
+	numBytes = min(max(numBytes, sbbfMinBytes), sbbfMaxBytes)
+	numBytes = 1 << bits.Len64(numBytes-1)
+	return NewSplitBlock(uint(numBytes))
+}
+
+// ParseSplitBlock wraps a bitset as read from a Parquet file. data is copied.
+func ParseSplitBlock(data []byte) (*SplitBlockFilter, error) {
+	if len(data) == 0 || len(data)%sbbfBlockBytes != 0 {
+		return nil, ErrInvalidSplitBlock
+	}
+	sb := NewSplitBlock(uint(len(data)))
+	for i := range sb.blocks {
+		for j := range sb.blocks[i] {
+			sb.blocks[i][j] = binary.LittleEndian.Uint32(data[i*sbbfBlockBytes+j*4:])
+		}
+	}
+	return sb, nil
+}
+
+// Add inserts the plain encoding of a value: raw bytes for BYTE_ARRAY and
+// FIXED_LEN_BYTE_ARRAY, little-endian for numeric types.
+func (sb *SplitBlockFilter) Add(item []byte) {
+	sb.AddHash(hashing.XXHash64(item, 0))
+}
+
+func (sb *SplitBlockFilter) Contains(item []byte) bool {
+	return sb.ContainsHash(hashing.XXHash64(item, 0))
+}
+
+// AddHash inserts a precomputed xxHash64 value.
+func (sb *SplitBlockFilter) AddHash(hash uint64) {
+	b := &sb.blocks[sb.blockIndex(hash)]
+	key := uint32(hash)
+	for i := range b {
+		atomic.OrUint32(&b[i], sbbfMask(key, i))
+	}
+}
+
+// ContainsHash checks a precomputed xxHash64 value.
+func (sb *SplitBlockFilter) ContainsHash(hash uint64) bool {
+	b := &sb.blocks[sb.blockIndex(hash)]
+	key := uint32(hash)
+	for i := range b {
+		mask := sbbfMask(key, i)
+		if atomic.LoadUint32(&b[i])&mask == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (sb *SplitBlockFilter) blockIndex(hash uint64) uint64 {
+	return (hash >> 32) * uint64(len(sb.blocks)) >> 32
+}
+
+func sbbfMask(key uint32, i int) uint32 {
+	return 1 << ((key * sbbfSalt[i]) >> 27)
+}
+
+// Bytes returns the bitset in Parquet's on-disk layout.
+func (sb *SplitBlockFilter) Bytes() []byte {
+	data := make([]byte, len(sb.blocks)*sbbfBlockBytes)
+	for i := range sb.blocks {
+		for j := range sb.blocks[i] {
+			binary.LittleEndian.PutUint32(data[i*sbbfBlockBytes+j*4:], atomic.LoadUint32(&sb.blocks[i][j]))
+		}
+	}
+	return data
+}
+
+// AppendParquet appends the Thrift compact-encoded BloomFilterHeader
+// (BLOCK, XXHASH, UNCOMPRESSED) followed by the bitset, which is how a
+// Parquet writer places the filter at a column chunk's bloom_filter_offset.
+func (sb *SplitBlockFilter) AppendParquet(dst []byte) []byte {
+	data := sb.Bytes()
+
+	dst = append(dst, 0x15)
+	dst = binary.AppendUvarint(dst, zigzag32(int32(len(data))))
+	for i := 0; i < 3; i++ {
+		// A struct field holding a union whose field 1 is an empty struct.
+		dst = append(dst, 0x1c, 0x1c, 0x00, 0x00)
+	}
+	dst = append(dst, 0x00)
+
+	return append(dst, data...)
+}
+
+// ReadParquetSplitBlock parses a BloomFilterHeader and bitset as written by
+// AppendParquet or any Parquet writer, and returns the filter together with
+// the number of bytes consumed.
+func ReadParquetSplitBlock(data []byte) (*SplitBlockFilter, int, error) {
+	r := thriftReader{data: data}
+	numBytes := int32(-1)
+	algorithm, hash, compression := -1, -1, -1
+
+	var lastID int16
+	for {
+		id, typ, ok := r.fieldHeader(&lastID)
+		if !ok {
+			return nil, 0, ErrInvalidSplitBlock
+		}
+		if typ == thriftStop {
+			break
+		}
+		switch {
+		case id == 1 && typ == thriftI32:
+			v, ok := r.varint()
+			if !ok {
+				return nil, 0, ErrInvalidSplitBlock
+			}
+			numBytes = unzigzag32(v)
+		case id >= 2 && id <= 4 && typ == thriftStruct:
+			member, ok := r.unionMember()
+			if !ok {
+				return nil, 0, ErrInvalidSplitBlock
+			}
+			switch id {
+			case 2:
+				algorithm = member
+			case 3:
+				hash = member
+			case 4:
+				compression = member
+			}
+		default:
+			if !r.skip(typ) {
+				return nil, 0, ErrInvalidSplitBlock
+			}
+		}
+	}
+
+	if algorithm != 1 || hash != 1 || compression != 1 || numBytes <= 0 {
+		return nil, 0, ErrInvalidSplitBlock
+	}
+	end := r.pos + int(numBytes)
+	if end > len(data) {
+		return nil, 0, ErrInvalidSplitBlock
+	}
+	sb, err := ParseSplitBlock(data[r.pos:end])
+	if err != nil {
+		return nil, 0, err
+	}
+	return sb, end, nil
+}
+
+func zigzag32(v int32) uint64 {
+	return uint64(uint32(v<<1) ^ uint32(v>>31))
+}
+
+func unzigzag32(v uint64) int32 {
+	return int32(uint32(v)>>1) ^ -int32(v&1)
+}
+
+const (
+	thriftStop   = 0
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftReader decodes just enough of the Thrift compact protocol to read a
+// BloomFilterHeader and skip fields it does not know.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() (byte, bool) {
+	if r.pos >= len(r.data) {
+		return 0, false
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, true
+}
+
+func (r *thriftReader) varint() (uint64, bool) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, false
+	}
+	r.pos += n
+	return v, true
+}
+
+func (r *thriftReader) fieldHeader(lastID *int16) (int16, byte, bool) {
+	b, ok := r.byte()
+	if !ok {
+		return 0, 0, false
+	}
+	typ := b & 0x0f
+	if typ == thriftStop {
+		return 0, thriftStop, true
+	}
+	if delta := int16(b >> 4); delta != 0 {
+		*lastID += delta
+	} else {
+		v, ok := r.varint()
+		if !ok {
+			return 0, 0, false
+		}
+		*lastID = int16(unzigzag32(v))
+	}
+	return *lastID, typ, true
+}
+
+// unionMember reads a union struct and returns the id of its set field.
+func (r *thriftReader) unionMember() (int, bool) {
+	member := -1
+	var lastID int16
+	for {
+		id, typ, ok := r.fieldHeader(&lastID)
+		if !ok {
+			return 0, false
+		}
+		if typ == thriftStop {
+			return member, true
+		}
+		member = int(id)
+		if !r.skip(typ) {
+			return 0, false
+		}
+	}
+}
+
+func (r *thriftReader) skip(typ byte) bool {
+	switch typ {
+	case thriftTrue, thriftFalse:
+		return true
+	case thriftByte:
+		_, ok := r.byte()
+		return ok
+	case thriftI16, thriftI32, thriftI64:
+		_, ok := r.varint()
+		return ok
+	case thriftDouble:
+		r.pos += 8
+		return r.pos <= len(r.data)
+	case thriftBinary:
+		n, ok := r.varint()
+		if !ok || n > uint64(len(r.data)-r.pos) {
+			return false
+		}
+		r.pos += int(n)
+		return true
+	case thriftList, thriftSet:
+		b, ok := r.byte()
+		if !ok {
+			return false
+		}
+		size, elem := uint64(b>>4), b&0x0f
+		if size == 15 {
+			if size, ok = r.varint(); !ok {
+				return false
+			}
+		}
+		return r.skipN(elem, size)
+	case thriftMap:
+		size, ok := r.varint()
+		if !ok {
+			return false
+		}
+		if size == 0 {
+			return true
+		}
+		kv, ok := r.byte()
+		if !ok {
+			return false
+		}
+		for i := uint64(0); i < size; i++ {
+			if !r.skip(kv>>4) || !r.skip(kv&0x0f) {
+				return false
+			}
+		}
+		return true
+	case thriftStruct:
+		var lastID int16
+		for {
+			_, typ, ok := r.fieldHeader(&lastID)
+			if !ok {
+				return false
+			}
+			if typ == thriftStop {
+				return true
+			}
+			if !r.skip(typ) {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+func (r *thriftReader) skipN(typ byte, n uint64) bool {
+	for i := uint64(0); i < n; i++ {
+		// Booleans inside containers take a byte each.
+		if typ == thriftTrue || typ == thriftFalse {
+			if _, ok := r.byte(); !ok {
+				return false
+			}
+			continue
+		}
+		if !r.skip(typ) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package bloomfilter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// The golden vectors below come from github.com/parquet-go/parquet-go/bloom
+// and its Thrift encoding of format.BloomFilterHeader, hashing with
+// github.com/cespare/xxhash/v2.
+
+func TestSplitBlockXXHash(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"hello, world", 0xb33a384e6d1b1242},
+	} {
+		if got := hashing.XXHash64([]byte(tc.in), 0); got != tc.want {
+			t.Errorf("XXHash64(%q) = %#x, want %#x", tc.in, got, tc.want)
+		}
+	}
+}
+
+// A 1 KiB filter holding item0 … item499.
+const (
+	sbbfGoldenHeader = "1580101c1c00001c1c00001c1c000000"
+	sbbfGoldenBits   = "c80b39b15c08362529d57ee5156ae8066434a2295501e27dc7d854d570f532b5"
+)
+
+func TestSplitBlockGolden(t *testing.T) {
+	sb := NewSplitBlock(1024)
+	for i := 0; i < 500; i++ {
+		sb.Add([]byte("item" + strconv.Itoa(i)))
+	}
+	sum := sha256.Sum256(sb.Bytes())
+	if got := hex.EncodeToString(sum[:]); got != sbbfGoldenBits {
+		t.Errorf("bits hash to %s, want %s", got, sbbfGoldenBits)
+	}
+
+	data := sb.AppendParquet(nil)
+	if got := hex.EncodeToString(data[:len(data)-1024]); got != sbbfGoldenHeader {
+		t.Errorf("header = %s, want %s", got, sbbfGoldenHeader)
+	}
+	parsed, n, err := ReadParquetSplitBlock(data)
+	if err != nil || n != len(data) {
+		t.Fatalf("ReadParquetSplitBlock = %d, %v; want %d, nil", n, err, len(data))
+	}
+	for i := 0; i < 500; i++ {
+		if !parsed.Contains([]byte("item" + strconv.Itoa(i))) {
+			t.Fatalf("parsed filter lacks item%d", i)
+		}
+	}
+}
+
+func TestReadParquetSplitBlockInvalid(t *testing.T) {
+	valid := NewSplitBlock(64).AppendParquet(nil)
+	header := len(valid) - 64
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"header only", valid[:header]},
+		{"truncated bitset", valid[:len(valid)-1]},
+		{"no stop", valid[:header-1]},
+		// NumBytes 0, then 63, which is not a whole number of blocks.
+		{"no bytes", append([]byte{0x15, 0x00}, valid[3:]...)},
+		{"partial block", append([]byte{0x15, 0x7e}, valid[3:]...)},
+		// Algorithm, hash and compression members other than 1.
+		{"unknown algorithm", splitBlockHeaderWith(valid, 4, 0x2c)},
+		{"unknown hash", splitBlockHeaderWith(valid, 8, 0x2c)},
+		{"gzip", splitBlockHeaderWith(valid, 12, 0x2c)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := ReadParquetSplitBlock(tc.data); !errors.Is(err, ErrInvalidSplitBlock) {
+				t.Errorf("got %v, want ErrInvalidSplitBlock", err)
+			}
+		})
+	}
+
+	for _, n := range []int{0, 33} {
+		if _, err := ParseSplitBlock(make([]byte, n)); !errors.Is(err, ErrInvalidSplitBlock) {
+			t.Errorf("ParseSplitBlock of %d bytes: got %v, want ErrInvalidSplitBlock", n, err)
+		}
+	}
+}
+
+// splitBlockHeaderWith returns data with the byte at i replaced by b.
+func splitBlockHeaderWith(data []byte, i int, b byte) []byte {
+	data = append([]byte(nil), data...)
+	data[i] = b
+	return data
+}
@@ -0,0 +1,191 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+type scalableStage struct {
+	filter   *BloomFilter
+	capacity uint
+	fpRate   float64
+}
+
+// ScalableBloomFilter grows by appending sub-filters, each growthFactor times
+// larger than the last and with its false-positive rate multiplied by the
+// tightening ratio, so the compound rate stays under the configured target no
+// matter how many items are added.
+type ScalableBloomFilter struct {
+	mu              sync.RWMutex
+	stages          []scalableStage
+	initialCapacity uint
+	fpRate          float64
+	growthFactor    uint
+	tighteningRatio float64
+	count           uint
+}
+
+func NewScalable(initialCapacity uint, fpRate float64, growthFactor uint, tighteningRatio float64) *ScalableBloomFilter {
+	if fpRate <= 0 || fpRate >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
+	}
+	if tighteningRatio <= 0 || tighteningRatio >= 1 {
+		panic("bloomfilter: tightening ratio must be in (0, 1)")
+	}
+	if growthFactor == 0 {
+		growthFactor = 1
+	}
+	if initialCapacity == 0 {
+		initialCapacity = 1
+	}
+
+	sf := &ScalableBloomFilter{
+		initialCapacity: initialCapacity,
+		fpRate:          fpRate,
+		growthFactor:    growthFactor,
+		tighteningRatio: tighteningRatio,
+	}
+	sf.grow()
+	return sf
+}
+
+// grow appends the next stage. The first stage gets fpRate*(1-r) so that
+// the geometric series over all stages sums to at most fpRate.
+func (sf *ScalableBloomFilter) grow() {
+	capacity := sf.initialCapacity
+	fpRate := sf.fpRate * (1 - sf.tighteningRatio)
+	if n := len(sf.stages); n > 0 {
+		last := sf.stages[n-1]
+		capacity = last.capacity * sf.growthFactor
+		fpRate = last.fpRate * sf.tighteningRatio
+	}
+	sf.stages = append(sf.stages, scalableStage{
+		filter:   NewWithEstimates(capacity, fpRate),
+		capacity: capacity,
+		fpRate:   fpRate,
+	})
+}
+
+func (sf *ScalableBloomFilter) Add(item []byte) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	current := sf.stages[len(sf.stages)-1]
+	if current.filter.Count() >= current.capacity {
+		sf.grow()
+		current = sf.stages[len(sf.stages)-1]
+	}
+	current.filter.Add(item)
+	sf.count++
+}
+
+func (sf *ScalableBloomFilter) Contains(item []byte) bool {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+
+	for i := len(sf.stages) - 1; i >= 0; i-- {
+		if sf.stages[i].filter.Contains(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sf *ScalableBloomFilter) Count() uint {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.count
+}
+
+// EstimatedFalsePositiveRate combines the estimates of every stage.
+func (sf *ScalableBloomFilter) EstimatedFalsePositiveRate() float64 {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+
+	miss := 1.0
+	for _, stage := range sf.stages {
+		miss *= 1 - stage.filter.EstimatedFalsePositiveRate()
+	}
+	return 1 - miss
+}
+
+func (sf *ScalableBloomFilter) Serialize() []byte {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+
+	serialized := make([]byte, 48)
+	binary.LittleEndian.PutUint64(serialized[0:8], uint64(sf.initialCapacity))
+	binary.LittleEndian.PutUint64(serialized[8:16], math.Float64bits(sf.fpRate))
+	binary.LittleEndian.PutUint64(serialized[16:24], uint64(sf.growthFactor))
+	binary.LittleEndian.PutUint64(serialized[24:32], math.Float64bits(sf.tighteningRatio))
+	binary.LittleEndian.PutUint64(serialized[32:40], uint64(sf.count))
+	binary.LittleEndian.PutUint64(serialized[40:48], uint64(len(sf.stages)))
+
+	for _, stage := range sf.stages {
+		data := stage.filter.Serialize()
+		header := make([]byte, 32)
+		binary.LittleEndian.PutUint64(header[0:8], uint64(stage.capacity))
+		binary.LittleEndian.PutUint64(header[8:16], math.Float64bits(stage.fpRate))
+		binary.LittleEndian.PutUint64(header[16:24], uint64(stage.filter.numHashes))
+		binary.LittleEndian.PutUint64(header[24:32], uint64(len(data)))
+		serialized = append(serialized, header...)
+		serialized = append(serialized, data...)
+	}
+
+	return serialized
+}
+
+func DeserializeScalable(data []byte) (*ScalableBloomFilter, error) {
+	if len(data) < 48 {
+		return nil, ErrInvalidFormat
+	}
+	sf := &ScalableBloomFilter{
+		initialCapacity: uint(binary.LittleEndian.Uint64(data[0:8])),
+		fpRate:          math.Float64frombits(binary.LittleEndian.Uint64(data[8:16])),
+		growthFactor:    uint(binary.LittleEndian.Uint64(data[16:24])),
+		tighteningRatio: math.Float64frombits(binary.LittleEndian.Uint64(data[24:32])),
+		count:           uint(binary.LittleEndian.Uint64(data[32:40])),
+	}
+	numStages := binary.LittleEndian.Uint64(data[40:48])
+	// Add and grow rely on these; NaN fails the range checks too.
+	if numStages == 0 || sf.growthFactor == 0 || !(sf.tighteningRatio > 0 && sf.tighteningRatio < 1) || !(sf.fpRate > 0 && sf.fpRate < 1) {
+		return nil, ErrInvalidFormat
+	}
+
+	offset := uint64(48)
+	for i := uint64(0); i < numStages; i++ {
+		if uint64(len(data))-offset < 32 {
+			return nil, ErrInvalidFormat
+		}
+		header := data[offset : offset+32]
+		length := binary.LittleEndian.Uint64(header[24:32])
+		offset += 32
+		if uint64(len(data))-offset < length {
+			return nil, ErrInvalidFormat
+		}
+
+		filter, err := Deserialize(data[offset : offset+length])
+		if err != nil {
+			return nil, err
+		}
+		// Stages serialized in version 1 lost their hash count.
+		numHashes := binary.LittleEndian.Uint64(header[16:24])
+		if numHashes < 1 || numHashes > MaxHashes {
+			return nil, ErrInvalidFormat
+		}
+		filter.numHashes = int(numHashes)
+		offset += length
+
+		stage := scalableStage{
+			filter:   filter,
+			capacity: uint(binary.LittleEndian.Uint64(header[0:8])),
+			fpRate:   math.Float64frombits(binary.LittleEndian.Uint64(header[8:16])),
+		}
+		if !(stage.fpRate > 0 && stage.fpRate < 1) {
+			return nil, ErrInvalidFormat
+		}
+		sf.stages = append(sf.stages, stage)
+	}
+	return sf, nil
+}
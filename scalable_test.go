@@ -0,0 +1,58 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestScalableRoundTrip(t *testing.T) {
+	sf := NewScalable(100, 0.01, 2, 0.5)
+	for i := 0; i < 1000; i++ {
+		sf.Add([]byte(strconv.Itoa(i)))
+	}
+	decoded, err := DeserializeScalable(sf.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Count() != 1000 {
+		t.Errorf("Count = %d, want 1000", decoded.Count())
+	}
+	for i := 0; i < 1000; i++ {
+		if !decoded.Contains([]byte(strconv.Itoa(i))) {
+			t.Fatalf("decoded filter lacks %d", i)
+		}
+	}
+	decoded.Add([]byte("more"))
+}
+
+func TestDeserializeScalableInvalid(t *testing.T) {
+	valid := NewScalable(100, 0.01, 2, 0.5).Serialize()
+	for _, tc := range []struct {
+		name   string
+		mutate func([]byte)
+	}{
+		{"no stages", func(b []byte) { binary.LittleEndian.PutUint64(b[40:], 0) }},
+		{"zero growth", func(b []byte) { binary.LittleEndian.PutUint64(b[16:], 0) }},
+		{"zero tightening", func(b []byte) { binary.LittleEndian.PutUint64(b[24:], 0) }},
+		{"tightening of 1", func(b []byte) { binary.LittleEndian.PutUint64(b[24:], math.Float64bits(1)) }},
+		{"NaN tightening", func(b []byte) { binary.LittleEndian.PutUint64(b[24:], math.Float64bits(math.NaN())) }},
+		{"NaN rate", func(b []byte) { binary.LittleEndian.PutUint64(b[8:], math.Float64bits(math.NaN())) }},
+		{"stage rate of 0", func(b []byte) { binary.LittleEndian.PutUint64(b[48+8:], 0) }},
+		{"stage past end", func(b []byte) { binary.LittleEndian.PutUint64(b[48+24:], math.MaxUint64) }},
+		{"extra stage", func(b []byte) { binary.LittleEndian.PutUint64(b[40:], 2) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := append([]byte(nil), valid...)
+			tc.mutate(data)
+			if _, err := DeserializeScalable(data); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("got %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+	if _, err := DeserializeScalable(valid[:47]); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("short input: got %v, want ErrInvalidFormat", err)
+	}
+}
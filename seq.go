@@ -0,0 +1,61 @@
+package bloomfilter
+
+import "iter"
+
+// seqBatch is how many items AddSeq sends to a Backend at once.
+const seqBatch = 1024
+
+// AddSeq adds every item of seq and returns how many there were. Items need
+// only stay valid until the next one is produced. With a Backend, probes go
+// out in batches, as with AddMany.
+func (bf *BloomFilter) AddSeq(seq iter.Seq[[]byte]) int {
+	n := 0
+	if bf.backend == nil {
+		for item := range seq {
+			bf.Add(item)
+			n++
+		}
+		return n
+	}
+
+	positions := make([]uint64, 0, seqBatch*bf.numHashes)
+	batched := 0
+	for item := range seq {
+		h1, h2 := bf.hash(item)
+		for i := 0; i < bf.numHashes; i++ {
+			positions = append(positions, bf.location(h1, h2, i))
+		}
+		n++
+		if batched++; batched == seqBatch {
+			bf.setPositions(positions, batched)
+			positions, batched = positions[:0], 0
+		}
+	}
+	if batched > 0 {
+		bf.setPositions(positions, batched)
+	}
+	return n
+}
+
+// AddStringSeq is AddSeq for string keys.
+func (bf *BloomFilter) AddStringSeq(seq iter.Seq[string]) int {
+	return bf.AddSeq(func(yield func([]byte) bool) {
+		for item := range seq {
+			if !yield(stringBytes(item)) {
+				return
+			}
+		}
+	})
+}
+
+// ContainsSeq returns a sequence pairing each item of seq with Contains of
+// it, computed as the pairs are consumed.
+func (bf *BloomFilter) ContainsSeq(seq iter.Seq[[]byte]) iter.Seq2[[]byte, bool] {
+	return func(yield func([]byte, bool) bool) {
+		for item := range seq {
+			if !yield(item, bf.Contains(item)) {
+				return
+			}
+		}
+	}
+}
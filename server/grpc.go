@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+	"github.com/hriday-13th/bloom-filter/bloompb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterGRPC serves the BloomService API from s's filters on r, alongside
+// or instead of HTTP.
+func (s *Server) RegisterGRPC(r grpc.ServiceRegistrar) {
+	bloompb.RegisterBloomServiceServer(r, &grpcServer{s: s})
+}
+
+type grpcServer struct {
+	bloompb.UnimplementedBloomServiceServer
+	s *Server
+}
+
+func (g *grpcServer) filter(name string) (*bloomfilter.BloomFilter, error) {
+	bf := g.s.Filter(name)
+	if bf == nil {
+		return nil, status.Errorf(codes.NotFound, "no such filter %q", name)
+	}
+	return bf, nil
+}
+
+func (g *grpcServer) Create(ctx context.Context, req *bloompb.CreateRequest) (*bloompb.CreateResponse, error) {
+	err := g.s.Create(req.GetName(), Params{
+		Capacity: uint(req.GetCapacity()),
+		FPRate:   req.GetFpRate(),
+		Bits:     req.GetBits(),
+		Hashes:   int(req.GetHashes()),
+	})
+	switch {
+	case errors.Is(err, bloomfilter.ErrTooLarge), errors.Is(err, ErrTooManyFilters):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &bloompb.CreateResponse{}, nil
+}
+
+func (g *grpcServer) Add(ctx context.Context, req *bloompb.AddRequest) (*bloompb.AddResponse, error) {
+	bf, err := g.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	bf.Add(req.GetItem())
+	return &bloompb.AddResponse{Added: 1}, nil
+}
+
+func (g *grpcServer) MultiAdd(ctx context.Context, req *bloompb.MultiAddRequest) (*bloompb.AddResponse, error) {
+	bf, err := g.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	bf.AddMany(req.GetItems())
+	return &bloompb.AddResponse{Added: uint64(len(req.GetItems()))}, nil
+}
+
+func (g *grpcServer) Contains(ctx context.Context, req *bloompb.ContainsRequest) (*bloompb.ContainsResponse, error) {
+	bf, err := g.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &bloompb.ContainsResponse{Present: bf.Contains(req.GetItem())}, nil
+}
+
+func (g *grpcServer) MultiContains(ctx context.Context, req *bloompb.MultiContainsRequest) (*bloompb.MultiContainsResponse, error) {
+	bf, err := g.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &bloompb.MultiContainsResponse{Present: bf.ContainsMany(req.GetItems())}, nil
+}
+
+func (g *grpcServer) Stats(ctx context.Context, req *bloompb.StatsRequest) (*bloompb.StatsResponse, error) {
+	bf, err := g.filter(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	st := statsOf(bf)
+	return &bloompb.StatsResponse{
+		Count:             st.Count,
+		BitsSet:           st.BitsSet,
+		FillRatio:         st.FillRatio,
+		ApproxCardinality: st.ApproxCardinality,
+		EstimatedFpRate:   st.EstimatedFPRate,
+		Size:              st.Size,
+		NumHashes:         uint32(st.NumHashes),
+		Hasher:            st.Hasher,
+		Queries:           st.Queries,
+		Positives:         st.Positives,
+	}, nil
+}
+
+// Export streams a snapshot, so that writers are not held up by a slow
+// client, in the chunks WriteTo writes.
+func (g *grpcServer) Export(req *bloompb.ExportRequest, stream grpc.ServerStreamingServer[bloompb.ExportChunk]) error {
+	bf, err := g.filter(req.GetName())
+	if err != nil {
+		return err
+	}
+	s := bf.Snapshot()
+	if s == nil {
+		return status.Error(codes.Unavailable, bf.Err().Error())
+	}
+	defer s.Close()
+	_, err = s.WriteTo(chunkWriter{stream})
+	return err
+}
+
+// chunkWriter sends each write as one ExportChunk.
+type chunkWriter struct {
+	stream grpc.ServerStreamingServer[bloompb.ExportChunk]
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	// The stream may hold on to the message, and WriteTo reuses p.
+	if err := w.stream.Send(&bloompb.ExportChunk{Data: bytes.Clone(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
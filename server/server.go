@@ -0,0 +1,348 @@
+// Package server exposes named filters over HTTP, so that one large filter
+// can sit behind an API instead of being replicated into every service.
+//
+//	PUT    /filters/{name}           create, from JSON parameters, or upload a serialized filter
+//	GET    /filters/{name}           download the filter in the Serialize format
+//	DELETE /filters/{name}           remove the filter
+//	POST   /filters/{name}/items     add items
+//	GET    /filters/{name}/contains  test the keys given as ?key=
+//	GET    /filters/{name}/stats     report size and fill statistics
+//
+// PUT with a JSON body takes {"capacity": n, "fp_rate": p} or {"bits": m,
+// "hashes": k}; with Content-Type application/octet-stream it takes the
+// output of Serialize. POST takes {"items": [...]} or, as text/plain, one
+// item per line. Responses are JSON, and errors are {"error": "..."}.
+//
+// RegisterGRPC serves the same filters through the BloomService gRPC API
+// defined in bloompb; the client package calls it.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"sync"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+// Server is an http.Handler holding named filters. The zero value is not
+// usable; call New.
+type Server struct {
+	// Limits bounds uploaded filters as well as the filters PUT may create.
+	// A zero MaxBits means 2^32 bits, 512 MiB per filter; set it to
+	// math.MaxUint64 to lift the limit.
+	Limits bloomfilter.DecodeOptions
+	// MaxBodyBytes bounds request bodies; zero means 64 MiB.
+	MaxBodyBytes int64
+	// MaxFilters bounds how many filters PUT and Create may leave the
+	// server holding; zero means 1024. Replacing a filter does not count
+	// against it, and Set ignores it.
+	MaxFilters int
+
+	mu      sync.RWMutex
+	filters map[string]*bloomfilter.BloomFilter
+	mux     *http.ServeMux
+}
+
+const (
+	defaultMaxBodyBytes = 64 << 20
+	defaultMaxBits      = 1 << 32
+	defaultMaxFilters   = 1024
+)
+
+// ErrTooManyFilters is returned when creating a filter would take the server
+// past MaxFilters.
+var ErrTooManyFilters = errors.New("too many filters")
+
+func New() *Server {
+	s := &Server{filters: make(map[string]*bloomfilter.BloomFilter)}
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("PUT /filters/{name}", s.put)
+	s.mux.HandleFunc("GET /filters/{name}", s.get)
+	s.mux.HandleFunc("DELETE /filters/{name}", s.delete)
+	s.mux.HandleFunc("POST /filters/{name}/items", s.addItems)
+	s.mux.HandleFunc("GET /filters/{name}/contains", s.contains)
+	s.mux.HandleFunc("GET /filters/{name}/stats", s.stats)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	limit := s.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	s.mux.ServeHTTP(w, r)
+}
+
+// Set installs bf under name, replacing any filter already there.
+func (s *Server) Set(name string, bf *bloomfilter.BloomFilter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filters[name] = bf
+}
+
+// Filter returns the filter named name, or nil.
+func (s *Server) Filter(name string) *bloomfilter.BloomFilter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.filters[name]
+}
+
+// Params sizes a new filter, either from Capacity and FPRate, as for
+// NewWithEstimates, or from Bits and Hashes, as for New64.
+type Params struct {
+	Capacity uint    `json:"capacity"`
+	FPRate   float64 `json:"fp_rate"`
+	Bits     uint64  `json:"bits"`
+	Hashes   int     `json:"hashes"`
+}
+
+func (s *Server) put(w http.ResponseWriter, r *http.Request) {
+	var bf *bloomfilter.BloomFilter
+	if mediaType(r) == "application/octet-stream" {
+		var err error
+		bf, _, err = s.limits().Decode(r.Body)
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+	} else {
+		var p Params
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		var err error
+		if bf, err = s.newFilter(p); err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+	}
+	if err := s.create(r.PathValue("name"), bf); err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Create makes a filter named name, replacing any already there. Sizes over
+// Limits.MaxBits, or its default, fail with a bloomfilter.LimitError, and a
+// new name past MaxFilters with ErrTooManyFilters.
+func (s *Server) Create(name string, p Params) error {
+	bf, err := s.newFilter(p)
+	if err != nil {
+		return err
+	}
+	return s.create(name, bf)
+}
+
+// create is Set, refusing a new name once the server holds MaxFilters.
+func (s *Server) create(name string, bf *bloomfilter.BloomFilter) error {
+	limit := s.MaxFilters
+	if limit <= 0 {
+		limit = defaultMaxFilters
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.filters[name]; !ok && len(s.filters) >= limit {
+		return ErrTooManyFilters
+	}
+	s.filters[name] = bf
+	return nil
+}
+
+func (s *Server) newFilter(req Params) (*bloomfilter.BloomFilter, error) {
+	switch {
+	case req.Capacity > 0 && req.Bits == 0:
+		if req.FPRate <= 0 || req.FPRate >= 1 {
+			return nil, errors.New("fp_rate must be in (0, 1)")
+		}
+		// Check the size NewWithEstimates would pick before allocating it.
+		bits, hashes := bloomfilter.EstimateParameters(req.Capacity, req.FPRate)
+		if err := s.checkBits(uint64(bits)); err != nil {
+			return nil, err
+		}
+		return bloomfilter.New(bits, hashes), nil
+	case req.Bits > 0 && req.Capacity == 0:
+		if req.Hashes < 1 || req.Hashes > bloomfilter.MaxHashes {
+			return nil, fmt.Errorf("hashes must be between 1 and %d", bloomfilter.MaxHashes)
+		}
+		if err := s.checkBits(req.Bits); err != nil {
+			return nil, err
+		}
+		return bloomfilter.New64(req.Bits, req.Hashes), nil
+	}
+	return nil, errors.New("give either capacity and fp_rate, or bits and hashes")
+}
+
+// limits is s.Limits with the default MaxBits filled in.
+func (s *Server) limits() bloomfilter.DecodeOptions {
+	limits := s.Limits
+	if limits.MaxBits == 0 {
+		limits.MaxBits = defaultMaxBits
+	}
+	return limits
+}
+
+func (s *Server) checkBits(bits uint64) error {
+	if limit := s.limits().MaxBits; bits > limit {
+		return &bloomfilter.LimitError{Limit: "MaxBits", Value: bits, Max: limit}
+	}
+	return nil
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	bf := s.lookup(w, r)
+	if bf == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	bf.WriteTo(w)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := r.PathValue("name")
+	if _, ok := s.filters[name]; !ok {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	delete(s.filters, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type addRequest struct {
+	Items []string `json:"items"`
+}
+
+func (s *Server) addItems(w http.ResponseWriter, r *http.Request) {
+	bf := s.lookup(w, r)
+	if bf == nil {
+		return
+	}
+
+	added := 0
+	if mediaType(r) == "text/plain" {
+		var err error
+		if added, err = bf.AddLines(r.Body, bloomfilter.LineOptions{}); err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+	} else {
+		var req addRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+		for _, item := range req.Items {
+			bf.AddString(item)
+		}
+		added = len(req.Items)
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"added": added})
+}
+
+func (s *Server) contains(w http.ResponseWriter, r *http.Request) {
+	bf := s.lookup(w, r)
+	if bf == nil {
+		return
+	}
+	keys := r.URL.Query()["key"]
+	if len(keys) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no key given"))
+		return
+	}
+	results := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		results[key] = bf.ContainsString(key)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// Stats is bloomfilter.Stats in JSON.
+type Stats struct {
+	Size              uint64  `json:"size"`
+	NumHashes         int     `json:"num_hashes"`
+	Hasher            string  `json:"hasher"`
+	Count             uint64  `json:"count"`
+	Queries           uint64  `json:"queries"`
+	Positives         uint64  `json:"positives"`
+	Negatives         uint64  `json:"negatives"`
+	BitsSet           uint64  `json:"bits_set"`
+	FillRatio         float64 `json:"fill_ratio"`
+	ApproxCardinality float64 `json:"approx_cardinality"`
+	EstimatedFPRate   float64 `json:"estimated_fp_rate"`
+}
+
+func (s *Server) stats(w http.ResponseWriter, r *http.Request) {
+	bf := s.lookup(w, r)
+	if bf == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, statsOf(bf))
+}
+
+func statsOf(bf *bloomfilter.BloomFilter) Stats {
+	s := bf.Stats()
+	if math.IsInf(s.ApproxCardinality, 0) {
+		// JSON has no infinity; a saturated filter reports its size instead.
+		s.ApproxCardinality = float64(s.Size)
+	}
+	return Stats{
+		Size:              s.Size,
+		NumHashes:         s.NumHashes,
+		Hasher:            s.Hasher,
+		Count:             s.Count,
+		Queries:           s.Queries,
+		Positives:         s.Positives,
+		Negatives:         s.Negatives,
+		BitsSet:           s.BitsSet,
+		FillRatio:         s.FillRatio,
+		ApproxCardinality: s.ApproxCardinality,
+		EstimatedFPRate:   s.EstimatedFPRate,
+	}
+}
+
+var errNotFound = errors.New("no such filter")
+
+// lookup returns the filter the request names, or writes a 404 and returns
+// nil.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *bloomfilter.BloomFilter {
+	bf := s.Filter(r.PathValue("name"))
+	if bf == nil {
+		writeError(w, http.StatusNotFound, errNotFound)
+	}
+	return bf
+}
+
+func mediaType(r *http.Request) string {
+	t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return t
+}
+
+func errorStatus(err error) int {
+	var maxBytes *http.MaxBytesError
+	if errors.Is(err, bloomfilter.ErrTooLarge) || errors.As(err, &maxBytes) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, ErrTooManyFilters) {
+		return http.StatusInsufficientStorage
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
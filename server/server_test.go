@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+func put(s *Server, contentType, body string) int {
+	return putNamed(s, "f", contentType, body)
+}
+
+func putNamed(s *Server, name, contentType, body string) int {
+	req := httptest.NewRequest(http.MethodPut, "/filters/"+name, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestDefaultMaxBits(t *testing.T) {
+	s := New()
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"capacity": 1000, "fp_rate": 0.01}`, http.StatusCreated},
+		{`{"bits": 4294967297, "hashes": 3}`, http.StatusRequestEntityTooLarge},
+		{`{"capacity": 1000000000, "fp_rate": 0.01}`, http.StatusRequestEntityTooLarge},
+		{`{"capacity": 18446744073709551615, "fp_rate": 1e-300}`, http.StatusRequestEntityTooLarge},
+	} {
+		if code := put(s, "application/json", tc.body); code != tc.code {
+			t.Errorf("PUT %s: status %d, want %d", tc.body, code, tc.code)
+		}
+	}
+}
+
+func TestCreateSizesLikeNewWithEstimates(t *testing.T) {
+	s := New()
+	if err := s.Create("f", Params{Capacity: 1000, FPRate: 0.01}); err != nil {
+		t.Fatal(err)
+	}
+	want := bloomfilter.NewWithEstimates(1000, 0.01)
+	if !bytes.Equal(s.Filter("f").Serialize(), want.Serialize()) {
+		t.Error("Create sized the filter differently from NewWithEstimates")
+	}
+
+	s.Limits.MaxBits = 1 << 10
+	if err := s.Create("g", Params{Capacity: 1000, FPRate: 0.01}); !errors.Is(err, bloomfilter.ErrTooLarge) {
+		t.Errorf("Create over MaxBits: got %v, want ErrTooLarge", err)
+	}
+}
+
+func TestUploadLimits(t *testing.T) {
+	s := New()
+	s.Limits.MaxBits = 1 << 10
+	small := bloomfilter.New(1<<10, 3).Serialize()
+	large := bloomfilter.New(1<<11, 3).Serialize()
+	if code := put(s, "application/octet-stream", string(small)); code != http.StatusCreated {
+		t.Errorf("upload within MaxBits: status %d", code)
+	}
+	if code := put(s, "application/octet-stream", string(large)); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("upload over MaxBits: status %d", code)
+	}
+}
+
+// Past MaxFilters, new names are refused through Create and both kinds of
+// PUT, while existing filters can still be replaced.
+func TestMaxFilters(t *testing.T) {
+	s := New()
+	s.MaxFilters = 2
+	params := `{"bits": 1024, "hashes": 3}`
+	upload := string(bloomfilter.New(1024, 3).Serialize())
+	if err := s.Create("a", Params{Bits: 1024, Hashes: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if code := putNamed(s, "b", "application/json", params); code != http.StatusCreated {
+		t.Fatalf("PUT within MaxFilters: status %d", code)
+	}
+
+	if err := s.Create("c", Params{Bits: 1024, Hashes: 3}); !errors.Is(err, ErrTooManyFilters) {
+		t.Errorf("Create past MaxFilters: got %v, want ErrTooManyFilters", err)
+	}
+	for contentType, body := range map[string]string{"application/json": params, "application/octet-stream": upload} {
+		if code := putNamed(s, "c", contentType, body); code != http.StatusInsufficientStorage {
+			t.Errorf("PUT %s past MaxFilters: status %d, want %d", contentType, code, http.StatusInsufficientStorage)
+		}
+		if code := putNamed(s, "b", contentType, body); code != http.StatusCreated {
+			t.Errorf("PUT %s replacing a filter: status %d", contentType, code)
+		}
+	}
+	if s.Filter("c") != nil {
+		t.Error("a refused filter was stored")
+	}
+}
@@ -0,0 +1,116 @@
+package bloomfilter
+
+import (
+	"math/bits"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+// ShardedFilter spreads items across independent BloomFilters chosen by
+// hash, so that writers on many cores rarely touch the same cache lines or
+// count. Every shard has the same shape, hasher and seed, and an item lives
+// in exactly one of them; the combined false-positive rate is that of one
+// shard at its share of the items.
+type ShardedFilter struct {
+	shards []*BloomFilter
+}
+
+// NewSharded returns a filter of size bits in total, split evenly over
+// numShards shards of numHashes hash functions each. WithBackend is not
+// supported.
+func NewSharded(numShards int, size uint, numHashes int, opts ...Option) *ShardedFilter {
+	if numShards < 1 {
+		panic("bloomfilter: sharded filter needs at least one shard")
+	}
+	perShard := (uint64(size) + uint64(numShards) - 1) / uint64(numShards)
+	sf := &ShardedFilter{shards: make([]*BloomFilter, numShards)}
+	sf.shards[0] = New64(perShard, numHashes, opts...)
+	if sf.shards[0].backend != nil {
+		panic("bloomfilter: ShardedFilter does not support a Backend")
+	}
+	// Items pick their shard by hash, so every shard must hash alike even
+	// with WithRandomSeed.
+	for i := 1; i < numShards; i++ {
+		sf.shards[i] = New64(perShard, numHashes, append(opts, hashedLike(sf.shards[0]))...)
+	}
+	return sf
+}
+
+// NewShardedWithEstimates sizes a sharded filter like NewWithEstimates.
+func NewShardedWithEstimates(numShards int, expectedElements uint, fpRate float64, opts ...Option) *ShardedFilter {
+	size, numHashes := EstimateParameters(expectedElements, fpRate)
+	return NewSharded(numShards, size, numHashes, opts...)
+}
+
+// shard returns the shard for an item hashed to h1, h2. It remixes the hash
+// so that the choice of shard is independent of the probes within it.
+func (sf *ShardedFilter) shard(h1, h2 uint64) *BloomFilter {
+	i, _ := bits.Mul64(hashing.Mix64(h1^h2), uint64(len(sf.shards)))
+	return sf.shards[i]
+}
+
+func (sf *ShardedFilter) Add(item []byte) {
+	h1, h2 := sf.shards[0].hash(item)
+	sf.shard(h1, h2).add(h1, h2)
+}
+
+func (sf *ShardedFilter) Contains(item []byte) bool {
+	h1, h2 := sf.shards[0].hash(item)
+	return sf.shard(h1, h2).contains(h1, h2)
+}
+
+func (sf *ShardedFilter) AddString(item string) {
+	sf.Add(stringBytes(item))
+}
+
+func (sf *ShardedFilter) ContainsString(item string) bool {
+	return sf.Contains(stringBytes(item))
+}
+
+func (sf *ShardedFilter) AddUint64(item uint64) {
+	h1, h2 := sf.shards[0].seeded(hashUint64(item))
+	sf.shard(h1, h2).add(h1, h2)
+}
+
+func (sf *ShardedFilter) ContainsUint64(item uint64) bool {
+	h1, h2 := sf.shards[0].seeded(hashUint64(item))
+	return sf.shard(h1, h2).contains(h1, h2)
+}
+
+// TestAndAdd is BloomFilter.TestAndAdd on the item's shard.
+func (sf *ShardedFilter) TestAndAdd(item []byte) bool {
+	h1, h2 := sf.shards[0].hash(item)
+	return sf.shard(h1, h2).TestAndAdd(item)
+}
+
+func (sf *ShardedFilter) Count() uint {
+	var n uint
+	for _, shard := range sf.shards {
+		n += shard.Count()
+	}
+	return n
+}
+
+func (sf *ShardedFilter) Reset() {
+	for _, shard := range sf.shards {
+		shard.Reset()
+	}
+}
+
+// Shards returns the shards themselves, not copies.
+func (sf *ShardedFilter) Shards() []*BloomFilter {
+	return sf.shards
+}
+
+// Merge returns a single BloomFilter of one shard's size holding every
+// item, the union of all shards. It answers Contains without knowing the
+// shard, at the false-positive rate of one shard holding all the items.
+func (sf *ShardedFilter) Merge() (*BloomFilter, error) {
+	merged := sf.shards[0].Clone()
+	for _, shard := range sf.shards[1:] {
+		if err := merged.UnionWith(shard); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
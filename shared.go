@@ -0,0 +1,116 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"os"
+	"sync/atomic"
+	"unsafe"
+)
+
+// OpenShared maps the filter file at path for sharing among processes on
+// one host, creating it with the given shape if it does not exist. Bits are
+// set with atomic writes to the shared mapping, so every process sees every
+// other's adds immediately.
+//
+// Opening happens under an exclusive lock on the file, so processes racing
+// to create it agree on one filter. The shape is fixed by whichever process
+// creates the file: opening it with another size or hash count returns
+// ErrIncompatible.
+//
+// Count is this process's adds plus the file's count as of the last Flush;
+// Flush adds this process's adds to the file's count rather than
+// overwriting it.
+func OpenShared(path string, size uint, numHashes int) (*BloomFilter, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	bf, err := openShared(f, uint64(size), numHashes)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return bf, nil
+}
+
+func openShared(f *os.File, size uint64, numHashes int) (*BloomFilter, error) {
+	if err := lockFile(f); err != nil {
+		return nil, err
+	}
+	defer unlockFile(f)
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	length := mmapHeaderSize + 8*int64(wordsFor(size))
+	if info.Size() == 0 {
+		if err := f.Truncate(length); err != nil {
+			return nil, err
+		}
+	} else if info.Size() != length {
+		return nil, ErrIncompatible
+	}
+
+	bf, err := mapFilter(f, length)
+	if err != nil {
+		return nil, err
+	}
+	// A zero size means the file is new, or its creator died before
+	// writing the header.
+	if binary.LittleEndian.Uint64(bf.mmap.data[0:8]) == 0 {
+		bf.size = size
+		bf.numHashes = numHashes
+		bf.hasher = defaultHasher
+		bf.writeMmapHeader()
+		if err := f.Sync(); err != nil {
+			unmap(bf.mmap.data)
+			return nil, err
+		}
+	} else {
+		header := bf.mmap.data[:mmapHeaderSize]
+		flags := binary.LittleEndian.Uint64(header[24:32])
+		bf.size = binary.LittleEndian.Uint64(header[0:8])
+		bf.numHashes = int(binary.LittleEndian.Uint64(header[16:24]))
+		bf.partitioned = flags&mmapFlagPartitioned != 0
+		bf.hasher = mmapHasher(flags)
+		if bf.size != size || bf.numHashes != numHashes || bf.hasher == nil {
+			unmap(bf.mmap.data)
+			return nil, ErrIncompatible
+		}
+	}
+	bf.mmap.shared = true
+	bf.count.Store(bf.sharedCount(0))
+	return bf, nil
+}
+
+// resetShared zeroes the count in the header along with the bits Reset has
+// just cleared for every process, so that the next Flush publishes only
+// adds made since.
+func (bf *BloomFilter) resetShared() {
+	if err := lockFile(bf.mmap.file); err != nil {
+		bf.setErr(err)
+		return
+	}
+	defer unlockFile(bf.mmap.file)
+	atomic.StoreUint64((*uint64)(unsafe.Pointer(&bf.mmap.data[8])), 0)
+	bf.mmap.flushed = 0
+}
+
+// sharedCount adds delta to the count in the header of a shared file and
+// returns the new total. The header is little-endian but atomics work in
+// native order, hence the compare-and-swap loop.
+func (bf *BloomFilter) sharedCount(delta uint64) uint64 {
+	p := (*uint64)(unsafe.Pointer(&bf.mmap.data[8]))
+	for {
+		old := atomic.LoadUint64(p)
+		var b [8]byte
+		binary.NativeEndian.PutUint64(b[:], old)
+		total := binary.LittleEndian.Uint64(b[:]) + delta
+		binary.LittleEndian.PutUint64(b[:], total)
+		if atomic.CompareAndSwapUint64(p, old, binary.NativeEndian.Uint64(b[:])) {
+			bf.mmap.flushed = total
+			return total
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package bloomfilter
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync/atomic"
+)
+
+// snapshotPageWords is the unit copy-on-write works in: writers copy a 4 KiB
+// page of the bit array the first time they change it under a snapshot.
+const snapshotPageWords = 512
+
+// Snapshot is a point-in-time view of a BloomFilter that costs almost
+// nothing to take. Rather than copying the bit array up front, the filter
+// copies each page just before the first write to it after the snapshot, so
+// writers keep going while the snapshot is serialized and memory grows only
+// with the pages actually written. Call Close when done to stop the copying.
+//
+// The snapshot holds every item added before Snapshot was called. An Add
+// running at the same time may or may not be included.
+type Snapshot struct {
+	bf     *BloomFilter
+	pages  *snapshotPages
+	h      header
+	hasher Hasher
+	seed   uint64
+}
+
+type snapshotPages struct {
+	live  []uint64
+	saved []atomic.Pointer[[snapshotPageWords]uint64]
+}
+
+// Snapshot returns a copy-on-write view of the filter. A filter with a
+// Backend has no pages to protect, so its bits are copied into memory
+// instead; that returns nil if they cannot be read, see Err.
+func (bf *BloomFilter) Snapshot() *Snapshot {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	if bf.backend != nil {
+		words, err := bf.words()
+		if err != nil {
+			bf.setErr(err)
+			return nil
+		}
+		return &Snapshot{pages: &snapshotPages{live: words}, h: bf.header(), hasher: bf.hasher, seed: bf.seed}
+	}
+
+	pages := &snapshotPages{
+		live:  bf.bitset,
+		saved: make([]atomic.Pointer[[snapshotPageWords]uint64], (len(bf.bitset)+snapshotPageWords-1)/snapshotPageWords),
+	}
+	bf.snapshotMu.Lock()
+	var active []*snapshotPages
+	if p := bf.snapshots.Load(); p != nil {
+		active = *p
+	}
+	active = append(slices.Clip(active), pages)
+	bf.snapshots.Store(&active)
+	bf.snapshotMu.Unlock()
+
+	// Read the header only once writers preserve pages, so that the count
+	// does not include items the bits might lack.
+	return &Snapshot{bf: bf, pages: pages, h: bf.header(), hasher: bf.hasher, seed: bf.seed}
+}
+
+// Close releases the snapshot. It must not be used afterwards.
+func (s *Snapshot) Close() {
+	bf := s.bf
+	if bf == nil {
+		return
+	}
+	s.bf = nil
+
+	bf.snapshotMu.Lock()
+	defer bf.snapshotMu.Unlock()
+	active := slices.DeleteFunc(slices.Clone(*bf.snapshots.Load()), func(p *snapshotPages) bool {
+		return p == s.pages
+	})
+	if len(active) == 0 {
+		bf.snapshots.Store(nil)
+	} else {
+		bf.snapshots.Store(&active)
+	}
+}
+
+// WriteTo writes the snapshot in the Serialize format.
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	return writeStream(context.Background(), w, s.h, len(s.pages.live), s.pages.word)
+}
+
+// Freeze copies the snapshot into a FrozenFilter.
+func (s *Snapshot) Freeze() *FrozenFilter {
+	bf := New64(s.h.size, int(s.h.numHashes), WithHasher(s.hasher), WithSeed(s.seed))
+	bf.partitioned = s.h.flags&formatFlagPartitioned != 0
+	bf.count.Store(s.h.count)
+	for i := range bf.bitset {
+		bf.bitset[i] = s.pages.word(i)
+	}
+	return &FrozenFilter{bf: bf}
+}
+
+// preserve is called before any write to word i of the bit array.
+func (bf *BloomFilter) preserve(i int) {
+	if p := bf.snapshots.Load(); p != nil {
+		for _, pages := range *p {
+			pages.preserve(i / snapshotPageWords)
+		}
+	}
+}
+
+func (sp *snapshotPages) preserve(page int) {
+	if page >= len(sp.saved) || sp.saved[page].Load() != nil {
+		return
+	}
+	saved := new([snapshotPageWords]uint64)
+	start := page * snapshotPageWords
+	for j := range min(snapshotPageWords, len(sp.live)-start) {
+		saved[j] = atomicLoad(sp.live, start+j)
+	}
+	sp.saved[page].CompareAndSwap(nil, saved)
+}
+
+// word returns word i as of the snapshot. A writer saves the page before
+// changing it, so if the page was saved by the time the live word has been
+// read, the saved copy is the one to trust.
+func (sp *snapshotPages) word(i int) uint64 {
+	if sp.saved == nil {
+		return sp.live[i]
+	}
+	saved := &sp.saved[i/snapshotPageWords]
+	if p := saved.Load(); p != nil {
+		return p[i%snapshotPageWords]
+	}
+	w := atomicLoad(sp.live, i)
+	if p := saved.Load(); p != nil {
+		return p[i%snapshotPageWords]
+	}
+	return w
+}
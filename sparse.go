@@ -0,0 +1,119 @@
+package bloomfilter
+
+import (
+	"slices"
+	"sync"
+)
+
+// A container covers 2^16 bits. It holds its set bits as a sorted array of
+// offsets until that would use more memory than a plain bitmap.
+const (
+	sparseContainerBits = 1 << 16
+	sparseArrayMax      = sparseContainerBits / 16
+)
+
+// SparseBackend keeps the bit array as roaring-style containers, so a large
+// filter that holds few items costs memory in proportion to the bits set
+// rather than to its size. Each touched 64Ki-bit range takes two bytes per
+// set bit, up to 8KiB once it is dense. Probes are slower than with the
+// packed bit array, and lose their advantage once the filter fills up.
+type SparseBackend struct {
+	mu         sync.RWMutex
+	containers map[uint64]*sparseContainer
+}
+
+type sparseContainer struct {
+	array  []uint16
+	bitmap []uint64
+}
+
+func NewSparseBackend() *SparseBackend {
+	return &SparseBackend{containers: make(map[uint64]*sparseContainer)}
+}
+
+// NewSparse returns a filter backed by a new SparseBackend.
+func NewSparse(size uint, numHashes int) *BloomFilter {
+	return NewWithBackend(size, numHashes, NewSparseBackend())
+}
+
+func (sb *SparseBackend) SetBits(positions []uint64) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	for _, pos := range positions {
+		c := sb.containers[pos/sparseContainerBits]
+		if c == nil {
+			c = &sparseContainer{}
+			sb.containers[pos/sparseContainerBits] = c
+		}
+		c.set(uint16(pos))
+	}
+	return nil
+}
+
+func (sb *SparseBackend) TestBits(positions []uint64) (bool, error) {
+	sb.mu.RLock()
+	defer sb.mu.RUnlock()
+	for _, pos := range positions {
+		c := sb.containers[pos/sparseContainerBits]
+		if c == nil || !c.test(uint16(pos)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (sb *SparseBackend) Words(size uint64) ([]uint64, error) {
+	sb.mu.RLock()
+	defer sb.mu.RUnlock()
+	words := make([]uint64, (size+63)/64)
+	for key, c := range sb.containers {
+		base := key * sparseContainerBits / 64
+		if c.bitmap != nil {
+			copy(words[min(base, uint64(len(words))):], c.bitmap)
+			continue
+		}
+		for _, low := range c.array {
+			if i := base + uint64(low)/64; i < uint64(len(words)) {
+				words[i] |= 1 << (low % 64)
+			}
+		}
+	}
+	return words, nil
+}
+
+func (sb *SparseBackend) Clear() error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	clear(sb.containers)
+	return nil
+}
+
+func (c *sparseContainer) set(low uint16) {
+	if c.bitmap != nil {
+		c.bitmap[low/64] |= 1 << (low % 64)
+		return
+	}
+	i, found := slices.BinarySearch(c.array, low)
+	if found {
+		return
+	}
+	if len(c.array) < sparseArrayMax {
+		c.array = slices.Insert(c.array, i, low)
+		return
+	}
+
+	c.bitmap = make([]uint64, sparseContainerBits/64)
+	for _, v := range c.array {
+		c.bitmap[v/64] |= 1 << (v % 64)
+	}
+	c.bitmap[low/64] |= 1 << (low % 64)
+	c.array = nil
+}
+
+func (c *sparseContainer) test(low uint16) bool {
+	if c.bitmap != nil {
+		return c.bitmap[low/64]&(1<<(low%64)) != 0
+	}
+	_, found := slices.BinarySearch(c.array, low)
+	return found
+}
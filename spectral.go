@@ -0,0 +1,120 @@
+package bloomfilter
+
+import (
+	"math"
+	"sync"
+)
+
+// SpectralBloomFilter is the spectral Bloom filter of Cohen and Matias: a
+// counting filter with 32-bit counters whose Estimate, the smallest of an
+// item's counters, approximates how many times it was added. Estimates
+// never fall below the true count, and exceed it only where every one of an
+// item's counters is shared with other items.
+//
+// With minimal increase, Add raises only the item's smallest counters. That
+// tightens estimates considerably for skewed streams, at the cost of Remove.
+type SpectralBloomFilter struct {
+	mu              sync.RWMutex
+	counters        []uint32
+	size            uint
+	numHashes       int
+	minimalIncrease bool
+	count           uint64
+}
+
+func NewSpectral(size uint, numHashes int, minimalIncrease bool) *SpectralBloomFilter {
+	return &SpectralBloomFilter{
+		counters:        make([]uint32, size),
+		size:            size,
+		numHashes:       numHashes,
+		minimalIncrease: minimalIncrease,
+	}
+}
+
+func (sf *SpectralBloomFilter) Add(item []byte) {
+	sf.AddN(item, 1)
+}
+
+// AddN adds n occurrences of item at once. Counters saturate at the
+// largest uint32.
+func (sf *SpectralBloomFilter) AddN(item []byte, n uint32) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	h1, h2 := hash128(item)
+	target := uint32(math.MaxUint32)
+	if sf.minimalIncrease {
+		target = sf.estimate(h1, h2)
+		target += min(n, math.MaxUint32-target)
+	}
+	for i := 0; i < sf.numHashes; i++ {
+		index := location(h1, h2, i, uint64(sf.size))
+		c := sf.counters[index]
+		if sf.minimalIncrease {
+			sf.counters[index] = max(c, target)
+		} else {
+			sf.counters[index] = c + min(n, math.MaxUint32-c)
+		}
+	}
+	sf.count += uint64(n)
+}
+
+func (sf *SpectralBloomFilter) estimate(h1, h2 uint64) uint32 {
+	est := uint32(math.MaxUint32)
+	for i := 0; i < sf.numHashes; i++ {
+		est = min(est, sf.counters[location(h1, h2, i, uint64(sf.size))])
+	}
+	return est
+}
+
+// Estimate returns the approximate number of times item was added.
+func (sf *SpectralBloomFilter) Estimate(item []byte) uint32 {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	h1, h2 := hash128(item)
+	return sf.estimate(h1, h2)
+}
+
+func (sf *SpectralBloomFilter) Contains(item []byte) bool {
+	return sf.Estimate(item) > 0
+}
+
+// Remove deletes one occurrence of item, reporting false and leaving the
+// filter untouched when item is definitely not present. It panics with
+// minimal increase, whose counters do not record every add.
+func (sf *SpectralBloomFilter) Remove(item []byte) bool {
+	if sf.minimalIncrease {
+		panic("bloomfilter: Remove is not supported with minimal increase")
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	h1, h2 := hash128(item)
+	if sf.estimate(h1, h2) == 0 {
+		return false
+	}
+	for i := 0; i < sf.numHashes; i++ {
+		index := location(h1, h2, i, uint64(sf.size))
+		if sf.counters[index] < math.MaxUint32 {
+			sf.counters[index]--
+		}
+	}
+	if sf.count > 0 {
+		sf.count--
+	}
+	return true
+}
+
+// Count is the total number of occurrences added.
+func (sf *SpectralBloomFilter) Count() uint64 {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.count
+}
+
+func (sf *SpectralBloomFilter) Reset() {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	clear(sf.counters)
+	sf.count = 0
+}
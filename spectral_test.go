@@ -0,0 +1,110 @@
+package bloomfilter
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// skewedCounts feeds sf a stream in which low keys are far more common and
+// returns the true count of each key.
+func skewedCounts(sf *SpectralBloomFilter) map[string]uint32 {
+	rng := rand.New(rand.NewPCG(1, 2))
+	counts := make(map[string]uint32)
+	for range 20000 {
+		key := strconv.Itoa(int(rng.ExpFloat64() * 200))
+		sf.Add([]byte(key))
+		counts[key]++
+	}
+	return counts
+}
+
+// Estimates never fall below the true count, and minimal increase keeps
+// them closer to it.
+func TestSpectralEstimate(t *testing.T) {
+	var overshoot [2]uint64
+	for i, minimal := range []bool{false, true} {
+		sf := NewSpectral(1<<10, 4, minimal)
+		counts := skewedCounts(sf)
+		for key, n := range counts {
+			est := sf.Estimate([]byte(key))
+			if est < n {
+				t.Fatalf("minimal increase %t: %s estimated at %d, added %d times", minimal, key, est, n)
+			}
+			overshoot[i] += uint64(est - n)
+		}
+		if sf.Count() != 20000 {
+			t.Errorf("count %d, want 20000", sf.Count())
+		}
+	}
+	if overshoot[1] >= overshoot[0] {
+		t.Errorf("minimal increase overshoots by %d in all, plain by %d", overshoot[1], overshoot[0])
+	}
+}
+
+// Remove takes back one occurrence, so removing everything added leaves
+// every counter at zero.
+func TestSpectralRemove(t *testing.T) {
+	// Removing a false positive would take from other items' counters, so
+	// this filter is roomy enough to have none here.
+	sf := NewSpectral(1<<16, 4, false)
+	counts := skewedCounts(sf)
+	if sf.Remove([]byte("never added")) {
+		t.Error("Remove of an absent item reported true")
+	}
+
+	sf.AddN([]byte("x"), 3)
+	sf.Remove([]byte("x"))
+	if est := sf.Estimate([]byte("x")); est < 2 {
+		t.Errorf("estimate %d after 3 adds and a Remove", est)
+	}
+	sf.Remove([]byte("x"))
+	sf.Remove([]byte("x"))
+	for key, n := range counts {
+		for range n {
+			if !sf.Remove([]byte(key)) {
+				t.Fatalf("Remove of %s, still present, reported false", key)
+			}
+		}
+	}
+	if slices.ContainsFunc(sf.counters, func(c uint32) bool { return c != 0 }) || sf.Count() != 0 {
+		t.Errorf("counters left after removing everything; count %d", sf.Count())
+	}
+	if sf.Contains([]byte("0")) {
+		t.Error("a removed item still matches")
+	}
+}
+
+// Saturated counters stay put, since they no longer know how many adds
+// they hold.
+func TestSpectralSaturate(t *testing.T) {
+	for _, minimal := range []bool{false, true} {
+		sf := NewSpectral(64, 3, minimal)
+		sf.AddN([]byte("a"), math.MaxUint32-1)
+		sf.AddN([]byte("a"), 10)
+		if est := sf.Estimate([]byte("a")); est != math.MaxUint32 {
+			t.Errorf("minimal increase %t: estimate %d, want saturated", minimal, est)
+		}
+		if !minimal {
+			sf.Remove([]byte("a"))
+			if est := sf.Estimate([]byte("a")); est != math.MaxUint32 {
+				t.Errorf("Remove lowered a saturated counter to %d", est)
+			}
+		}
+		sf.Reset()
+		if sf.Contains([]byte("a")) || sf.Count() != 0 {
+			t.Error("Reset left the item")
+		}
+	}
+}
+
+func TestSpectralRemoveMinimalIncrease(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Remove with minimal increase did not panic")
+		}
+	}()
+	NewSpectral(64, 3, true).Remove([]byte("a"))
+}
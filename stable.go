@@ -0,0 +1,96 @@
+package bloomfilter
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+)
+
+// StableBloomFilter is meant for unbounded streams. Each Add first
+// decrements a run of randomly chosen cells, then sets the item's cells to the
+// maximum cell value, so old items are gradually evicted and the fraction of
+// zero cells converges to a fixed point instead of filling up. The price is
+// that false negatives become possible for items that have not been seen
+// recently.
+type StableBloomFilter struct {
+	mu         sync.Mutex
+	cells      []uint8
+	size       uint
+	numHashes  int
+	max        uint8
+	decrements uint
+}
+
+// NewStable returns a stable filter of size cells, each cellBits wide (1 to
+// 8), that decrements the given number of cells on every insert.
+func NewStable(size uint, numHashes int, cellBits uint8, decrements uint) *StableBloomFilter {
+	if cellBits < 1 || cellBits > 8 {
+		panic("bloomfilter: cell bits must be between 1 and 8")
+	}
+	return &StableBloomFilter{
+		cells:      make([]uint8, size),
+		size:       size,
+		numHashes:  numHashes,
+		max:        uint8(1<<cellBits - 1),
+		decrements: decrements,
+	}
+}
+
+func (sf *StableBloomFilter) Add(item []byte) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	sf.decrement()
+
+	h1, h2 := hash128(item)
+	for i := 0; i < sf.numHashes; i++ {
+		sf.cells[location(h1, h2, i, uint64(sf.size))] = sf.max
+	}
+}
+
+// decrement lowers a run of consecutive cells starting at a random offset,
+// which has the same effect on the stable point as picking each at random.
+func (sf *StableBloomFilter) decrement() {
+	start := rand.UintN(sf.size)
+	for i := uint(0); i < sf.decrements; i++ {
+		index := (start + i) % sf.size
+		if sf.cells[index] > 0 {
+			sf.cells[index]--
+		}
+	}
+}
+
+func (sf *StableBloomFilter) Contains(item []byte) bool {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	h1, h2 := hash128(item)
+	for i := 0; i < sf.numHashes; i++ {
+		if sf.cells[location(h1, h2, i, uint64(sf.size))] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (sf *StableBloomFilter) Reset() {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	clear(sf.cells)
+}
+
+// StablePoint returns the expected fraction of zero cells once the filter
+// has converged.
+func (sf *StableBloomFilter) StablePoint() float64 {
+	k := float64(sf.numHashes)
+	m := float64(sf.size)
+	p := float64(sf.decrements)
+
+	return math.Pow(1/(1+1/(p*(1/k-1/m))), float64(sf.max))
+}
+
+// EstimatedFalsePositiveRate returns the false-positive rate at the stable
+// point.
+func (sf *StableBloomFilter) EstimatedFalsePositiveRate() float64 {
+	return math.Pow(1-sf.StablePoint(), float64(sf.numHashes))
+}
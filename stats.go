@@ -0,0 +1,57 @@
+package bloomfilter
+
+// Stats is a snapshot of a filter's shape, contents and use.
+type Stats struct {
+	Size        uint64
+	NumHashes   int
+	Hasher      string
+	Partitioned bool
+
+	// Count is the number of Add calls, as from Count.
+	Count uint64
+	// Queries is the number of Contains calls of every kind since the
+	// filter was built or ResetStats was called. Positives of them reported
+	// the item as present and Negatives did not; each negative is a lookup
+	// the filter saved.
+	Queries   uint64
+	Positives uint64
+	Negatives uint64
+
+	BitsSet           uint64
+	FillRatio         float64
+	ApproxCardinality float64
+	EstimatedFPRate   float64
+}
+
+// Stats returns a snapshot of the filter's statistics. It scans the bit
+// array to count the bits set; if the bits cannot be read those fields are
+// zero, see Err.
+func (bf *BloomFilter) Stats() Stats {
+	// The counters are read separately, and ResetStats may run between
+	// them or between a query's two increments.
+	positives := bf.positives.Load()
+	queries := max(bf.queries.Load(), positives)
+	s := Stats{
+		Size:            bf.size,
+		NumHashes:       bf.numHashes,
+		Hasher:          bf.hasher.Name(),
+		Partitioned:     bf.partitioned,
+		Count:           bf.count.Load(),
+		Queries:         queries,
+		Positives:       positives,
+		Negatives:       queries - positives,
+		BitsSet:         bf.bitsSet(),
+		EstimatedFPRate: bf.EstimatedFalsePositiveRate(),
+	}
+	if s.Size > 0 {
+		s.FillRatio = float64(s.BitsSet) / float64(s.Size)
+	}
+	s.ApproxCardinality = estimateCardinality(s.BitsSet, s.Size, s.NumHashes)
+	return s
+}
+
+// ResetStats zeroes the query counters.
+func (bf *BloomFilter) ResetStats() {
+	bf.queries.Store(0)
+	bf.positives.Store(0)
+}
@@ -0,0 +1,266 @@
+package bloomfilter
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// streamChunkSize bounds the buffer WriteTo and ReadFrom use, so streaming a
+// large filter costs a fixed amount of memory on top of the filter itself.
+const streamChunkSize = 64 << 10
+
+// WriteTo implements io.WriterTo, writing the Serialize format in chunks
+// instead of building it in memory first.
+func (bf *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	return bf.WriteToContext(context.Background(), w)
+}
+
+// WriteToContext is WriteTo, giving up with ctx's error between chunks once
+// ctx is done. The output is then a truncated filter that will not load.
+func (bf *BloomFilter) WriteToContext(ctx context.Context, w io.Writer) (int64, error) {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	words, err := bf.words()
+	if err != nil {
+		return 0, err
+	}
+	return writeStream(ctx, w, bf.header(), len(words), func(i int) uint64 {
+		return atomicLoad(words, i)
+	})
+}
+
+// writeStream writes a filter with header h and n words, the i-th of which
+// is word(i). It checks ctx before writing each chunk.
+func writeStream(ctx context.Context, w io.Writer, h header, n int, word func(i int) uint64) (int64, error) {
+	buf := h.appendTo(make([]byte, 0, streamChunkSize))
+	var crc uint32
+	var written int64
+	flush := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		crc = crc32.Update(crc, castagnoli, buf)
+		n, err := w.Write(buf)
+		written += int64(n)
+		buf = buf[:0]
+		return err
+	}
+
+	for i := range n {
+		if len(buf)+8 > streamChunkSize {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+		buf = binary.LittleEndian.AppendUint64(buf, word(i))
+	}
+	if err := flush(); err != nil {
+		return written, err
+	}
+
+	n, err := w.Write(binary.LittleEndian.AppendUint32(buf, crc))
+	return written + int64(n), err
+}
+
+// ReadFrom implements io.ReaderFrom, reading either serialization format
+// straight into a new bit array. Like UnmarshalBinary it replaces the whole
+// filter. It reads exactly one filter, so several can be concatenated; at
+// the end of the stream it returns io.EOF.
+func (bf *BloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	decoded, n, err := readFilter(r, DecodeOptions{Hasher: bf.hasher})
+	if err != nil {
+		return n, err
+	}
+	bf.replace(decoded)
+	return n, nil
+}
+
+func readFilter(r io.Reader, o DecodeOptions) (*BloomFilter, int64, error) {
+	cr := &checksumReader{r: r}
+	prefix := make([]byte, 4, 64)
+	if _, err := io.ReadFull(cr, prefix); err != nil {
+		return nil, cr.n, err
+	}
+
+	var bf *BloomFilter
+	var err error
+	if hasMagic(prefix) {
+		bf, err = readV2(cr, prefix, o)
+	} else {
+		bf, err = readV1(cr, prefix, o)
+	}
+	return bf, cr.n, err
+}
+
+func readV2(cr *checksumReader, hdr []byte, o DecodeOptions) (*BloomFilter, error) {
+	// Grow hdr one section at a time until parseHeader can take it whole.
+	readMore := func(n int) error {
+		start := len(hdr)
+		hdr = append(hdr, make([]byte, n)...)
+		_, err := io.ReadFull(cr, hdr[start:])
+		return noEOF(err)
+	}
+	if err := readMore(3); err != nil {
+		return nil, err
+	}
+	if err := readMore(int(hdr[6]) + 4 + 8 + 8 + 1); err != nil {
+		return nil, err
+	}
+	if err := readMore(8 * int(hdr[len(hdr)-1])); err != nil {
+		return nil, err
+	}
+	h, _, err := parseHeader(hdr)
+	if err != nil {
+		return nil, err
+	}
+	bf, err := h.newFilter(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if c := Compression(h.flags >> formatCompressionShift); c == NoCompression {
+		if err := o.checkBytes(uint64(len(hdr)) + 8*uint64(wordsFor(h.size)) + 4); err != nil {
+			return nil, err
+		}
+		if bf.bitset, err = readWords(cr, bf.bitset, wordsFor(h.size)); err != nil {
+			return nil, err
+		}
+	} else {
+		var length [8]byte
+		if _, err := io.ReadFull(cr, length[:]); err != nil {
+			return nil, noEOF(err)
+		}
+		n := binary.LittleEndian.Uint64(length[:])
+		if err := o.checkBytes(uint64(len(hdr)) + 8 + n + 4); err != nil {
+			return nil, err
+		}
+		lr := io.LimitReader(cr, int64(n))
+		r, err := decompressor(lr, c)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		if bf.bitset, err = readWords(r, bf.bitset, wordsFor(h.size)); err != nil {
+			return nil, err
+		}
+		// Skip whatever the decompressor left unread so the checksum lines up.
+		if _, err := io.Copy(io.Discard, lr); err != nil {
+			return nil, err
+		}
+	}
+	if len(bf.bitset) > 0 {
+		bf.bitset[len(bf.bitset)-1] &= lastWordMask(bf.size)
+	}
+
+	sum := cr.crc
+	var trailer [4]byte
+	if _, err := io.ReadFull(cr, trailer[:]); err != nil {
+		return nil, noEOF(err)
+	}
+	if binary.LittleEndian.Uint32(trailer[:]) != sum {
+		return nil, ErrChecksum
+	}
+	return bf, nil
+}
+
+// readWords appends little-endian words read from r to dst until it holds
+// n. It grows dst only as the words arrive, so a header that declares a huge
+// filter ahead of a short stream costs what the stream holds, not what the
+// header claims.
+func readWords(r io.Reader, dst []uint64, n int) ([]uint64, error) {
+	buf := make([]byte, 8*min(n, streamChunkSize/8))
+	for i := len(dst); i < n; {
+		m := min(n-i, len(buf)/8)
+		if _, err := io.ReadFull(r, buf[:8*m]); err != nil {
+			return nil, noEOF(err)
+		}
+		dst = growWords(dst, i+m, n)
+		for j := 0; j < m; j++ {
+			dst[i+j] = binary.LittleEndian.Uint64(buf[8*j:])
+		}
+		i += m
+	}
+	return dst, nil
+}
+
+// growWords extends words to length n, doubling its capacity as needed up
+// to limit, the length it will end at. New words are zero.
+func growWords(words []uint64, n, limit int) []uint64 {
+	if n <= cap(words) {
+		return words[:n]
+	}
+	grown := make([]uint64, n, min(limit, max(n, 2*cap(words))))
+	copy(grown, words)
+	return grown
+}
+
+// readV1 reads the version 1 layout, whose bit array Serialize always wrote
+// as size/8+1 bytes.
+func readV1(cr *checksumReader, prefix []byte, o DecodeOptions) (*BloomFilter, error) {
+	hdr := append(prefix, make([]byte, 12)...)
+	if _, err := io.ReadFull(cr, hdr[4:]); err != nil {
+		return nil, noEOF(err)
+	}
+	size := binary.LittleEndian.Uint64(hdr[0:8])
+	if err := o.checkBits(size); err != nil {
+		return nil, err
+	}
+	if err := o.checkBytes(16 + size/8 + 1); err != nil {
+		return nil, err
+	}
+
+	// As in readWords, the bit array grows as the bytes arrive unless
+	// MaxBits vetted the size.
+	words := wordsFor(size)
+	var bitset []uint64
+	if o.MaxBits > 0 {
+		bitset = make([]uint64, 0, words)
+	}
+	total := size/8 + 1
+	buf := make([]byte, min(total, streamChunkSize))
+	for off := uint64(0); off < total; {
+		n := min(total-off, streamChunkSize)
+		if _, err := io.ReadFull(cr, buf[:n]); err != nil {
+			return nil, noEOF(err)
+		}
+		bitset = growWords(bitset, min(words, int((off+n+7)/8)), words)
+		for i := uint64(0); i < n; i++ {
+			if w := int((off + i) / 8); w < len(bitset) {
+				bitset[w] |= uint64(buf[i]) << (8 * ((off + i) % 8))
+			}
+		}
+		off += n
+	}
+	if len(bitset) > 0 {
+		bitset[len(bitset)-1] &= lastWordMask(size)
+	}
+
+	bf := New64(size, 1, WithHasher(legacyHasher), withBitset(bitset))
+	bf.count.Store(binary.LittleEndian.Uint64(hdr[8:16]))
+	return bf, nil
+}
+
+// checksumReader counts the bytes it reads and keeps a running CRC-32C of
+// them.
+type checksumReader struct {
+	r   io.Reader
+	n   int64
+	crc uint32
+}
+
+func (cr *checksumReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	cr.crc = crc32.Update(cr.crc, castagnoli, p[:n])
+	return n, err
+}
+
+// noEOF turns a clean EOF partway through a filter into ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package bloomfilter
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TimeRotatingFilter is a RotatingFilter whose generations roll on the
+// clock instead of when full: with an hour interval and 24 generations it
+// answers "seen in roughly the last day". Generations start on multiples of
+// the interval since the Unix epoch, so hourly ones roll on the hour in
+// every process. Rotation happens lazily on the next call, so an idle filter
+// costs nothing.
+type TimeRotatingFilter struct {
+	rf       RotatingFilter
+	interval int64
+	epoch    atomic.Int64
+	now      func() time.Time
+}
+
+// NewTimeRotating returns a filter keeping the given number of generations
+// of interval each, sized for capacity items per generation at fpRate.
+// Adding more items than that to one generation raises its false-positive
+// rate rather than rotating early. WithBackend is not supported.
+func NewTimeRotating(interval time.Duration, generations int, capacity uint, fpRate float64, opts ...Option) *TimeRotatingFilter {
+	if interval <= 0 {
+		panic("bloomfilter: rotation interval must be positive")
+	}
+	tf := &TimeRotatingFilter{interval: int64(interval), now: time.Now}
+	tf.rf.init(capacity, fpRate, generations, opts)
+	tf.epoch.Store(tf.currentEpoch())
+	return tf
+}
+
+func (tf *TimeRotatingFilter) currentEpoch() int64 {
+	return tf.now().UnixNano() / tf.interval
+}
+
+// advance rotates once per interval that has begun since the last call,
+// clearing everything if the filter sat idle for all its generations.
+func (tf *TimeRotatingFilter) advance() {
+	epoch := tf.currentEpoch()
+	if epoch <= tf.epoch.Load() {
+		return
+	}
+	tf.rf.mu.Lock()
+	defer tf.rf.mu.Unlock()
+	last := tf.epoch.Load()
+	for i := last; i < epoch && i-last < int64(len(tf.rf.gens)); i++ {
+		tf.rf.rotate()
+	}
+	if epoch > last {
+		tf.epoch.Store(epoch)
+	}
+}
+
+func (tf *TimeRotatingFilter) Add(item []byte) {
+	tf.advance()
+	tf.rf.Add(item)
+}
+
+func (tf *TimeRotatingFilter) Contains(item []byte) bool {
+	tf.advance()
+	return tf.rf.Contains(item)
+}
+
+func (tf *TimeRotatingFilter) AddString(item string) {
+	tf.Add(stringBytes(item))
+}
+
+func (tf *TimeRotatingFilter) ContainsString(item string) bool {
+	return tf.Contains(stringBytes(item))
+}
+
+func (tf *TimeRotatingFilter) AddUint64(item uint64) {
+	tf.advance()
+	tf.rf.AddUint64(item)
+}
+
+func (tf *TimeRotatingFilter) ContainsUint64(item uint64) bool {
+	tf.advance()
+	return tf.rf.ContainsUint64(item)
+}
+
+// Count is the number of items added across the retained generations.
+func (tf *TimeRotatingFilter) Count() uint {
+	tf.advance()
+	return tf.rf.Count()
+}
+
+func (tf *TimeRotatingFilter) EstimatedFalsePositiveRate() float64 {
+	tf.advance()
+	return tf.rf.EstimatedFalsePositiveRate()
+}
+
+// Generations returns the generations themselves, newest first; the i-th
+// covers the interval that began i intervals ago.
+func (tf *TimeRotatingFilter) Generations() []*BloomFilter {
+	tf.advance()
+	return tf.rf.Generations()
+}
+
+func (tf *TimeRotatingFilter) Reset() {
+	tf.rf.Reset()
+}
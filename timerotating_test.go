@@ -0,0 +1,105 @@
+package bloomfilter
+
+import (
+	"testing"
+	"time"
+)
+
+func newFakeTimeRotating(interval time.Duration, generations int) (*TimeRotatingFilter, *fakeClock) {
+	// Start on an interval boundary, as a generation does.
+	clock := &fakeClock{t: time.Unix(1e9, 0).Truncate(interval)}
+	tf := NewTimeRotating(interval, generations, 1000, 0.01)
+	tf.now = clock.now
+	tf.epoch.Store(tf.currentEpoch())
+	return tf, clock
+}
+
+// Generations roll on interval boundaries, however far into one an item
+// was added.
+func TestTimeRotatingInterval(t *testing.T) {
+	tf, clock := newFakeTimeRotating(time.Hour, 3)
+	tf.AddString("a")
+	clock.advance(time.Hour - time.Nanosecond)
+	tf.AddString("b")
+	if gen := tf.Generations()[0]; !gen.ContainsString("a") || !gen.ContainsString("b") {
+		t.Fatal("items within one interval landed in different generations")
+	}
+
+	clock.advance(time.Nanosecond)
+	tf.AddString("c")
+	gens := tf.Generations()
+	if gens[0].ContainsString("a") || !gens[0].ContainsString("c") || !gens[1].ContainsString("a") {
+		t.Error("the boundary did not start a new generation")
+	}
+
+	clock.advance(time.Hour)
+	if !tf.ContainsString("a") || !tf.ContainsString("c") {
+		t.Error("items dropped before their generations expired")
+	}
+	clock.advance(time.Hour)
+	if tf.ContainsString("a") || tf.ContainsString("b") || !tf.ContainsString("c") {
+		t.Error("want only the second interval's item after three intervals")
+	}
+	if tf.Count() != 1 {
+		t.Errorf("count %d, want 1", tf.Count())
+	}
+}
+
+// A filter left idle catches up on every boundary it missed at its next
+// call, and one idle for all its generations starts empty.
+func TestTimeRotatingIdle(t *testing.T) {
+	tf, clock := newFakeTimeRotating(time.Minute, 4)
+	tf.AddString("old")
+	clock.advance(time.Minute)
+	tf.AddString("newer")
+
+	// Two boundaries at once: "old" is now three generations back.
+	clock.advance(2 * time.Minute)
+	if !tf.ContainsString("old") || !tf.ContainsString("newer") {
+		t.Fatal("catching up on two intervals dropped a live generation")
+	}
+	if gens := tf.Generations(); !gens[3].ContainsString("old") || !gens[2].ContainsString("newer") {
+		t.Error("items not where two rotations put them")
+	}
+	clock.advance(time.Minute)
+	if tf.ContainsString("old") || !tf.ContainsString("newer") {
+		t.Error("want only the newer item after one more interval")
+	}
+
+	// Idle for far more intervals than there are generations.
+	clock.advance(1000 * time.Hour)
+	if tf.ContainsString("newer") || tf.Count() != 0 {
+		t.Error("items survived a long idle period")
+	}
+	tf.AddString("fresh")
+	clock.advance(3 * time.Minute)
+	if !tf.ContainsString("fresh") {
+		t.Error("an item added after the idle period expired early")
+	}
+}
+
+// A clock stepping back rotates nothing, and no interval is counted twice
+// once it comes forward again.
+func TestTimeRotatingClockBack(t *testing.T) {
+	tf, clock := newFakeTimeRotating(time.Minute, 2)
+	tf.AddString("a")
+	clock.advance(-time.Hour)
+	tf.AddString("b")
+	clock.advance(time.Hour + time.Minute)
+	if !tf.ContainsString("a") || !tf.ContainsString("b") {
+		t.Error("items dropped after the clock stepped back")
+	}
+	clock.advance(time.Minute)
+	if tf.ContainsString("a") {
+		t.Error("item outlived its generations")
+	}
+}
+
+func TestNewTimeRotatingInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewTimeRotating did not panic")
+		}
+	}()
+	NewTimeRotating(0, 2, 100, 0.01)
+}
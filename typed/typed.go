@@ -0,0 +1,78 @@
+// Package typed wraps bloomfilter.BloomFilter for keys of a Go type, so
+// callers need not convert their keys to bytes at every call.
+package typed
+
+import (
+	"hash/maphash"
+
+	bloomfilter "github.com/hriday-13th/bloom-filter"
+)
+
+// BloomFilter holds keys of type T, turning each into bytes with the encode
+// function it was built with, or into an integer key with hash for
+// NewComparable. It is safe for concurrent use.
+type BloomFilter[T any] struct {
+	filter *bloomfilter.BloomFilter
+	encode func(T) []byte
+	hash   func(T) uint64
+}
+
+// New returns a filter of size bits and numHashes hash functions. encode
+// must map equal keys to equal bytes; it is also what makes the filter
+// portable, since Filter().Serialize() can be read back anywhere the same
+// encoding is used.
+func New[T any](size uint, numHashes int, encode func(T) []byte) *BloomFilter[T] {
+	return Wrap(bloomfilter.New(size, numHashes), encode)
+}
+
+// NewComparable hashes keys with hash/maphash, so any comparable type works
+// without an encoding, and keys go in through AddUint64 without allocating.
+// The maphash seed is random and cannot be saved, so the filter is only
+// meaningful within the process that built it.
+func NewComparable[T comparable](size uint, numHashes int) *BloomFilter[T] {
+	seed := maphash.MakeSeed()
+	return &BloomFilter[T]{
+		filter: bloomfilter.New(size, numHashes),
+		hash: func(item T) uint64 {
+			return maphash.Comparable(seed, item)
+		},
+	}
+}
+
+// Wrap adapts an existing filter, for example one read back with
+// bloomfilter.Deserialize.
+func Wrap[T any](filter *bloomfilter.BloomFilter, encode func(T) []byte) *BloomFilter[T] {
+	return &BloomFilter[T]{filter: filter, encode: encode}
+}
+
+func (tf *BloomFilter[T]) Add(item T) {
+	if tf.hash != nil {
+		tf.filter.AddUint64(tf.hash(item))
+		return
+	}
+	tf.filter.Add(tf.encode(item))
+}
+
+func (tf *BloomFilter[T]) Contains(item T) bool {
+	if tf.hash != nil {
+		return tf.filter.ContainsUint64(tf.hash(item))
+	}
+	return tf.filter.Contains(tf.encode(item))
+}
+
+func (tf *BloomFilter[T]) TestAndAdd(item T) bool {
+	if tf.hash != nil {
+		return tf.filter.TestAndAddUint64(tf.hash(item))
+	}
+	return tf.filter.TestAndAdd(tf.encode(item))
+}
+
+func (tf *BloomFilter[T]) Count() uint {
+	return tf.filter.Count()
+}
+
+// Filter returns the underlying filter, for serialization and the
+// whole-filter operations.
+func (tf *BloomFilter[T]) Filter() *bloomfilter.BloomFilter {
+	return tf.filter
+}
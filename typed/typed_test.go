@@ -0,0 +1,26 @@
+package typed
+
+import "testing"
+
+type point struct{ x, y int }
+
+func TestComparableZeroAllocs(t *testing.T) {
+	ints := NewComparable[int](1<<16, 7)
+	strs := NewComparable[string](1<<16, 7)
+	points := NewComparable[point](1<<16, 7)
+	for op, f := range map[string]func(){
+		"int Add":           func() { ints.Add(42) },
+		"int Contains":      func() { ints.Contains(42) },
+		"string Add":        func() { strs.Add("an item") },
+		"string Contains":   func() { strs.Contains("an item") },
+		"struct TestAndAdd": func() { points.TestAndAdd(point{1, 2}) },
+		"struct Contains":   func() { points.Contains(point{1, 2}) },
+	} {
+		if n := testing.AllocsPerRun(100, f); n != 0 {
+			t.Errorf("%s: %v allocations, want 0", op, n)
+		}
+	}
+	if !ints.Contains(42) || !strs.Contains("an item") || !points.Contains(point{1, 2}) {
+		t.Error("added items not found")
+	}
+}
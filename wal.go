@@ -0,0 +1,320 @@
+package bloomfilter
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SyncPolicy says when a WAL syncs its file to disk.
+type SyncPolicy uint8
+
+const (
+	// SyncAlways syncs before every Add returns, so an Add that returned
+	// survives a crash.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval buffers records and writes and syncs them every
+	// WALOptions.Interval, losing at most that much on a crash.
+	SyncInterval
+	// SyncNever buffers records and writes them to the file every
+	// WALOptions.Interval and on Close, but leaves syncing to the OS.
+	SyncNever
+)
+
+type WALOptions struct {
+	Sync SyncPolicy
+	// Interval is the period of SyncInterval and SyncNever; it defaults to
+	// one second.
+	Interval time.Duration
+}
+
+// The log is walMagic, a version byte, then one record per Add: the probe
+// hashes h1 and h2 and a CRC-32C of them, all little-endian. A torn final
+// record fails its checksum and is dropped on replay.
+const (
+	walVersion    = 1
+	walHeaderSize = 5
+	walRecordSize = 20
+)
+
+var walMagic = [4]byte{0x89, 'B', 'L', 'W'}
+
+var ErrInvalidWAL = errors.New("bloomfilter: not a write-ahead log")
+
+// ErrWALFailed wraps the error that failed a WAL. Once a write or sync has
+// failed, what reached the file is unknown, and a failed fsync may already
+// have dropped the pages it could not write, so every later Add, Sync and
+// Truncate returns it. Reopening the log replays whatever records survived.
+var ErrWALFailed = errors.New("bloomfilter: write-ahead log failed")
+
+// WAL makes adds to a filter durable between snapshots by logging them to
+// a file before applying them. It logs the probe hashes rather than the
+// items, so records are small and fixed-size, and replaying them needs a
+// filter with the same hasher and seed. Adds through a WAL are serialized
+// with each other.
+type WAL struct {
+	bf   *BloomFilter
+	opts WALOptions
+
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	w    *bufio.Writer
+	size int64
+	err  error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// OpenWAL opens or creates the log at path and replays it into bf.
+func OpenWAL(bf *BloomFilter, path string, opts WALOptions) (*WAL, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	size, err := replayWAL(bf, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	w := &WAL{bf: bf, opts: opts, path: path, f: f, w: bufio.NewWriter(f), size: size}
+	if opts.Sync != SyncAlways {
+		if w.opts.Interval <= 0 {
+			w.opts.Interval = time.Second
+		}
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+		go w.run()
+	}
+	return w, nil
+}
+
+// replayWAL adds every intact record of f to bf, writing the header if f is
+// empty, and returns the length of the intact prefix, to which it truncates
+// f.
+func replayWAL(bf *BloomFilter, f *os.File) (int64, error) {
+	r := bufio.NewReader(f)
+	var hdr [walHeaderSize]byte
+	switch _, err := io.ReadFull(r, hdr[:]); err {
+	case nil:
+		if [4]byte(hdr[:4]) != walMagic || hdr[4] != walVersion {
+			return 0, ErrInvalidWAL
+		}
+	case io.EOF:
+		if _, err := f.WriteAt(append(walMagic[:], walVersion), 0); err != nil {
+			return 0, err
+		}
+		return walHeaderSize, nil
+	case io.ErrUnexpectedEOF:
+		return 0, ErrInvalidWAL
+	default:
+		return 0, err
+	}
+
+	size := int64(walHeaderSize)
+	var rec [walRecordSize]byte
+	for {
+		if _, err := io.ReadFull(r, rec[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return 0, err
+		}
+		if crc32.Checksum(rec[:16], castagnoli) != binary.LittleEndian.Uint32(rec[16:]) {
+			break
+		}
+		bf.add(binary.LittleEndian.Uint64(rec[0:8]), binary.LittleEndian.Uint64(rec[8:16]))
+		size += walRecordSize
+	}
+	return size, f.Truncate(size)
+}
+
+func (w *WAL) run() {
+	defer close(w.done)
+	t := time.NewTicker(w.opts.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C:
+			if w.opts.Sync == SyncNever {
+				w.flush()
+			} else {
+				w.Sync()
+			}
+		}
+	}
+}
+
+// Add logs item and adds it to the filter. If logging fails the filter is
+// left unchanged and the WAL fails.
+func (w *WAL) Add(item []byte) error {
+	return w.add(w.bf.hash(item))
+}
+
+func (w *WAL) AddString(item string) error {
+	return w.Add(stringBytes(item))
+}
+
+func (w *WAL) AddUint64(item uint64) error {
+	return w.add(w.bf.seeded(hashUint64(item)))
+}
+
+func (w *WAL) add(h1, h2 uint64) error {
+	var rec [walRecordSize]byte
+	binary.LittleEndian.PutUint64(rec[0:8], h1)
+	binary.LittleEndian.PutUint64(rec[8:16], h2)
+	binary.LittleEndian.PutUint32(rec[16:], crc32.Checksum(rec[:16], castagnoli))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if _, err := w.w.Write(rec[:]); err != nil {
+		return w.fail(err)
+	}
+	if w.opts.Sync == SyncAlways {
+		if err := w.sync(); err != nil {
+			return err
+		}
+	}
+	w.size += walRecordSize
+	// Apply while holding mu, so that mark sees every logged record in the
+	// filter.
+	w.bf.add(h1, h2)
+	return nil
+}
+
+// Err returns the error that failed the WAL, if any, including one from a
+// background sync.
+func (w *WAL) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Sync writes any buffered records and syncs the file.
+func (w *WAL) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sync()
+}
+
+// flush writes any buffered records to the file without syncing it.
+func (w *WAL) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		return w.fail(err)
+	}
+	return nil
+}
+
+func (w *WAL) sync() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		return w.fail(err)
+	}
+	if err := w.f.Sync(); err != nil {
+		return w.fail(err)
+	}
+	return nil
+}
+
+// fail records err as the reason the WAL failed and returns it wrapped.
+func (w *WAL) fail(err error) error {
+	w.err = fmt.Errorf("%w: %w", ErrWALFailed, err)
+	return w.err
+}
+
+// Truncate empties the log, once the filter has been saved some other way.
+func (w *WAL) Truncate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.discard(w.size)
+}
+
+// mark returns the length of the log; every record before it is already
+// in the filter.
+func (w *WAL) mark() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size
+}
+
+// discardTo drops the records before offset, a value from mark, keeping
+// any logged since.
+func (w *WAL) discardTo(offset int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.discard(offset)
+}
+
+// discard rewrites the log with only the records after offset, to a
+// temporary file that is synced and renamed over it, so a crash leaves
+// either the old log or the new one whole.
+func (w *WAL) discard(offset int64) error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		return w.fail(err)
+	}
+	if err := writeFileAtomic(w.path, func(dst io.Writer) error {
+		if _, err := dst.Write(append(walMagic[:], walVersion)); err != nil {
+			return err
+		}
+		_, err := io.Copy(dst, io.NewSectionReader(w.f, offset, w.size-offset))
+		return err
+	}); err != nil {
+		return w.fail(err)
+	}
+
+	f, err := os.OpenFile(w.path, os.O_RDWR, 0)
+	if err != nil {
+		return w.fail(err)
+	}
+	w.size = walHeaderSize + w.size - offset
+	if _, err := f.Seek(w.size, io.SeekStart); err != nil {
+		f.Close()
+		return w.fail(err)
+	}
+	w.f.Close()
+	w.f = f
+	w.w.Reset(f)
+	return nil
+}
+
+// Close syncs and closes the log.
+func (w *WAL) Close() error {
+	if w.stop != nil {
+		close(w.stop)
+		<-w.done
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.sync()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
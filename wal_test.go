@@ -0,0 +1,180 @@
+package bloomfilter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWALReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	for _, policy := range []SyncPolicy{SyncAlways, SyncInterval, SyncNever} {
+		os.Remove(path)
+		w, err := OpenWAL(New(1000, 3), path, WALOptions{Sync: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			if err := w.AddString(strconv.Itoa(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		bf := New(1000, 3)
+		w, err = OpenWAL(bf, path, WALOptions{Sync: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			if !bf.ContainsString(strconv.Itoa(i)) {
+				t.Fatalf("policy %d: replayed filter lacks %d", policy, i)
+			}
+		}
+		w.Close()
+	}
+}
+
+// A torn final record is dropped on replay and cut from the file.
+func TestWALTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := OpenWAL(New(1000, 3), path, WALOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddString("a")
+	w.AddString("b")
+	w.Close()
+	if err := os.Truncate(path, walHeaderSize+walRecordSize+7); err != nil {
+		t.Fatal(err)
+	}
+
+	bf := New(1000, 3)
+	w, err = OpenWAL(bf, path, WALOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if !bf.ContainsString("a") || bf.ContainsString("b") {
+		t.Error("want only the intact record replayed")
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Size() != walHeaderSize+walRecordSize {
+		t.Errorf("log not truncated to its intact records: %v, %v", fi.Size(), err)
+	}
+}
+
+func TestWALInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	for _, data := range [][]byte{{0x89, 'B'}, []byte("not a log")} {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenWAL(New(1000, 3), path, WALOptions{}); !errors.Is(err, ErrInvalidWAL) {
+			t.Errorf("%q: got %v, want ErrInvalidWAL", data, err)
+		}
+	}
+}
+
+// After a failed sync the record is neither applied nor counted, and the WAL
+// refuses further writes rather than buffering them behind the failure.
+func TestWALSyncFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	bf := New(1000, 3)
+	w, err := OpenWAL(bf, path, WALOptions{Sync: SyncAlways})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddString("a"); err != nil {
+		t.Fatal(err)
+	}
+	size := w.mark()
+	w.f.Close()
+
+	if err := w.AddString("b"); !errors.Is(err, ErrWALFailed) {
+		t.Fatalf("Add: got %v, want ErrWALFailed", err)
+	}
+	if bf.ContainsString("b") {
+		t.Error("filter has an item whose record failed to sync")
+	}
+	if w.mark() != size {
+		t.Errorf("size grew from %d to %d", size, w.mark())
+	}
+	for name, err := range map[string]error{
+		"Add":      w.AddString("c"),
+		"Sync":     w.Sync(),
+		"Truncate": w.Truncate(),
+		"Err":      w.Err(),
+		"Close":    w.Close(),
+	} {
+		if !errors.Is(err, ErrWALFailed) {
+			t.Errorf("%s: got %v, want ErrWALFailed", name, err)
+		}
+	}
+}
+
+// Dropping the records a save covers rewrites the log beside it, keeps the
+// records logged since, and leaves later adds going to the new file.
+func TestWALDiscardTo(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log")
+	w, err := OpenWAL(New(1000, 3), path, WALOptions{Sync: SyncAlways})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddString("a")
+	w.AddString("b")
+	mark := w.mark()
+	w.AddString("c")
+	if err := w.discardTo(mark); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddString("d"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want only the log: %v", len(entries), err)
+	}
+	bf := New(1000, 3)
+	w, err = OpenWAL(bf, path, WALOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if bf.ContainsString("a") || bf.ContainsString("b") {
+		t.Error("discarded records were replayed")
+	}
+	if !bf.ContainsString("c") || !bf.ContainsString("d") {
+		t.Error("records after the mark were lost")
+	}
+	if w.mark() != walHeaderSize+2*walRecordSize {
+		t.Errorf("log is %d bytes, want two records", w.mark())
+	}
+}
+
+// SyncNever still writes its buffer out on the timer, for the OS to sync.
+func TestWALSyncNeverFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	w, err := OpenWAL(New(1000, 3), path, WALOptions{Sync: SyncNever, Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.AddString("a")
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if fi, err := os.Stat(path); err == nil && fi.Size() == walHeaderSize+walRecordSize {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("record never reached the file")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package bloomfilter
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// watermark is a hook that fires once the count reaches at, the number of
+// items at which the filter is expected to cross the hook's threshold.
+type watermark struct {
+	fillRatio bool
+	threshold float64
+	fn        func(*BloomFilter)
+	at        uint64
+	fired     atomic.Bool
+}
+
+// WithFillHook calls fn once the filter is expected to be ratio full, for
+// instance to rotate it or raise an alert. WithFPHook does the same for the
+// estimated false-positive rate. Both go by Count rather than scanning the
+// bits, so they cost a comparison per Add, and adding an item twice counts
+// twice. fn runs in the goroutine whose Add crossed the threshold, once per
+// hook until Reset re-arms it; it should be quick, or start a goroutine.
+func WithFillHook(ratio float64, fn func(*BloomFilter)) Option {
+	if ratio <= 0 || ratio >= 1 {
+		panic("bloomfilter: fill ratio must be in (0, 1)")
+	}
+	return func(bf *BloomFilter) {
+		bf.watermarks = append(bf.watermarks, &watermark{fillRatio: true, threshold: ratio, fn: fn})
+	}
+}
+
+// WithFPHook calls fn once EstimatedFalsePositiveRate reaches rate; see
+// WithFillHook.
+func WithFPHook(rate float64, fn func(*BloomFilter)) Option {
+	if rate <= 0 || rate >= 1 {
+		panic("bloomfilter: false-positive rate must be in (0, 1)")
+	}
+	return func(bf *BloomFilter) {
+		bf.watermarks = append(bf.watermarks, &watermark{threshold: rate, fn: fn})
+	}
+}
+
+// armWatermarks works out the count for each hook from the filter's shape
+// and re-arms them all. Hooks already crossed fire on the next Add, not here,
+// since callers may hold mu.
+func (bf *BloomFilter) armWatermarks() {
+	next := uint64(math.MaxUint64)
+	m, k := float64(bf.size), float64(bf.numHashes)
+	for _, w := range bf.watermarks {
+		// The expected fill ratio after n items is 1 - e^(-kn/m), and the
+		// false-positive rate is its kth power.
+		fill := w.threshold
+		if !w.fillRatio {
+			fill = math.Pow(w.threshold, 1/k)
+		}
+		w.at = uint64(math.Ceil(-m / k * math.Log1p(-fill)))
+		w.fired.Store(false)
+		next = min(next, w.at)
+	}
+	bf.nextWatermark.Store(next)
+}
+
+// added counts n new items and fires any hooks they cross.
+func (bf *BloomFilter) added(n uint64) {
+	if total := bf.count.Add(n); total >= bf.nextWatermark.Load() {
+		bf.checkWatermarks(total)
+	}
+}
+
+func (bf *BloomFilter) checkWatermarks(total uint64) {
+	next := uint64(math.MaxUint64)
+	var crossed []*watermark
+	for _, w := range bf.watermarks {
+		if w.fired.Load() {
+			continue
+		}
+		if total < w.at {
+			next = min(next, w.at)
+		} else if w.fired.CompareAndSwap(false, true) {
+			crossed = append(crossed, w)
+		}
+	}
+	// A racing call may store a stale, lower value; that only means an
+	// extra check. Store before calling out, so a hook that resets the
+	// filter keeps the thresholds it re-armed.
+	bf.nextWatermark.Store(next)
+	for _, w := range crossed {
+		w.fn(bf)
+	}
+}
@@ -0,0 +1,154 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/hriday-13th/bloom-filter/internal/hashing"
+)
+
+const maxXorAttempts = 100
+
+var ErrXorConstruction = errors.New("bloomfilter: could not construct xor filter")
+
+// XorFilter is an immutable filter over a set of keys known up front. It
+// stores one 8-bit fingerprint per slot, about 9.84 bits per key, for a
+// false-positive rate of roughly 0.39%.
+type XorFilter struct {
+	seed         uint64
+	blockLength  uint32
+	fingerprints []uint8
+}
+
+// NewXor builds an xor filter containing keys. Duplicate keys are allowed.
+func NewXor(keys [][]byte) (*XorFilter, error) {
+	hashes := make([]uint64, len(keys))
+	for i, key := range keys {
+		hashes[i], _ = hashing.Sum128(key)
+	}
+	slices.Sort(hashes)
+	hashes = slices.Compact(hashes)
+
+	capacity := 32 + uint32(1.23*float64(len(hashes)))
+	capacity = capacity / 3 * 3
+	xf := &XorFilter{
+		blockLength:  capacity / 3,
+		fingerprints: make([]uint8, capacity),
+	}
+
+	xorMask := make([]uint64, capacity)
+	counts := make([]uint32, capacity)
+	queue := make([]uint32, 0, capacity)
+	stack := make([]uint64, 0, 2*len(hashes))
+
+	for attempt := 0; attempt < maxXorAttempts; attempt++ {
+		xf.seed = rand.Uint64()
+		clear(xorMask)
+		clear(counts)
+		queue, stack = queue[:0], stack[:0]
+
+		for _, h := range hashes {
+			h = xf.mix(h)
+			for _, index := range xf.positions(h) {
+				xorMask[index] ^= h
+				counts[index]++
+			}
+		}
+
+		for index, count := range counts {
+			if count == 1 {
+				queue = append(queue, uint32(index))
+			}
+		}
+
+		// Peel slots that are hit by exactly one remaining key; each peeled
+		// key is assigned to that slot.
+		for len(queue) > 0 {
+			index := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+			if counts[index] != 1 {
+				continue
+			}
+			h := xorMask[index]
+			stack = append(stack, uint64(index), h)
+			for _, pos := range xf.positions(h) {
+				xorMask[pos] ^= h
+				counts[pos]--
+				if counts[pos] == 1 {
+					queue = append(queue, pos)
+				}
+			}
+		}
+
+		if len(stack) == 2*len(hashes) {
+			break
+		}
+		if attempt == maxXorAttempts-1 {
+			return nil, ErrXorConstruction
+		}
+	}
+
+	// Assign in reverse peeling order. The slot being assigned is still zero,
+	// so including it in the xor is harmless.
+	for i := len(stack) - 2; i >= 0; i -= 2 {
+		index, h := uint32(stack[i]), stack[i+1]
+		p := xf.positions(h)
+		xf.fingerprints[index] = xorFingerprint(h) ^ xf.fingerprints[p[0]] ^ xf.fingerprints[p[1]] ^ xf.fingerprints[p[2]]
+	}
+	return xf, nil
+}
+
+func (xf *XorFilter) Contains(item []byte) bool {
+	h, _ := hashing.Sum128(item)
+	h = xf.mix(h)
+	p := xf.positions(h)
+	return xorFingerprint(h) == xf.fingerprints[p[0]]^xf.fingerprints[p[1]]^xf.fingerprints[p[2]]
+}
+
+func (xf *XorFilter) mix(h uint64) uint64 {
+	return hashing.Mix64(h + xf.seed)
+}
+
+// positions returns one slot in each of the three blocks.
+func (xf *XorFilter) positions(h uint64) [3]uint32 {
+	return [3]uint32{
+		reduce(uint32(h), xf.blockLength),
+		reduce(uint32(bits.RotateLeft64(h, 21)), xf.blockLength) + xf.blockLength,
+		reduce(uint32(bits.RotateLeft64(h, 42)), xf.blockLength) + 2*xf.blockLength,
+	}
+}
+
+func reduce(h, n uint32) uint32 {
+	return uint32(uint64(h) * uint64(n) >> 32)
+}
+
+func xorFingerprint(h uint64) uint8 {
+	return uint8(h ^ h>>32)
+}
+
+func (xf *XorFilter) Serialize() []byte {
+	serialized := make([]byte, 16+len(xf.fingerprints))
+	binary.LittleEndian.PutUint64(serialized[0:8], xf.seed)
+	binary.LittleEndian.PutUint64(serialized[8:16], uint64(xf.blockLength))
+	copy(serialized[16:], xf.fingerprints)
+	return serialized
+}
+
+func DeserializeXor(data []byte) (*XorFilter, error) {
+	if len(data) < 16 {
+		return nil, ErrInvalidFormat
+	}
+	blockLength := binary.LittleEndian.Uint64(data[8:16])
+	if blockLength == 0 || blockLength > math.MaxUint32 || uint64(len(data)-16) != 3*blockLength {
+		return nil, ErrInvalidFormat
+	}
+	return &XorFilter{
+		seed:         binary.LittleEndian.Uint64(data[0:8]),
+		blockLength:  uint32(blockLength),
+		fingerprints: slices.Clone(data[16:]),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestXorRoundTrip(t *testing.T) {
+	keys := make([][]byte, 1000)
+	for i := range keys {
+		keys[i] = []byte(strconv.Itoa(i))
+	}
+	xf, err := NewXor(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DeserializeXor(xf.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if !decoded.Contains(key) {
+			t.Fatalf("decoded filter lacks %s", key)
+		}
+	}
+}
+
+func TestDeserializeXorInvalid(t *testing.T) {
+	xf, err := NewXor([][]byte{[]byte("a"), []byte("b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := xf.Serialize()
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"short", valid[:15]},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing bytes", append(valid[:len(valid):len(valid)], 0)},
+		{"no blocks", xorHeader(0, 0)},
+		{"block length past end", xorHeader(1<<20, 30)},
+		{"block length over uint32", xorHeader(math.MaxUint32+1, 0)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DeserializeXor(tc.data); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("got %v, want ErrInvalidFormat", err)
+			}
+		})
+	}
+}
+
+func xorHeader(blockLength uint64, n int) []byte {
+	data := make([]byte, 16+n)
+	binary.LittleEndian.PutUint64(data[8:], blockLength)
+	return data
+}